@@ -1,15 +1,26 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"blockchain-client/pkg/hexutil"
 	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+	"blockchain-client/pkg/middleware"
 	"blockchain-client/rpc"
 	"blockchain-client/server"
 
 	"go.uber.org/zap"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 func main() {
@@ -23,15 +34,62 @@ func main() {
 		logLevel = "debug" // More verbose logging in development
 	}
 
+	// Output formats are validated once the logger can report the problem
+	rotationConfig.DisableFile = getEnv("LOG_FILE_ENABLED", "true") == "false"
+	rotationConfig.ConsoleColor = getEnv("LOG_COLOR", "false") == "true"
+
+	// Repetitive log lines are sampled in production so bursts of identical
+	// errors do not flood the logs
+	if getEnv("LOG_SAMPLING", strconv.FormatBool(isProduction)) == "true" {
+		sampling := logger.DefaultSamplingConfig()
+		sampling.Initial = getEnvInt("LOG_SAMPLING_INITIAL", sampling.Initial)
+		sampling.Thereafter = getEnvInt("LOG_SAMPLING_THEREAFTER", sampling.Thereafter)
+		rotationConfig.Sampling = sampling
+	}
+	consoleEncoding, consoleErr := logger.ParseEncoding(getEnv("LOG_FORMAT", string(rotationConfig.ConsoleEncoding)))
+	if consoleErr == nil {
+		rotationConfig.ConsoleEncoding = consoleEncoding
+	}
+	fileEncoding, fileErr := logger.ParseEncoding(getEnv("LOG_FILE_FORMAT", string(rotationConfig.FileEncoding)))
+	if fileErr == nil {
+		rotationConfig.FileEncoding = fileEncoding
+	}
+
 	logger.InitWithRotation(logLevel, rotationConfig)
 	defer logger.Sync()
 
+	if consoleErr != nil {
+		logger.Fatal("Invalid LOG_FORMAT", zap.Error(consoleErr))
+	}
+	if fileErr != nil {
+		logger.Fatal("Invalid LOG_FILE_FORMAT", zap.Error(fileErr))
+	}
+
 	logger.Info("Starting blockchain client application")
+	if bucketsStr := getEnv("METRICS_DURATION_BUCKETS", ""); bucketsStr != "" {
+		buckets, err := parseBuckets(bucketsStr)
+		if err == nil {
+			err = metrics.SetDurationBuckets(buckets)
+		}
+		if err != nil {
+			logger.Fatal("Invalid METRICS_DURATION_BUCKETS", zap.String("buckets", bucketsStr), zap.Error(err))
+		}
+	}
+	for _, err := range metrics.RegistrationErrors() {
+		logger.Warn("Metric not registered and will not be exported", zap.Error(err))
+	}
 
 	// Get configuration from environment variables
 	rpcURL := getEnv("RPC_URL", "https://polygon-rpc.com/")
+	fallbackURLsStr := getEnv("RPC_FALLBACK_URLS", "")
+	wsURL := getEnv("WS_RPC_URL", "")
 	timeoutStr := getEnv("TIMEOUT_SECONDS", "10")
 	port := getEnv("PORT", "8080")
+	chainURLsStr := getEnv("CHAIN_RPC_URLS", "")
+	finalityMarginStr := getEnv("FINALITY_MARGIN", strconv.FormatUint(rpc.DefaultFinalityMargin, 10))
+	slowRequestMsStr := getEnv("SLOW_REQUEST_THRESHOLD_MS", "2000")
+	retryConfig := rpc.DefaultRetryConfig()
+	maxRetriesStr := getEnv("RPC_MAX_RETRIES", strconv.Itoa(retryConfig.MaxRetries))
 
 	// Parse timeout
 	timeout, err := strconv.Atoi(timeoutStr)
@@ -39,13 +97,305 @@ func main() {
 		logger.Fatal("Invalid timeout value", zap.String("timeout", timeoutStr), zap.Error(err))
 	}
 
-	// Create enhanced RPC client
-	logger.Info("Initializing blockchain RPC client", zap.String("url", rpcURL))
-	client := rpc.NewEnhancedClient(rpcURL, time.Duration(timeout)*time.Second)
+	// Parse finality margin
+	finalityMargin, err := strconv.ParseUint(finalityMarginStr, 10, 64)
+	if err != nil {
+		logger.Fatal("Invalid finality margin value", zap.String("finality_margin", finalityMarginStr), zap.Error(err))
+	}
+
+	// Parse retry count
+	retryConfig.MaxRetries, err = strconv.Atoi(maxRetriesStr)
+	if err != nil || retryConfig.MaxRetries < 0 {
+		logger.Fatal("Invalid max retries value", zap.String("max_retries", maxRetriesStr), zap.Error(err))
+	}
+
+	// Randomize retry backoffs by a percentage so replicas do not retry in lockstep
+	retryConfig.Jitter = float64(getEnvInt("RPC_RETRY_JITTER_PERCENT", int(retryConfig.Jitter*100))) / 100
+	if retryConfig.Jitter > 1 {
+		logger.Fatal("Invalid retry jitter percentage", zap.Float64("retry_jitter", retryConfig.Jitter))
+	}
+	logger.Info("RPC retry backoff configured",
+		zap.Int("max_retries", retryConfig.MaxRetries),
+		zap.Bool("jitter_enabled", retryConfig.JitterEnabled()),
+		zap.Float64("jitter", retryConfig.Jitter))
+
+	// Parse slow request threshold
+	slowRequestMs, err := strconv.Atoi(slowRequestMsStr)
+	if err != nil || slowRequestMs < 0 {
+		logger.Fatal("Invalid slow request threshold", zap.String("slow_request_threshold_ms", slowRequestMsStr), zap.Error(err))
+	}
+
+	// Parse additional chains
+	chainURLs, err := parseChainURLs(chainURLsStr)
+	if err != nil {
+		logger.Fatal("Invalid chain RPC URLs", zap.String("chain_rpc_urls", chainURLsStr), zap.Error(err))
+	}
+
+	// Fail fast while the node is down instead of waiting out every timeout
+	breakerConfig := rpc.DefaultBreakerConfig()
+	breakerConfig.FailureThreshold = getEnvInt("RPC_BREAKER_FAILURE_THRESHOLD", breakerConfig.FailureThreshold)
+	breakerConfig.Cooldown = time.Duration(getEnvInt("RPC_BREAKER_COOLDOWN_SECONDS", int(breakerConfig.Cooldown/time.Second))) * time.Second
+
+	// Keep enough idle connections to the node for bursts of concurrent calls
+	transportConfig := rpc.DefaultTransportConfig()
+	transportConfig.MaxIdleConns = getEnvInt("RPC_MAX_IDLE_CONNS", transportConfig.MaxIdleConns)
+	transportConfig.MaxIdleConnsPerHost = getEnvInt("RPC_MAX_IDLE_CONNS_PER_HOST", transportConfig.MaxIdleConnsPerHost)
+	transportConfig.IdleConnTimeout = time.Duration(getEnvInt("RPC_IDLE_CONN_TIMEOUT_SECONDS", int(transportConfig.IdleConnTimeout/time.Second))) * time.Second
+	transportConfig.HTTP2 = getEnv("RPC_HTTP2", "true") == "true"
+
+	clientOptions := []rpc.Option{
+		rpc.WithTimeout(time.Duration(timeout) * time.Second),
+		rpc.WithTransportConfig(transportConfig),
+		rpc.WithFinalityMargin(finalityMargin),
+		rpc.WithMaxHeadJump(uint64(getEnvInt("HEAD_MAX_JUMP", int(rpc.DefaultMaxHeadJump)))),
+		rpc.WithRetry(retryConfig),
+		rpc.WithCircuitBreaker(breakerConfig),
+		rpc.WithCache(getEnvInt("BLOCK_CACHE_SIZE", 0)),
+		rpc.WithMaxResponseSize(int64(getEnvInt("RPC_MAX_RESPONSE_BYTES", int(rpc.DefaultMaxResponseSize)))),
+		rpc.WithMaxLogBlockRange(uint64(getEnvInt("LOG_MAX_BLOCK_RANGE", int(rpc.DefaultMaxLogBlockRange)))),
+	}
+
+	// Keep the blocks near the head cached for explorer-style workloads
+	if getEnv("BLOCK_CACHE_WARMING", "false") == "true" {
+		warmDepth := getEnvInt("BLOCK_CACHE_WARM_DEPTH", 0)
+		if warmDepth > rpc.MaxCacheWarmDepth {
+			logger.Fatal("Invalid cache warming depth",
+				zap.Int("block_cache_warm_depth", warmDepth),
+				zap.Int("max", rpc.MaxCacheWarmDepth))
+		}
+		clientOptions = append(clientOptions, rpc.WithCacheWarming(warmDepth))
+	}
+
+	// Options for RPC_URL's provider only. Fallback and chain URLs may belong
+	// to other providers, so they never receive these credentials and carry
+	// their own in their URLs.
+	primaryOptions := []rpc.Option{rpc.WithWebSocketURL(wsURL)}
+
+	// Providers that serve JSON-RPC below the base URL or authenticate with a
+	// key in the query string or the path
+	if rpcPath := getEnv("RPC_PATH", ""); rpcPath != "" {
+		primaryOptions = append(primaryOptions, rpc.WithRPCPath(rpcPath))
+	}
+	if apiKey := getEnv("RPC_API_KEY", ""); apiKey != "" {
+		switch location := getEnv("RPC_API_KEY_LOCATION", "query"); location {
+		case "query":
+			primaryOptions = append(primaryOptions, rpc.WithAPIKeyParam(getEnv("RPC_API_KEY_PARAM", "apikey"), apiKey))
+		case "path":
+			primaryOptions = append(primaryOptions, rpc.WithAPIKeyPath(apiKey))
+		default:
+			logger.Fatal("Invalid RPC API key location, expected query or path", zap.String("location", location))
+		}
+	}
+
+	// Static headers for RPC_URL's provider, which may key auth or routing off
+	// custom headers
+	rpcHeaders, err := parseHeaders(getEnv("RPC_HEADERS", ""))
+	if err != nil {
+		logger.Fatal("Invalid RPC_HEADERS", zap.Error(err))
+	}
+	if len(rpcHeaders) > 0 {
+		primaryOptions = append(primaryOptions, rpc.WithHeaders(rpcHeaders))
+	}
+
+	// Providers that authenticate with a bearer token or basic auth. The
+	// credentials are only ever sent to RPC_URL's provider, never logged.
+	bearerToken := getEnv("RPC_BEARER_TOKEN", "")
+	basicAuthUsername := getEnv("RPC_BASIC_AUTH_USERNAME", "")
+	switch {
+	case bearerToken != "" && basicAuthUsername != "":
+		logger.Fatal("Set either RPC_BEARER_TOKEN or RPC_BASIC_AUTH_USERNAME, not both")
+	case bearerToken != "":
+		primaryOptions = append(primaryOptions, rpc.WithBearerToken(bearerToken))
+	case basicAuthUsername != "":
+		primaryOptions = append(primaryOptions, rpc.WithBasicAuth(basicAuthUsername, getEnv("RPC_BASIC_AUTH_PASSWORD", "")))
+	}
+
+	// Chaos testing aid for staging; never enable in production
+	if getEnv("FAULT_INJECT_ENABLED", "false") == "true" {
+		faults := rpc.FaultConfig{
+			Latency:   time.Duration(getEnvInt("FAULT_INJECT_LATENCY_MS", 0)) * time.Millisecond,
+			ErrorRate: getEnvRate("FAULT_INJECT_ERROR_RATE", 0),
+			DropRate:  getEnvRate("FAULT_INJECT_DROP_RATE", 0),
+		}
+		clientOptions = append(clientOptions, rpc.WithFaultInjection(faults))
+		logger.Warn("RPC fault injection enabled; do not use in production",
+			zap.Duration("latency", faults.Latency),
+			zap.Float64("error_rate", faults.ErrorRate),
+			zap.Float64("drop_rate", faults.DropRate))
+	}
+
+	// Diagnostic dump of outbound HTTP exchanges for debugging providers
+	if getEnv("RPC_DUMP_ENABLED", "false") == "true" {
+		samplePercent := getEnvInt("RPC_DUMP_SAMPLE_PERCENT", 1)
+		if samplePercent > 100 {
+			logger.Fatal("Invalid RPC dump sample percentage", zap.Int("percent", samplePercent))
+		}
+		dumpFile := getEnv("RPC_DUMP_FILE", "rpc-dump.log")
+		clientOptions = append(clientOptions, rpc.WithRequestDump(rpc.DumpConfig{
+			Writer: &lumberjack.Logger{
+				Filename:   dumpFile,
+				MaxSize:    getEnvInt("RPC_DUMP_MAX_SIZE_MB", 100),
+				MaxBackups: 3,
+			},
+			SampleRate: float64(samplePercent) / 100,
+		}))
+		logger.Warn("RPC request dumping enabled",
+			zap.String("file", dumpFile),
+			zap.Int("sample_percent", samplePercent))
+	}
+
+	// Match batch responses to requests by ID unless the provider drops IDs
+	switch correlation := getEnv("RPC_BATCH_CORRELATION", "id"); correlation {
+	case "id":
+	case "position":
+		clientOptions = append(clientOptions, rpc.WithBatchCorrelation(rpc.CorrelateByPosition))
+	default:
+		logger.Fatal("Invalid batch correlation strategy", zap.String("batch_correlation", correlation))
+	}
+
+	// Keep within the provider's request quota
+	if rateLimit := getEnvInt("RPC_RATE_LIMIT_RPS", 0); rateLimit > 0 {
+		clientOptions = append(clientOptions, rpc.WithRateLimit(float64(rateLimit), getEnvInt("RPC_RATE_LIMIT_BURST", rateLimit)))
+	}
+	clientOptions = append(clientOptions, rpc.WithMaxConcurrency(getEnvInt("RPC_MAX_CONCURRENCY", 0)))
+
+	// Forward each API request's ID to the node so provider logs can be correlated
+	clientOptions = append(clientOptions, rpc.WithRequestIDHeader(getEnv("RPC_REQUEST_ID_HEADER", rpc.DefaultRequestIDHeader)))
+
+	// Return blocks with undecodable transactions rather than failing them
+	if getEnv("TOLERANT_TRANSACTION_DECODING", "false") == "true" {
+		clientOptions = append(clientOptions, rpc.WithTolerantDecoding(true))
+	}
+
+	// Optionally coalesce concurrent block lookups into batch requests
+	if getEnv("RPC_AUTO_BATCH", "false") == "true" {
+		autoBatchConfig := rpc.DefaultAutoBatchConfig()
+		autoBatchConfig.MaxWait = time.Duration(getEnvInt("RPC_AUTO_BATCH_WAIT_MS", int(autoBatchConfig.MaxWait/time.Millisecond))) * time.Millisecond
+		autoBatchConfig.MaxBatchSize = getEnvInt("RPC_AUTO_BATCH_SIZE", autoBatchConfig.MaxBatchSize)
+		clientOptions = append(clientOptions, rpc.WithAutoBatch(autoBatchConfig))
+		logger.Info("Auto-batching of block requests enabled",
+			zap.Duration("max_wait", autoBatchConfig.MaxWait),
+			zap.Int("max_batch_size", autoBatchConfig.MaxBatchSize))
+	}
+
+	// Create enhanced RPC client, failing over to any fallback endpoints
+	var fallbackURLs []string
+	for _, url := range strings.Split(fallbackURLsStr, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			fallbackURLs = append(fallbackURLs, url)
+		}
+	}
+	primaryOptions = append(primaryOptions, rpc.WithEndpoints(fallbackURLs...))
+	client := rpc.NewClient(rpcURL, append(append([]rpc.Option{}, clientOptions...), primaryOptions...)...)
+	logger.Info("Initialized blockchain RPC client", zap.Strings("urls", endpointURLs(client)))
+	go client.RunCacheWarmer(context.Background())
+
+	// Confirm which chain the RPC serves before accepting traffic
+	verifyChainID(client, getEnv("EXPECTED_CHAIN_ID", ""), time.Duration(timeout)*time.Second)
+
+	// Follow new heads over WebSocket to keep the chain head and height metric current
+	if wsURL != "" {
+		heads, err := client.SubscribeNewHeads(context.Background())
+		if err != nil {
+			logger.Warn("Failed to subscribe to new heads", zap.Error(err))
+		} else {
+			go func() {
+				for head := range heads {
+					if height, err := hexutil.DecodeUint64(head.Number); err == nil {
+						metrics.UpdateBlockchainHeight(float64(height))
+					}
+				}
+			}()
+		}
+	}
+
+	// Create a client per additional chain
+	var chains *server.ClientRegistry
+	if len(chainURLs) > 0 {
+		chains = server.NewClientRegistry()
+		for chain, url := range chainURLs {
+			chainClient := rpc.NewClient(url, append(append([]rpc.Option{}, clientOptions...), rpc.WithName(chain))...)
+			logger.Info("Initialized chain RPC client", zap.String("chain", chain), zap.Strings("urls", endpointURLs(chainClient)))
+			go chainClient.RunCacheWarmer(context.Background())
+			chains.Register(chain, chainClient)
+		}
+	}
 
 	// Create and start server with rate limiting and metrics
 	logger.Info("Initializing enhanced HTTP server", zap.String("port", port))
-	srv := server.NewEnhanced(client, port)
+	serverConfig := server.DefaultConfig()
+	serverConfig.Port = port
+	serverConfig.SlowRequestThreshold = time.Duration(slowRequestMs) * time.Millisecond
+	for _, route := range strings.Split(getEnv("LOG_VERBOSE_ROUTES", ""), ",") {
+		if route = strings.TrimSpace(route); route != "" {
+			serverConfig.VerboseRoutes = append(serverConfig.VerboseRoutes, route)
+		}
+	}
+	serverConfig.RequestTimeout = time.Duration(getEnvInt("REQUEST_TIMEOUT_MS", int(serverConfig.RequestTimeout/time.Millisecond))) * time.Millisecond
+	serverConfig.Chains = chains
+	if value := getEnv("TRUSTED_PROXIES", ""); value != "" {
+		serverConfig.TrustedProxies, err = parseTrustedProxies(value)
+		if err != nil {
+			logger.Fatal("Invalid TRUSTED_PROXIES", zap.Error(err))
+		}
+	}
+
+	// Share rate limit counters across replicas when Redis is configured
+	redisURL := getEnv("REDIS_URL", "")
+	serverConfig.RateLimiterStore, err = middleware.NewRateLimiterStore(redisURL)
+	if err != nil {
+		logger.Fatal("Failed to create rate limiter store", zap.Error(err))
+	}
+	if redisURL != "" {
+		logger.Info("Using Redis for rate limiting")
+	}
+	if getEnv("RATE_LIMIT_BY_API_KEY", "false") == "true" {
+		var apiKeys []string
+		for _, key := range strings.Split(getEnv("RATE_LIMIT_API_KEYS", ""), ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				apiKeys = append(apiKeys, key)
+			}
+		}
+		if len(apiKeys) == 0 {
+			logger.Fatal("RATE_LIMIT_BY_API_KEY requires RATE_LIMIT_API_KEYS")
+		}
+		serverConfig.RateLimitKeyFunc = middleware.APIKeyOrIP(apiKeys)
+	}
+	serverConfig.StrictValueDecoding = getEnv("STRICT_VALUE_DECODING", "false") == "true"
+	serverConfig.MaxBlockRange = getEnvInt("MAX_BLOCK_RANGE", serverConfig.MaxBlockRange)
+	if serverConfig.MaxBlockRange < 1 {
+		logger.Fatal("Invalid max block range", zap.Int("max_block_range", serverConfig.MaxBlockRange))
+	}
+	serverConfig.MaxTransactionPage = getEnvInt("MAX_BLOCK_TX_LIMIT", serverConfig.MaxTransactionPage)
+	if serverConfig.MaxTransactionPage < 1 {
+		logger.Fatal("Invalid max block transaction limit", zap.Int("max_block_tx_limit", serverConfig.MaxTransactionPage))
+	}
+	serverConfig.MaxBalanceAddresses = getEnvInt("MAX_BALANCE_ADDRESSES", serverConfig.MaxBalanceAddresses)
+	if serverConfig.MaxBalanceAddresses < 1 {
+		logger.Fatal("Invalid max balance addresses", zap.Int("max_balance_addresses", serverConfig.MaxBalanceAddresses))
+	}
+	serverConfig.MaxBodySize = int64(getEnvInt("MAX_BODY_BYTES", int(serverConfig.MaxBodySize)))
+	if value := getEnv("RPC_PROXY_METHODS", ""); value != "" {
+		// "none" leaves the allow-list empty, rejecting every proxied request
+		serverConfig.RPCProxyMethods = nil
+		for _, method := range strings.Split(value, ",") {
+			if method = strings.TrimSpace(method); method != "" && method != "none" {
+				serverConfig.RPCProxyMethods = append(serverConfig.RPCProxyMethods, method)
+			}
+		}
+	}
+	serverConfig.StatsMaxMissingRatio = getEnvRate("STATS_MAX_MISSING_RATIO", serverConfig.StatsMaxMissingRatio)
+	serverConfig.AdminToken = getEnv("ADMIN_TOKEN", "")
+	serverConfig.EnablePprof = getEnv("ENABLE_PPROF", "false") == "true"
+
+	// Maintenance mode is on when requested or when the maintenance file exists
+	maintenanceFile := getEnv("MAINTENANCE_FILE", "")
+	serverConfig.Maintenance = getEnv("MAINTENANCE_MODE", "false") == "true" || fileExists(maintenanceFile)
+	serverConfig.MaintenanceRetryAfter = time.Duration(getEnvInt("MAINTENANCE_RETRY_AFTER_SECONDS", int(serverConfig.MaintenanceRetryAfter/time.Second))) * time.Second
+	srv := server.NewEnhancedWithConfig(client, serverConfig)
+	if maintenanceFile != "" {
+		go reloadMaintenanceOnSIGHUP(srv, maintenanceFile)
+	}
 
 	// Log startup message
 	logger.Info("Server initialized with rate limiting, metrics, and enhanced logging",
@@ -59,6 +409,78 @@ func main() {
 	}
 }
 
+// verifyChainID logs the chain the client is connected to. When expected is
+// set, it exits unless the node reports that chain ID. Without an expected
+// chain ID a failed lookup is only logged, since the node may still be starting.
+func verifyChainID(client *rpc.EnhancedClient, expected string, timeout time.Duration) {
+	var want *big.Int
+	if expected != "" {
+		var err error
+		if want, err = parseChainID(expected); err != nil {
+			logger.Fatal("Invalid expected chain ID", zap.String("expected_chain_id", expected), zap.Error(err))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		if want != nil {
+			logger.Fatal("Failed to verify chain ID", zap.String("expected_chain_id", expected), zap.Error(err))
+		}
+		logger.Warn("Failed to get chain ID at startup", zap.Error(err))
+		return
+	}
+
+	got, err := hexutil.DecodeBig(chainID)
+	if err != nil {
+		logger.Fatal("Node returned an invalid chain ID", zap.String("chain_id", chainID), zap.Error(err))
+	}
+	logger.Info("Connected to chain",
+		zap.String("chain_id", chainID),
+		zap.String("chain_id_decimal", got.String()),
+		zap.String("chain_name", rpc.ChainName(chainID)))
+
+	if want != nil && want.Cmp(got) != 0 {
+		logger.Fatal("Connected to an unexpected chain",
+			zap.String("chain_id", got.String()),
+			zap.String("expected_chain_id", want.String()))
+	}
+}
+
+// reloadMaintenanceOnSIGHUP re-reads maintenance mode on every SIGHUP, turning
+// it on when path exists and off when it does not
+func reloadMaintenanceOnSIGHUP(srv *server.EnhancedServer, path string) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		logger.Info("Received SIGHUP, reloading maintenance mode", zap.String("maintenance_file", path))
+		srv.SetMaintenance(fileExists(path), "SIGHUP")
+	}
+}
+
+// fileExists reports whether path names an existing file
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// parseChainID parses a chain ID given in decimal or as a 0x-prefixed hex quantity
+func parseChainID(value string) (*big.Int, error) {
+	if strings.HasPrefix(value, "0x") {
+		return hexutil.DecodeBig(value)
+	}
+	id, ok := new(big.Int).SetString(value, 10)
+	if !ok || id.Sign() < 0 {
+		return nil, fmt.Errorf("expected a decimal or 0x-prefixed hex chain ID, got %q", value)
+	}
+	return id, nil
+}
+
 // getEnv gets an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -67,3 +489,114 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvInt gets a non-negative integer environment variable or returns a default value,
+// exiting if the variable is set to something else
+func getEnvInt(key string, defaultValue int) int {
+	valueStr := getEnv(key, strconv.Itoa(defaultValue))
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 0 {
+		logger.Fatal("Invalid integer environment variable", zap.String("key", key), zap.String("value", valueStr), zap.Error(err))
+	}
+	return value
+}
+
+// endpointURLs returns a client's endpoint URLs with credentials redacted, for logging
+func endpointURLs(client *rpc.EnhancedClient) []string {
+	statuses := client.EndpointStatuses()
+	urls := make([]string, len(statuses))
+	for i, status := range statuses {
+		urls[i] = status.URL
+	}
+	return urls
+}
+
+// getEnvRate reads a fraction between 0 and 1 from the environment
+func getEnvRate(key string, defaultValue float64) float64 {
+	valueStr := getEnv(key, strconv.FormatFloat(defaultValue, 'f', -1, 64))
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil || value < 0 || value > 1 {
+		logger.Fatal("Invalid rate environment variable", zap.String("key", key), zap.String("value", valueStr), zap.Error(err))
+	}
+	return value
+}
+
+// parseChainURLs parses a comma-separated list of chain=url pairs
+func parseChainURLs(value string) (map[string]string, error) {
+	chains := make(map[string]string)
+	if strings.TrimSpace(value) == "" {
+		return chains, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		name, url, found := strings.Cut(strings.TrimSpace(pair), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		url = strings.TrimSpace(url)
+		if !found || name == "" || url == "" {
+			return nil, fmt.Errorf("expected chain=url, got %q", pair)
+		}
+		if _, exists := chains[name]; exists {
+			return nil, fmt.Errorf("chain %q configured more than once", name)
+		}
+		chains[name] = url
+	}
+	return chains, nil
+}
+
+// parseTrustedProxies parses a comma-separated list of proxy IPs and CIDR
+// ranges. "none" trusts no proxy, so client IPs are always taken from the
+// connection.
+func parseTrustedProxies(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "none" {
+		return nil, nil
+	}
+
+	var proxies []string
+	for _, field := range strings.Split(value, ",") {
+		proxy := strings.TrimSpace(field)
+		if strings.Contains(proxy, "/") {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return nil, fmt.Errorf("invalid CIDR range %q", proxy)
+			}
+		} else if net.ParseIP(proxy) == nil {
+			return nil, fmt.Errorf("invalid proxy IP %q", proxy)
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies, nil
+}
+
+// parseBuckets parses a comma-separated list of histogram bucket bounds
+func parseBuckets(value string) ([]float64, error) {
+	var buckets []float64
+	for _, field := range strings.Split(value, ",") {
+		bucket, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket %q", field)
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
+}
+
+// parseHeaders parses a comma-separated list of name=value header pairs
+func parseHeaders(value string) (map[string]string, error) {
+	headers := make(map[string]string)
+	if strings.TrimSpace(value) == "" {
+		return headers, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		name, headerValue, found := strings.Cut(strings.TrimSpace(pair), "=")
+		name = strings.TrimSpace(name)
+		// The pair is not quoted since its value may be a credential
+		if !found || name == "" {
+			return nil, fmt.Errorf("expected name=value, got a pair without a header name")
+		}
+		if strings.ContainsAny(name, " \t:") {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+		headers[name] = strings.TrimSpace(headerValue)
+	}
+	return headers, nil
+}
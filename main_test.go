@@ -118,3 +118,80 @@ func TestGetBlockByNumberEndpoint(t *testing.T) {
 	assert.Equal(t, "0x1234", response.Number)
 	assert.Equal(t, "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef", response.Hash)
 }
+
+func TestParseChainURLs(t *testing.T) {
+	chains, err := parseChainURLs("polygon=https://polygon-rpc.com/, Ethereum=https://eth.llamarpc.com")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"polygon":  "https://polygon-rpc.com/",
+		"ethereum": "https://eth.llamarpc.com",
+	}, chains)
+
+	chains, err = parseChainURLs("")
+	assert.NoError(t, err)
+	assert.Empty(t, chains)
+
+	_, err = parseChainURLs("polygon")
+	assert.Error(t, err)
+
+	_, err = parseChainURLs("polygon=https://a,polygon=https://b")
+	assert.Error(t, err)
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := parseHeaders("X-Api-Key=abc=, X-Project-Id = explorer")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"X-Api-Key":    "abc=",
+		"X-Project-Id": "explorer",
+	}, headers)
+
+	headers, err = parseHeaders("")
+	assert.NoError(t, err)
+	assert.Empty(t, headers)
+
+	for _, value := range []string{"X-Api-Key", "=abc", "X Api=abc"} {
+		_, err = parseHeaders(value)
+		assert.Error(t, err, value)
+		if err != nil {
+			assert.NotContains(t, err.Error(), "abc", value)
+		}
+	}
+}
+
+func TestParseBuckets(t *testing.T) {
+	buckets, err := parseBuckets("0.01, 0.1,1,10")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0.01, 0.1, 1, 10}, buckets)
+
+	_, err = parseBuckets("0.1,1s")
+	assert.Error(t, err)
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := parseTrustedProxies("10.0.0.0/8, 192.168.1.10,::1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.10", "::1"}, proxies)
+
+	proxies, err = parseTrustedProxies("none")
+	assert.NoError(t, err)
+	assert.Nil(t, proxies)
+
+	for _, value := range []string{"10.0.0.0/33", "proxy.internal", "10.0.0.1,"} {
+		_, err = parseTrustedProxies(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseChainID(t *testing.T) {
+	for input, want := range map[string]int64{"137": 137, "0x89": 137, "1": 1, "0x1": 1} {
+		id, err := parseChainID(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, want, id.Int64(), input)
+	}
+
+	for _, input := range []string{"polygon", "0x", "-1", "0xzz"} {
+		_, err := parseChainID(input)
+		assert.Error(t, err, input)
+	}
+}
@@ -1,5 +1,12 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+
+	"blockchain-client/pkg/hexutil"
+)
+
 // RPCRequest represents a JSON-RPC request
 type RPCRequest struct {
 	JSONRPC string        `json:"jsonrpc"`
@@ -8,6 +15,15 @@ type RPCRequest struct {
 	ID      int           `json:"id"`
 }
 
+// RawRPCRequest represents a JSON-RPC request whose params and ID are passed
+// to the node undecoded, so values such as large numbers survive unchanged
+type RawRPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
 // BlockNumberResponse represents the response for the eth_blockNumber method
 type BlockNumberResponse struct {
 	JSONRPC string `json:"jsonrpc"`
@@ -15,6 +31,14 @@ type BlockNumberResponse struct {
 	Result  string `json:"result"`
 }
 
+// StringResponse represents a response whose result is a single string, such as
+// a hex quantity returned by eth_getBalance
+type StringResponse struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Result  string `json:"result"`
+}
+
 // BlockResponse represents the response for the eth_getBlockByNumber method
 type BlockResponse struct {
 	JSONRPC string `json:"jsonrpc"`
@@ -22,6 +46,43 @@ type BlockResponse struct {
 	Result  *Block `json:"result"`
 }
 
+// TransactionResponse represents the response for the eth_getTransactionByHash method
+type TransactionResponse struct {
+	JSONRPC string       `json:"jsonrpc"`
+	ID      int          `json:"id"`
+	Result  *Transaction `json:"result"`
+}
+
+// TransactionReceiptResponse represents the response for the eth_getTransactionReceipt method
+type TransactionReceiptResponse struct {
+	JSONRPC string              `json:"jsonrpc"`
+	ID      int                 `json:"id"`
+	Result  *TransactionReceipt `json:"result"`
+}
+
+// LogsResponse represents the response for the eth_getLogs method
+type LogsResponse struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Result  []Log  `json:"result"`
+}
+
+// FeeHistoryResponse represents the response for the eth_feeHistory method
+type FeeHistoryResponse struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int         `json:"id"`
+	Result  *FeeHistory `json:"result"`
+}
+
+// RPCResponse represents a generic JSON-RPC response whose result is decoded later,
+// as returned for each entry of a batch request
+type RPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
 // RPCErrorResponse represents an error response from the JSON-RPC API
 type RPCErrorResponse struct {
 	JSONRPC string    `json:"jsonrpc"`
@@ -31,8 +92,9 @@ type RPCErrorResponse struct {
 
 // RPCError represents the error object in an RPC error response
 type RPCError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
 }
 
 // Block represents a block in the blockchain
@@ -56,6 +118,123 @@ type Block struct {
 	Timestamp        string        `json:"timestamp"`
 	Transactions     []Transaction `json:"transactions"`
 	Uncles           []string      `json:"uncles"`
+
+	// Post-London (EIP-1559) and post-Merge header fields
+	BaseFeePerGas string `json:"baseFeePerGas,omitempty"`
+	MixHash       string `json:"mixHash,omitempty"`
+
+	// Post-Shanghai (EIP-4895) validator withdrawals
+	WithdrawalsRoot string       `json:"withdrawalsRoot,omitempty"`
+	Withdrawals     []Withdrawal `json:"withdrawals,omitempty"`
+
+	// TransactionHashes holds the transaction list when the block was fetched
+	// without full transaction objects
+	TransactionHashes []string `json:"-"`
+
+	// TransactionErrors lists transactions that could not be decoded and are
+	// therefore missing from Transactions
+	TransactionErrors []TransactionDecodeError `json:"transactionErrors,omitempty"`
+
+	// Pagination describes the page of transactions listed when the API was
+	// asked for one. It is never set by the node.
+	Pagination *TransactionPagination `json:"pagination,omitempty"`
+}
+
+// TransactionPagination describes a page of a block's transactions
+type TransactionPagination struct {
+	// Total is the number of transactions in the block
+	Total  int `json:"total"`
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// TransactionDecodeError describes a transaction of a block that could not be
+// decoded. Index is the transaction's position in the block as sent by the node.
+type TransactionDecodeError struct {
+	Index int    `json:"index"`
+	Hash  string `json:"hash,omitempty"`
+	Error string `json:"error"`
+}
+
+// Withdrawal represents a validator withdrawal included in a post-Shanghai block.
+// Amount is denominated in gwei.
+type Withdrawal struct {
+	Index          string `json:"index"`
+	ValidatorIndex string `json:"validatorIndex"`
+	Address        string `json:"address"`
+	Amount         string `json:"amount"`
+}
+
+// UnmarshalJSON decodes a block whose transactions are either full objects or
+// bare hashes, depending on how it was requested from the node
+func (b *Block) UnmarshalJSON(data []byte) error {
+	type block Block
+	raw := struct {
+		*block
+		Transactions json.RawMessage `json:"transactions"`
+	}{block: (*block)(b)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	b.Transactions, b.TransactionHashes, b.TransactionErrors = nil, nil, nil
+	if len(raw.Transactions) == 0 || string(raw.Transactions) == "null" {
+		return nil
+	}
+
+	var hashes []string
+	if json.Unmarshal(raw.Transactions, &hashes) == nil {
+		b.TransactionHashes = hashes
+		return nil
+	}
+
+	// Transactions are decoded one by one so a malformed transaction is
+	// recorded in TransactionErrors without losing the rest of the block
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw.Transactions, &items); err != nil {
+		return err
+	}
+	b.Transactions = make([]Transaction, 0, len(items))
+	for i, item := range items {
+		var tx Transaction
+		if err := json.Unmarshal(item, &tx); err != nil {
+			// Best effort: the hash may itself be what failed to decode
+			var identified struct {
+				Hash string `json:"hash"`
+			}
+			_ = json.Unmarshal(item, &identified)
+			b.TransactionErrors = append(b.TransactionErrors, TransactionDecodeError{
+				Index: i,
+				Hash:  identified.Hash,
+				Error: err.Error(),
+			})
+			continue
+		}
+		b.Transactions = append(b.Transactions, tx)
+	}
+	return nil
+}
+
+// MarshalJSON renders transaction hashes in place of transaction objects for
+// blocks fetched without them, so responses keep the node's shape
+func (b Block) MarshalJSON() ([]byte, error) {
+	type block Block
+	if b.TransactionHashes == nil {
+		return json.Marshal(block(b))
+	}
+	return json.Marshal(struct {
+		block
+		Transactions []string `json:"transactions"`
+	}{block(b), b.TransactionHashes})
+}
+
+// TimestampTime decodes the block's hex unix timestamp as a UTC time
+func (b *Block) TimestampTime() (time.Time, error) {
+	seconds, err := hexutil.DecodeUint64(b.Timestamp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(seconds), 0).UTC(), nil
 }
 
 // Transaction represents a transaction in a block
@@ -71,9 +250,102 @@ type Transaction struct {
 	To               string `json:"to"`
 	TransactionIndex string `json:"transactionIndex"`
 	Value            string `json:"value"`
-	Type             string `json:"type"`
-	ChainID          string `json:"chainId"`
-	V                string `json:"v"`
-	R                string `json:"r"`
-	S                string `json:"s"`
+	// Type is the EIP-2718 transaction type as a hex quantity: 0x0 for legacy,
+	// 0x1 for EIP-2930 access list and 0x2 for EIP-1559 dynamic fee transactions
+	Type    string `json:"type"`
+	ChainID string `json:"chainId"`
+	V       string `json:"v"`
+	R       string `json:"r"`
+	S       string `json:"s"`
+
+	// EIP-1559 fee caps, set on type 0x2 transactions
+	MaxFeePerGas         string `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas,omitempty"`
+	// AccessList is set on type 0x1 and 0x2 transactions
+	AccessList []AccessTuple `json:"accessList,omitempty"`
+}
+
+// AccessTuple is an EIP-2930 access list entry: a contract address and the
+// storage slots the transaction declares it will touch
+type AccessTuple struct {
+	Address     string   `json:"address"`
+	StorageKeys []string `json:"storageKeys"`
+}
+
+// TransactionReceipt represents the receipt of a mined transaction
+type TransactionReceipt struct {
+	TransactionHash   string `json:"transactionHash"`
+	TransactionIndex  string `json:"transactionIndex"`
+	BlockHash         string `json:"blockHash"`
+	BlockNumber       string `json:"blockNumber"`
+	From              string `json:"from"`
+	To                string `json:"to"`
+	Status            string `json:"status"`
+	GasUsed           string `json:"gasUsed"`
+	CumulativeGasUsed string `json:"cumulativeGasUsed"`
+	EffectiveGasPrice string `json:"effectiveGasPrice"`
+	ContractAddress   string `json:"contractAddress"`
+	Logs              []Log  `json:"logs"`
+	LogsBloom         string `json:"logsBloom"`
+	Type              string `json:"type"`
+}
+
+// Log represents an event log emitted by a transaction
+type Log struct {
+	Address          string   `json:"address"`
+	Topics           []string `json:"topics"`
+	Data             string   `json:"data"`
+	BlockNumber      string   `json:"blockNumber"`
+	BlockHash        string   `json:"blockHash"`
+	TransactionHash  string   `json:"transactionHash"`
+	TransactionIndex string   `json:"transactionIndex"`
+	LogIndex         string   `json:"logIndex"`
+	Removed          bool     `json:"removed"`
+}
+
+// FeeHistory is the fee data of a range of blocks returned by eth_feeHistory.
+// BaseFeePerGas has one more entry than GasUsedRatio, the last being the base
+// fee of the block after the newest one. Reward holds, for each block, the
+// priority fee paid at each requested percentile of the block's gas.
+type FeeHistory struct {
+	OldestBlock   string     `json:"oldestBlock"`
+	BaseFeePerGas []string   `json:"baseFeePerGas"`
+	GasUsedRatio  []float64  `json:"gasUsedRatio"`
+	Reward        [][]string `json:"reward,omitempty"`
+}
+
+// TokenTransfer is an ERC-20 Transfer event decoded from a log. Value is the
+// transferred amount in the token's smallest unit, in decimal.
+type TokenTransfer struct {
+	Token           string `json:"token"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Value           string `json:"value"`
+	TransactionHash string `json:"transactionHash"`
+	LogIndex        string `json:"logIndex"`
+}
+
+// CallMsg represents the call object passed to eth_call and eth_estimateGas.
+// Quantities and data are 0x-prefixed hex strings.
+type CallMsg struct {
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Gas      string `json:"gas,omitempty"`
+	GasPrice string `json:"gasPrice,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Data     string `json:"data,omitempty"`
+}
+
+// HealthStatus describes the outcome of an RPC health check
+type HealthStatus struct {
+	Healthy     bool   `json:"healthy"`
+	NetworkID   string `json:"networkId,omitempty"`
+	ChainName   string `json:"chainName,omitempty"`
+	Description string `json:"description"`
+	// PeerCount is nil when the node does not report its peers
+	PeerCount     *uint64 `json:"peerCount,omitempty"`
+	ClientVersion string  `json:"clientVersion,omitempty"`
+	// Warning describes a problem that leaves the node responding but
+	// possibly serving stale data, such as having no peers
+	Warning string `json:"warning,omitempty"`
 }
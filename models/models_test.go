@@ -0,0 +1,145 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockDecodesPreLondonBlock(t *testing.T) {
+	var block Block
+	err := json.Unmarshal([]byte(`{
+		"number": "0x1",
+		"hash": "0x88e96d4537bea4d9c05d12549907b32561d3bf31f45aae734cdc119f13406cb6",
+		"gasUsed": "0x0",
+		"transactions": [],
+		"uncles": []
+	}`), &block)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", block.Number)
+	assert.Empty(t, block.BaseFeePerGas)
+	assert.Empty(t, block.WithdrawalsRoot)
+	assert.Nil(t, block.Withdrawals)
+
+	// Absent fields stay absent when the block is served back
+	encoded, err := json.Marshal(block)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(encoded), "baseFeePerGas")
+	assert.NotContains(t, string(encoded), "withdrawals")
+}
+
+func TestBlockRecordsUndecodableTransactions(t *testing.T) {
+	var block Block
+	err := json.Unmarshal([]byte(`{
+		"number": "0x10",
+		"transactions": [
+			{"hash": "0x01", "nonce": "0x0"},
+			{"hash": "0x02", "nonce": 7},
+			{"hash": "0x03", "nonce": "0x2"}
+		]
+	}`), &block)
+	assert.NoError(t, err)
+	assert.Len(t, block.Transactions, 2)
+	assert.Equal(t, "0x03", block.Transactions[1].Hash)
+	assert.Len(t, block.TransactionErrors, 1)
+	assert.Equal(t, 1, block.TransactionErrors[0].Index)
+	assert.Equal(t, "0x02", block.TransactionErrors[0].Hash)
+	assert.Contains(t, block.TransactionErrors[0].Error, "nonce")
+
+	encoded, err := json.Marshal(block)
+	assert.NoError(t, err)
+	assert.Contains(t, string(encoded), `"transactionErrors":[{"index":1,"hash":"0x02"`)
+
+	// A transaction list that is not an array still fails the block
+	assert.Error(t, json.Unmarshal([]byte(`{"number": "0x10", "transactions": {}}`), &block))
+}
+
+func TestBlockDecodesShanghaiBlock(t *testing.T) {
+	var block Block
+	err := json.Unmarshal([]byte(`{
+		"number": "0x1100000",
+		"baseFeePerGas": "0x5d21dba00",
+		"mixHash": "0x2d3ba6a0e1c23e28ea2b8bc87e91df5e8d1bd4a0a9bc63ec5ddc0f6d7a6f3b9c",
+		"withdrawalsRoot": "0x7a4ecf19774d15cf9c15adf0dd8e8a250c128b26c9e2ab2a08d6c9c8ffbd104f",
+		"withdrawals": [{
+			"index": "0x1a2b3c",
+			"validatorIndex": "0x5f5e1",
+			"address": "0xb9d7934878b5fb9610b3fe8a5e441e8fad7e293f",
+			"amount": "0x1027f3b"
+		}],
+		"transactions": ["0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"]
+	}`), &block)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x5d21dba00", block.BaseFeePerGas)
+	assert.Equal(t, "0x7a4ecf19774d15cf9c15adf0dd8e8a250c128b26c9e2ab2a08d6c9c8ffbd104f", block.WithdrawalsRoot)
+	assert.Equal(t, []Withdrawal{{
+		Index:          "0x1a2b3c",
+		ValidatorIndex: "0x5f5e1",
+		Address:        "0xb9d7934878b5fb9610b3fe8a5e441e8fad7e293f",
+		Amount:         "0x1027f3b",
+	}}, block.Withdrawals)
+	assert.Equal(t, []string{"0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"}, block.TransactionHashes)
+}
+
+func TestTransactionDecodesDynamicFeeFields(t *testing.T) {
+	var tx Transaction
+	err := json.Unmarshal([]byte(`{
+		"blockHash": "0x2ab53b4a2c8e7f0d9c5d6a3f3b1b4b8f0cb0a2a6f27e1c7a9e2e0b2d6f6e7a11",
+		"blockNumber": "0x10d4f",
+		"from": "0xa7d9ddbe1f17865597fbd27ec712455208b6b76d",
+		"gas": "0x5208",
+		"gasPrice": "0x4a817c800",
+		"maxFeePerGas": "0x59682f000",
+		"maxPriorityFeePerGas": "0x3b9aca00",
+		"hash": "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b",
+		"input": "0x",
+		"nonce": "0x15",
+		"to": "0xc2132d05d31c914a87c6611c10748aeb04b58e8f",
+		"transactionIndex": "0x41",
+		"value": "0xf3dbb76162000",
+		"type": "0x2",
+		"accessList": [{
+			"address": "0xc2132d05d31c914a87c6611c10748aeb04b58e8f",
+			"storageKeys": ["0x0000000000000000000000000000000000000000000000000000000000000003"]
+		}],
+		"chainId": "0x89",
+		"v": "0x0",
+		"r": "0x1b5e176d927f8e9ab405058b2d2457392da3e20f328b16ddabcebc33eaac5fea",
+		"s": "0x4ba69724e8f69de52f0125ad8b3c5c2cef33019bac3249e2c0a2192766d1721c"
+	}`), &tx)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x2", tx.Type)
+	assert.Equal(t, "0x59682f000", tx.MaxFeePerGas)
+	assert.Equal(t, "0x3b9aca00", tx.MaxPriorityFeePerGas)
+	assert.Equal(t, []AccessTuple{{
+		Address:     "0xc2132d05d31c914a87c6611c10748aeb04b58e8f",
+		StorageKeys: []string{"0x0000000000000000000000000000000000000000000000000000000000000003"},
+	}}, tx.AccessList)
+}
+
+func TestTransactionDecodesLegacyTransaction(t *testing.T) {
+	var tx Transaction
+	err := json.Unmarshal([]byte(`{
+		"from": "0xa7d9ddbe1f17865597fbd27ec712455208b6b76d",
+		"gas": "0x5208",
+		"gasPrice": "0x4a817c800",
+		"hash": "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b",
+		"nonce": "0x9",
+		"to": "0xc2132d05d31c914a87c6611c10748aeb04b58e8f",
+		"value": "0xde0b6b3a7640000",
+		"type": "0x0",
+		"v": "0x25"
+	}`), &tx)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x0", tx.Type)
+	assert.Equal(t, "0x4a817c800", tx.GasPrice)
+	assert.Empty(t, tx.MaxFeePerGas)
+	assert.Empty(t, tx.MaxPriorityFeePerGas)
+	assert.Nil(t, tx.AccessList)
+
+	encoded, err := json.Marshal(tx)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(encoded), "maxFeePerGas")
+	assert.NotContains(t, string(encoded), "accessList")
+}
@@ -0,0 +1,63 @@
+// Package abi decodes the contract ABI encoding of event log topics and data.
+package abi
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/trie"
+)
+
+// WordSize is the size in bytes of an ABI word, and so of every log topic
+const WordSize = 32
+
+// Errors returned when decoding ABI words
+var (
+	ErrWordLength   = errors.New("ABI word is not 32 bytes")
+	ErrDirtyAddress = errors.New("address word has non-zero padding")
+)
+
+// EventTopic returns the topic identifying an event, the Keccak-256 hash of its
+// canonical signature such as "Transfer(address,address,uint256)"
+func EventTopic(signature string) string {
+	return hexutil.EncodeBytes(trie.Keccak256([]byte(signature)))
+}
+
+// DecodeAddress decodes an address from a 0x-prefixed ABI word, such as an
+// indexed address topic, returning it as lowercase 0x-prefixed hex
+func DecodeAddress(word string) (string, error) {
+	decoded, err := decodeWord(word)
+	if err != nil {
+		return "", err
+	}
+	// Addresses are left-padded with zeros to a full word
+	for _, b := range decoded[:WordSize-20] {
+		if b != 0 {
+			return "", fmt.Errorf("%w: %s", ErrDirtyAddress, word)
+		}
+	}
+	return hexutil.EncodeBytes(decoded[WordSize-20:]), nil
+}
+
+// DecodeUint256 decodes an unsigned integer from a 0x-prefixed ABI word
+func DecodeUint256(word string) (*big.Int, error) {
+	decoded, err := decodeWord(word)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(decoded), nil
+}
+
+// decodeWord decodes a 0x-prefixed hex string holding exactly one ABI word
+func decodeWord(word string) ([]byte, error) {
+	decoded, err := hexutil.DecodeBytes(word)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) != WordSize {
+		return nil, fmt.Errorf("%w: got %d bytes", ErrWordLength, len(decoded))
+	}
+	return decoded, nil
+}
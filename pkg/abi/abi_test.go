@@ -0,0 +1,44 @@
+package abi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventTopic(t *testing.T) {
+	assert.Equal(t, "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+		EventTopic("Transfer(address,address,uint256)"))
+	assert.Equal(t, "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925",
+		EventTopic("Approval(address,address,uint256)"))
+}
+
+func TestDecodeAddress(t *testing.T) {
+	address, err := DecodeAddress("0x000000000000000000000000A0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	assert.NoError(t, err)
+	assert.Equal(t, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", address)
+
+	_, err = DecodeAddress("0x010000000000000000000000a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+	assert.ErrorIs(t, err, ErrDirtyAddress)
+
+	_, err = DecodeAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+	assert.ErrorIs(t, err, ErrWordLength)
+}
+
+func TestDecodeUint256(t *testing.T) {
+	value, err := DecodeUint256("0x00000000000000000000000000000000000000000000000000000000000f4240")
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1000000), value)
+
+	// Values beyond 64 bits decode in full
+	value, err = DecodeUint256("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	assert.NoError(t, err)
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	assert.Equal(t, max, value)
+
+	_, err = DecodeUint256("0x")
+	assert.ErrorIs(t, err, ErrWordLength)
+	_, err = DecodeUint256("0xzz")
+	assert.Error(t, err)
+}
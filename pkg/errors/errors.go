@@ -3,6 +3,9 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
 )
 
 // Common error types for the application
@@ -18,19 +21,70 @@ const (
 	ErrorTypeNotFound     = "not_found_error"  // Duplicate with different name for backward compatibility
 	ErrorTypeValidation   = "validation_error" // For backward compatibility
 	ErrTypePermission     = "permission_error" // For permission-related errors
+	ErrTypeReverted       = "execution_reverted"
+	ErrTypeTooManyResults = "too_many_results"
+	ErrTypeUnsupported    = "unsupported_method"
+	ErrTypeUnavailable    = "service_unavailable"
 )
 
-// Standard errors
+// Stable error codes for conditions clients commonly handle. Errors without
+// one of these report their type in upper case, such as "VALIDATION_ERROR".
+const (
+	CodeBlockNotFound       = "BLOCK_NOT_FOUND"
+	CodeTransactionNotFound = "TRANSACTION_NOT_FOUND"
+	CodeReceiptNotFound     = "RECEIPT_NOT_FOUND"
+	CodeUnknownChain        = "UNKNOWN_CHAIN"
+	CodeInvalidBlockNumber  = "INVALID_BLOCK_NUMBER"
+	CodeInvalidHash         = "INVALID_HASH"
+	CodeInvalidAddress      = "INVALID_ADDRESS"
+	CodeRangeTooLarge       = "RANGE_TOO_LARGE"
+	CodeCircuitOpen         = "CIRCUIT_OPEN"
+	CodeTooManyAddresses    = "TOO_MANY_ADDRESSES"
+	CodeBodyTooLarge        = "BODY_TOO_LARGE"
+	CodeMethodNotAllowed    = "METHOD_NOT_ALLOWED"
+
+	CodeTransactionsRootMismatch = "TRANSACTIONS_ROOT_MISMATCH"
+
+	CodeNonceTooLow             = "NONCE_TOO_LOW"
+	CodeInsufficientFunds       = "INSUFFICIENT_FUNDS"
+	CodeTransactionAlreadyKnown = "TRANSACTION_ALREADY_KNOWN"
+)
+
+// Sentinel errors, one per error type, for use with the standard library:
+// errors.Is(err, ErrNotFound) reports whether err wraps a not-found AppError
 var (
-	ErrNotFound = errors.New("not found")
+	ErrInternal       error = sentinel(ErrTypeInternal)
+	ErrRPC            error = sentinel(ErrTypeRPC)
+	ErrValidation     error = sentinel(ErrTypeValidation)
+	ErrTimeout        error = sentinel(ErrTypeTimeout)
+	ErrAuthentication error = sentinel(ErrTypeAuthentication)
+	ErrAuthorization  error = sentinel(ErrTypeAuthorization)
+	ErrNotFound       error = sentinel(ErrTypeNotFound)
+	ErrBlockchain     error = sentinel(ErrorTypeBlockchain)
+	ErrPermission     error = sentinel(ErrTypePermission)
+	ErrReverted       error = sentinel(ErrTypeReverted)
+	ErrTooManyResults error = sentinel(ErrTypeTooManyResults)
+	ErrUnsupported    error = sentinel(ErrTypeUnsupported)
+	ErrUnavailable    error = sentinel(ErrTypeUnavailable)
 )
 
+// sentinel is an error standing for every AppError of one type
+type sentinel string
+
+// Error implements the error interface
+func (s sentinel) Error() string {
+	return string(s)
+}
+
 // AppError represents a structured application error
 type AppError struct {
 	Type    string
 	Message string
-	Err     error
-	Data    map[string]interface{}
+	// Code identifies the condition for clients independently of the
+	// human-readable message, which may change
+	Code string
+	Err  error
+	Data map[string]interface{}
 }
 
 // Error implements the error interface
@@ -46,6 +100,13 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
+// Is reports whether target is the sentinel for the error's type, so that
+// errors.Is(err, ErrValidation) matches any validation AppError in err's chain
+func (e *AppError) Is(target error) bool {
+	s, ok := target.(sentinel)
+	return ok && string(s) == e.Type
+}
+
 // WithData adds contextual data to the error
 func (e *AppError) WithData(data map[string]interface{}) *AppError {
 	if e.Data == nil {
@@ -59,6 +120,20 @@ func (e *AppError) WithData(data map[string]interface{}) *AppError {
 	return e
 }
 
+// WithCode sets the stable error code reported to clients
+func (e *AppError) WithCode(code string) *AppError {
+	e.Code = code
+	return e
+}
+
+// ErrorCode returns the error's code, defaulting to its type in upper case
+func (e *AppError) ErrorCode() string {
+	if e.Code != "" {
+		return e.Code
+	}
+	return strings.ToUpper(e.Type)
+}
+
 // New creates a new application error with the given type and message
 func New(errType, message string) *AppError {
 	return &AppError{
@@ -116,13 +191,34 @@ func NewNotFoundError(message string, err error) *AppError {
 	return NewAppError(ErrTypeNotFound, message, err)
 }
 
+// NewRevertedError creates a new execution reverted error
+func NewRevertedError(message string, err error) *AppError {
+	return NewAppError(ErrTypeReverted, message, err)
+}
+
+// NewTooManyResultsError creates a new error for queries the node refused to answer in full
+func NewTooManyResultsError(message string, err error) *AppError {
+	return NewAppError(ErrTypeTooManyResults, message, err)
+}
+
+// NewUnsupportedError creates a new error for RPC methods the node does not offer
+func NewUnsupportedError(message string, err error) *AppError {
+	return NewAppError(ErrTypeUnsupported, message, err)
+}
+
+// NewUnavailableError creates a new error for requests the server is deliberately not serving
+func NewUnavailableError(message string, err error) *AppError {
+	return NewAppError(ErrTypeUnavailable, message, err)
+}
+
 // IsAppError checks if an error is an AppError and returns it
 func IsAppError(err error) (*AppError, bool) {
 	appErr, ok := err.(*AppError)
 	return appErr, ok
 }
 
-// IsType checks if an error is of a specific type
+// IsType checks if an error is of a specific type. Unlike errors.Is with a
+// sentinel, it only looks at err itself, not at the errors it wraps.
 func IsType(err error, errType string) bool {
 	appErr, ok := IsAppError(err)
 	if !ok {
@@ -130,3 +226,144 @@ func IsType(err error, errType string) bool {
 	}
 	return appErr.Type == errType
 }
+
+// Cause returns the AppError that decides how err is reported to clients.
+// That is the first AppError in err's chain, unless it is a generic blockchain
+// or RPC failure wrapping a more specific AppError, such as a timeout or an
+// open circuit breaker, in which case the first specific one below it decides.
+// Callers commonly re-wrap errors as blockchain errors, and the wrapper would
+// otherwise hide what actually went wrong. Errors joining several failures,
+// such as batch errors, are not looked into; their wrapper speaks for them.
+func Cause(err error) (*AppError, bool) {
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		return nil, false
+	}
+	if !isGeneric(appErr.Type) {
+		return appErr, true
+	}
+
+	for inner := appErr.Err; inner != nil; inner = errors.Unwrap(inner) {
+		if specific, ok := inner.(*AppError); ok && !isGeneric(specific.Type) {
+			return specific, true
+		}
+	}
+	return appErr, true
+}
+
+// isGeneric reports whether errType only says that the upstream node failed
+func isGeneric(errType string) bool {
+	return errType == ErrorTypeBlockchain || errType == ErrTypeRPC
+}
+
+// HTTPStatus returns the HTTP status code that reports err to a client. The
+// AppError returned by Cause decides; any other error is a 500.
+func HTTPStatus(err error) int {
+	appErr, ok := Cause(err)
+	if !ok {
+		return http.StatusInternalServerError
+	}
+
+	switch appErr.Type {
+	case ErrTypeValidation, ErrTypeTooManyResults:
+		switch appErr.Code {
+		case CodeBodyTooLarge:
+			return http.StatusRequestEntityTooLarge
+		case CodeMethodNotAllowed:
+			return http.StatusMethodNotAllowed
+		}
+		return http.StatusBadRequest
+	case ErrTypeAuthentication:
+		return http.StatusUnauthorized
+	case ErrTypeAuthorization, ErrTypePermission:
+		return http.StatusForbidden
+	case ErrTypeNotFound:
+		return http.StatusNotFound
+	case ErrTypeReverted:
+		// The call executed; the contract rejected it
+		return http.StatusUnprocessableEntity
+	case ErrTypeUnsupported:
+		return http.StatusNotImplemented
+	case ErrTypeTimeout:
+		return http.StatusGatewayTimeout
+	case ErrTypeUnavailable:
+		return http.StatusServiceUnavailable
+	case ErrorTypeBlockchain, ErrTypeRPC:
+		// The upstream node failed rather than this server
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// FieldsKey is the Data key under which validation errors list their invalid
+// input fields, mapping each field name to the reason it was rejected
+const FieldsKey = "fields"
+
+// FieldErrors accumulates validation failures of individual input fields so a
+// request with several invalid fields reports all of them at once. The zero
+// value is ready to use.
+type FieldErrors struct {
+	reasons map[string]string
+	// first is the first error added, returned as is when it is the only one
+	first *AppError
+}
+
+// Add records why field is invalid. Only the first reason per field is kept.
+func (f *FieldErrors) Add(field, reason string) {
+	f.add(field, reason, NewValidationError(reason, nil))
+}
+
+// AddError records err's message as the reason field is invalid. Nil errors
+// are ignored.
+func (f *FieldErrors) AddError(field string, err error) {
+	if err == nil {
+		return
+	}
+	appErr, ok := IsAppError(err)
+	if !ok {
+		appErr = NewValidationError(err.Error(), err)
+	}
+	f.add(field, appErr.Message, appErr)
+}
+
+func (f *FieldErrors) add(field, reason string, err *AppError) {
+	if f.reasons == nil {
+		f.reasons = make(map[string]string)
+	}
+	if _, exists := f.reasons[field]; exists {
+		return
+	}
+	f.reasons[field] = reason
+	if f.first == nil {
+		f.first = err
+	}
+}
+
+// Len returns the number of invalid fields recorded
+func (f *FieldErrors) Len() int {
+	return len(f.reasons)
+}
+
+// Err returns a validation error listing the invalid fields under FieldsKey in
+// its Data, or nil when every field was valid. A single invalid field keeps
+// the message, code and data of the error recorded for it.
+func (f *FieldErrors) Err() error {
+	if len(f.reasons) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]string, len(f.reasons))
+	names := make([]string, 0, len(f.reasons))
+	for field, reason := range f.reasons {
+		fields[field] = reason
+		names = append(names, field)
+	}
+
+	if len(names) == 1 {
+		return f.first.WithData(map[string]interface{}{FieldsKey: fields})
+	}
+	sort.Strings(names)
+	return NewValidationError(fmt.Sprintf("Invalid fields: %s", strings.Join(names, ", ")), nil).
+		WithData(map[string]interface{}{FieldsKey: fields})
+}
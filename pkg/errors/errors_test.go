@@ -0,0 +1,134 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	tests := map[string]int{
+		ErrTypeValidation:     http.StatusBadRequest,
+		ErrTypeTooManyResults: http.StatusBadRequest,
+		ErrTypeAuthentication: http.StatusUnauthorized,
+		ErrTypeAuthorization:  http.StatusForbidden,
+		ErrTypePermission:     http.StatusForbidden,
+		ErrTypeNotFound:       http.StatusNotFound,
+		ErrTypeReverted:       http.StatusUnprocessableEntity,
+		ErrTypeUnsupported:    http.StatusNotImplemented,
+		ErrTypeTimeout:        http.StatusGatewayTimeout,
+		ErrTypeUnavailable:    http.StatusServiceUnavailable,
+		ErrorTypeBlockchain:   http.StatusBadGateway,
+		ErrTypeRPC:            http.StatusBadGateway,
+		ErrTypeInternal:       http.StatusInternalServerError,
+		"unknown_error":       http.StatusInternalServerError,
+	}
+	for errType, status := range tests {
+		assert.Equal(t, status, HTTPStatus(New(errType, "message")), errType)
+	}
+
+	// Oversized bodies and unsupported methods are validation errors with their own status
+	assert.Equal(t, http.StatusRequestEntityTooLarge, HTTPStatus(NewValidationError("Request body too large", nil).WithCode(CodeBodyTooLarge)))
+	assert.Equal(t, http.StatusMethodNotAllowed, HTTPStatus(NewValidationError("Method not allowed", nil).WithCode(CodeMethodNotAllowed)))
+
+	// A generic blockchain error defers to the specific AppError it wraps
+	wrapped := fmt.Errorf("handler: %w", Wrap(NewNotFoundError("Block not found", nil), ErrorTypeBlockchain, "Failed"))
+	assert.Equal(t, http.StatusNotFound, HTTPStatus(wrapped))
+	nested := NewBlockchainError("Failed to get block", NewRPCError("Request failed", NewTimeoutError("Request timed out", nil)))
+	assert.Equal(t, http.StatusGatewayTimeout, HTTPStatus(nested))
+
+	// A specific AppError decides even when it wraps another one
+	reclassified := NewNotFoundError("Receipt not found", NewBlockchainError("Failed", NewTimeoutError("Request timed out", nil)))
+	assert.Equal(t, http.StatusNotFound, HTTPStatus(reclassified))
+	assert.Equal(t, http.StatusBadGateway, HTTPStatus(NewBlockchainError("Failed", NewRPCError("Request failed", nil))))
+
+	assert.Equal(t, http.StatusInternalServerError, HTTPStatus(errors.New("plain")))
+	assert.Equal(t, http.StatusInternalServerError, HTTPStatus(nil))
+}
+
+func TestCause(t *testing.T) {
+	timeout := NewTimeoutError("Request to blockchain node timed out", nil)
+	appErr, ok := Cause(NewBlockchainError("Failed to get block", timeout))
+	require.True(t, ok)
+	assert.Same(t, timeout, appErr)
+	assert.Equal(t, "TIMEOUT_ERROR", appErr.ErrorCode())
+
+	open := NewUnavailableError("Circuit breaker is open", nil).WithCode(CodeCircuitOpen)
+	appErr, ok = Cause(fmt.Errorf("handler: %w", Wrap(open, ErrorTypeBlockchain, "Failed")))
+	require.True(t, ok)
+	assert.Equal(t, CodeCircuitOpen, appErr.ErrorCode())
+
+	// Without a specific cause the outermost AppError is kept
+	outer := NewBlockchainError("Failed to get block", NewRPCError("Request failed", errors.New("EOF")))
+	appErr, ok = Cause(outer)
+	require.True(t, ok)
+	assert.Same(t, outer, appErr)
+
+	// Joined failures are reported by their wrapper, not by whichever came first
+	joined := NewBlockchainError("Too many blocks failed", errors.Join(NewNotFoundError("Block not found", nil), errors.New("EOF")))
+	appErr, ok = Cause(joined)
+	require.True(t, ok)
+	assert.Same(t, joined, appErr)
+
+	_, ok = Cause(errors.New("plain"))
+	assert.False(t, ok)
+}
+
+func TestSentinels(t *testing.T) {
+	err := NewNotFoundError("Block not found", nil)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.False(t, errors.Is(err, ErrValidation))
+
+	// Sentinels match through wrapping, including wrapped AppErrors
+	wrapped := fmt.Errorf("handler: %w", NewTimeoutError("Request timed out", nil))
+	assert.True(t, errors.Is(wrapped, ErrTimeout))
+	nested := NewBlockchainError("Failed to get block", NewValidationError("Invalid block number", nil))
+	assert.True(t, errors.Is(nested, ErrBlockchain))
+	assert.True(t, errors.Is(nested, ErrValidation))
+	assert.False(t, IsType(nested, ErrTypeValidation))
+
+	// Two AppErrors of the same type are still distinct errors
+	assert.False(t, errors.Is(NewValidationError("a", nil), NewValidationError("a", nil)))
+
+	var appErr *AppError
+	assert.True(t, errors.As(wrapped, &appErr))
+	assert.Equal(t, ErrTypeTimeout, appErr.Type)
+
+	assert.False(t, errors.Is(errors.New("plain"), ErrInternal))
+}
+
+func TestFieldErrors(t *testing.T) {
+	var fields FieldErrors
+	assert.NoError(t, fields.Err())
+
+	// A single invalid field keeps its error's code and data
+	fields.AddError("to", NewValidationError("to must be a 0x-prefixed 20-byte hex address", nil).
+		WithCode(CodeInvalidAddress).
+		WithData(map[string]interface{}{"to": "0x1234"}))
+	fields.AddError("data", nil)
+	appErr, ok := IsAppError(fields.Err())
+	assert.True(t, ok)
+	assert.Equal(t, ErrTypeValidation, appErr.Type)
+	assert.Equal(t, CodeInvalidAddress, appErr.Code)
+	assert.Equal(t, "to must be a 0x-prefixed 20-byte hex address", appErr.Message)
+	assert.Equal(t, "0x1234", appErr.Data["to"])
+	assert.Equal(t, map[string]string{"to": "to must be a 0x-prefixed 20-byte hex address"}, appErr.Data[FieldsKey])
+
+	// Several are listed together, keeping the first reason per field
+	fields.Add("gas", "gas must be a 0x-prefixed hex quantity")
+	fields.Add("to", "to is required")
+	appErr, ok = IsAppError(fields.Err())
+	assert.True(t, ok)
+	assert.Equal(t, ErrTypeValidation, appErr.Type)
+	assert.Equal(t, "VALIDATION_ERROR", appErr.ErrorCode())
+	assert.Equal(t, "Invalid fields: gas, to", appErr.Message)
+	assert.Equal(t, map[string]string{
+		"to":  "to must be a 0x-prefixed 20-byte hex address",
+		"gas": "gas must be a 0x-prefixed hex quantity",
+	}, appErr.Data[FieldsKey])
+	assert.Equal(t, 2, fields.Len())
+}
@@ -0,0 +1,148 @@
+package hexutil
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Errors returned when decoding hex quantities
+var (
+	ErrEmpty         = errors.New("empty hex string")
+	ErrMissingPrefix = errors.New("hex string without 0x prefix")
+	ErrSyntax        = errors.New("invalid hex string")
+	ErrUint64Range   = errors.New("hex number does not fit in 64 bits")
+	ErrOddLength     = errors.New("hex data of odd length")
+)
+
+// maxQuotedLength bounds how much of an offending input is quoted in errors,
+// since a misbehaving node can return arbitrarily long garbage
+const maxQuotedLength = 32
+
+// FieldError reports a hex value that failed to decode together with the
+// name of the field it came from
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+// Error implements the error interface
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid %s: %v", e.Field, e.Err)
+}
+
+// Unwrap returns the underlying decoding error
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeUint64 decodes a 0x-prefixed hex quantity such as a block number
+func DecodeUint64(hex string) (uint64, error) {
+	digits, err := checkQuantity(hex)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := strconv.ParseUint(digits, 16, 64)
+	if err != nil {
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			return 0, fmt.Errorf("%w: %q", ErrUint64Range, truncate(hex))
+		}
+		return 0, fmt.Errorf("%w: %q", ErrSyntax, truncate(hex))
+	}
+	return value, nil
+}
+
+// DecodeBig decodes a 0x-prefixed hex quantity of arbitrary size, such as a wei amount
+func DecodeBig(hex string) (*big.Int, error) {
+	digits, err := checkQuantity(hex)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := new(big.Int).SetString(digits, 16)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrSyntax, truncate(hex))
+	}
+	return value, nil
+}
+
+// DecodeBigField decodes a hex quantity like DecodeBig, reporting failures as a
+// *FieldError naming field so callers decoding several values can tell them apart
+func DecodeBigField(field, hex string) (*big.Int, error) {
+	value, err := DecodeBig(hex)
+	if err != nil {
+		return nil, &FieldError{Field: field, Err: err}
+	}
+	return value, nil
+}
+
+// DecodeBytes decodes 0x-prefixed hex data such as call input. Unlike a
+// quantity, "0x" is valid and decodes to no bytes.
+func DecodeBytes(data string) ([]byte, error) {
+	if !strings.HasPrefix(data, "0x") && !strings.HasPrefix(data, "0X") {
+		return nil, fmt.Errorf("%w: %q", ErrMissingPrefix, truncate(data))
+	}
+	digits := data[2:]
+	if len(digits)%2 != 0 {
+		return nil, fmt.Errorf("%w: %q", ErrOddLength, truncate(data))
+	}
+	decoded, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrSyntax, truncate(data))
+	}
+	return decoded, nil
+}
+
+// EncodeUint64 encodes a number as a 0x-prefixed hex quantity
+func EncodeUint64(value uint64) string {
+	return "0x" + strconv.FormatUint(value, 16)
+}
+
+// EncodeBig encodes a non-negative number of arbitrary size, such as a wei
+// amount, as a 0x-prefixed hex quantity
+func EncodeBig(value *big.Int) string {
+	return "0x" + value.Text(16)
+}
+
+// EncodeBytes encodes data as 0x-prefixed hex
+func EncodeBytes(data []byte) string {
+	return "0x" + hex.EncodeToString(data)
+}
+
+// checkQuantity validates the prefix and digits of a hex quantity and returns the digits
+func checkQuantity(hex string) (string, error) {
+	if hex == "" {
+		return "", ErrEmpty
+	}
+	if !strings.HasPrefix(hex, "0x") && !strings.HasPrefix(hex, "0X") {
+		return "", fmt.Errorf("%w: %q", ErrMissingPrefix, truncate(hex))
+	}
+
+	digits := hex[2:]
+	if digits == "" {
+		return "", fmt.Errorf("%w: %q", ErrEmpty, truncate(hex))
+	}
+	for _, r := range digits {
+		if !isHexDigit(r) {
+			return "", fmt.Errorf("%w: %q", ErrSyntax, truncate(hex))
+		}
+	}
+	return digits, nil
+}
+
+// truncate shortens an offending input for inclusion in an error message
+func truncate(hex string) string {
+	if len(hex) <= maxQuotedLength {
+		return hex
+	}
+	return hex[:maxQuotedLength] + "..."
+}
+
+// isHexDigit reports whether r is a hexadecimal digit
+func isHexDigit(r rune) bool {
+	return ('0' <= r && r <= '9') || ('a' <= r && r <= 'f') || ('A' <= r && r <= 'F')
+}
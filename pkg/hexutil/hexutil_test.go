@@ -0,0 +1,117 @@
+package hexutil
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeUint64(t *testing.T) {
+	tests := []struct {
+		input string
+		want  uint64
+		err   error
+	}{
+		{"0x0", 0, nil},
+		{"0x134e82a", 0x134e82a, nil},
+		{"0X1F", 31, nil},
+		{"0xffffffffffffffff", math.MaxUint64, nil},
+		{"", 0, ErrEmpty},
+		{"0x", 0, ErrEmpty},
+		{"134e82a", 0, ErrMissingPrefix},
+		{"0xzz", 0, ErrSyntax},
+		{"0x-1", 0, ErrSyntax},
+		{"0x10000000000000000", 0, ErrUint64Range},
+	}
+
+	for _, tt := range tests {
+		got, err := DecodeUint64(tt.input)
+		if tt.err != nil {
+			assert.True(t, errors.Is(err, tt.err), "%q: got %v", tt.input, err)
+			continue
+		}
+		assert.NoError(t, err, tt.input)
+		assert.Equal(t, tt.want, got, tt.input)
+	}
+}
+
+func TestDecodeBig(t *testing.T) {
+	wei, err := DecodeBig("0xde0b6b3a7640000")
+	assert.NoError(t, err)
+	assert.Equal(t, "1000000000000000000", wei.String())
+
+	large, err := DecodeBig("0x10000000000000000")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, large.Cmp(new(big.Int).Lsh(big.NewInt(1), 64)))
+
+	zero, err := DecodeBig("0x0")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), zero.Int64())
+
+	for _, input := range []string{"", "0x", "123", "0xg1"} {
+		_, err := DecodeBig(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestEncodeUint64(t *testing.T) {
+	assert.Equal(t, "0x0", EncodeUint64(0))
+	assert.Equal(t, "0x134e82a", EncodeUint64(0x134e82a))
+
+	// Encoding round-trips through decoding
+	decoded, err := DecodeUint64(EncodeUint64(math.MaxUint64))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), decoded)
+}
+
+func TestEncodeBig(t *testing.T) {
+	assert.Equal(t, "0x0", EncodeBig(big.NewInt(0)))
+	assert.Equal(t, "0xde0b6b3a7640000", EncodeBig(big.NewInt(1000000000000000000)))
+
+	// Values beyond 64 bits round-trip through decoding
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	decoded, err := DecodeBig(EncodeBig(huge))
+	assert.NoError(t, err)
+	assert.Equal(t, huge, decoded)
+}
+
+func TestDecodeBigField(t *testing.T) {
+	value, err := DecodeBigField("value", "0xde0b6b3a7640000")
+	assert.NoError(t, err)
+	assert.Equal(t, "1000000000000000000", value.String())
+
+	_, err = DecodeBigField("gasPrice", "1000000000")
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "gasPrice", fieldErr.Field)
+	assert.True(t, errors.Is(err, ErrMissingPrefix))
+	assert.Equal(t, `invalid gasPrice: hex string without 0x prefix: "1000000000"`, err.Error())
+}
+
+func TestDecodeBytes(t *testing.T) {
+	data, err := DecodeBytes("0xa9059cbb")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xa9, 0x05, 0x9c, 0xbb}, data)
+
+	empty, err := DecodeBytes("0x")
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+
+	_, err = DecodeBytes("0xabc")
+	assert.True(t, errors.Is(err, ErrOddLength))
+	_, err = DecodeBytes("abcd")
+	assert.True(t, errors.Is(err, ErrMissingPrefix))
+	_, err = DecodeBytes("0xzz")
+	assert.True(t, errors.Is(err, ErrSyntax))
+}
+
+func TestErrorsTruncateLongInput(t *testing.T) {
+	garbage := "0x" + strings.Repeat("zz", 1000)
+	_, err := DecodeBig(garbage)
+	assert.True(t, errors.Is(err, ErrSyntax))
+	assert.Equal(t, `invalid hex string: "0xzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz..."`, err.Error())
+}
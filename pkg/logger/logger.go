@@ -1,8 +1,11 @@
 package logger
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -10,12 +13,62 @@ import (
 )
 
 var (
+	// mu guards log and initialized, so the global logger can be swapped
+	// while other goroutines are logging
+	mu sync.RWMutex
 	// Global logger instance
 	log *zap.Logger
-	// Ensure initialization happens only once
-	once sync.Once
+	// initialized is set by the first Init or InitWithRotation; later calls
+	// keep the logger it created
+	initialized bool
+	// level is shared by every core so it can be changed at runtime
+	level = zap.NewAtomicLevel()
 )
 
+// levels maps the supported level names to zap levels
+var levels = map[string]zapcore.Level{
+	"debug": zap.DebugLevel,
+	"info":  zap.InfoLevel,
+	"warn":  zap.WarnLevel,
+	"error": zap.ErrorLevel,
+}
+
+// Encoding selects how a sink writes log entries
+type Encoding string
+
+const (
+	// EncodingConsole writes human-readable, tab-separated lines
+	EncodingConsole Encoding = "console"
+	// EncodingJSON writes one JSON object per line
+	EncodingJSON Encoding = "json"
+)
+
+// ParseEncoding returns the encoding with the given name, console or json
+func ParseEncoding(name string) (Encoding, error) {
+	switch encoding := Encoding(name); encoding {
+	case EncodingConsole, EncodingJSON:
+		return encoding, nil
+	default:
+		return "", fmt.Errorf("unknown log encoding %q: expected console or json", name)
+	}
+}
+
+// SamplingConfig deduplicates repetitive log lines: each second, the first
+// Initial entries with the same level and message are logged, then only every
+// Thereafter-th one
+type SamplingConfig struct {
+	Initial    int
+	Thereafter int
+}
+
+// DefaultSamplingConfig provides the sampling used in production
+func DefaultSamplingConfig() *SamplingConfig {
+	return &SamplingConfig{
+		Initial:    100,
+		Thereafter: 100,
+	}
+}
+
 // Config defines logger configuration
 type Config struct {
 	Level      string
@@ -25,6 +78,10 @@ type Config struct {
 	MaxAge     int // days
 	Compress   bool
 	JSON       bool
+	// Color colors level names when JSON is false
+	Color bool
+	// Sampling deduplicates repetitive log lines; nil logs every entry
+	Sampling *SamplingConfig
 }
 
 // RotationConfig defines configuration for log rotation
@@ -34,6 +91,17 @@ type RotationConfig struct {
 	MaxBackups int
 	MaxAge     int // days
 	Compress   bool
+	// DisableFile logs to stdout only, for deployments that collect stdout
+	DisableFile bool
+	// ConsoleEncoding and FileEncoding select the encoding of the stdout and
+	// file sinks, defaulting to console and JSON respectively
+	ConsoleEncoding Encoding
+	FileEncoding    Encoding
+	// ConsoleColor colors level names on stdout when it is console encoded
+	ConsoleColor bool
+	// Sampling deduplicates repetitive log lines in every sink; nil logs
+	// every entry
+	Sampling *SamplingConfig
 }
 
 // DefaultConfig provides a default configuration for development
@@ -57,61 +125,144 @@ func DefaultRotationConfig() RotationConfig {
 		MaxBackups: 3,
 		MaxAge:     28,
 		Compress:   true,
+
+		ConsoleEncoding: EncodingConsole,
+		FileEncoding:    EncodingJSON,
 	}
 }
 
-// Init initializes the logger with the given configuration
+// newEncoder creates an encoder for the given encoding. Color only applies to
+// console encoding, since escape codes would corrupt JSON.
+func newEncoder(encoding Encoding, color bool) zapcore.Encoder {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "timestamp"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	if encoding == EncodingJSON {
+		return zapcore.NewJSONEncoder(encoderConfig)
+	}
+	if color {
+		encoderConfig.EncodeLevel = zapcore.LowercaseColorLevelEncoder
+	}
+	return zapcore.NewConsoleEncoder(encoderConfig)
+}
+
+// Init initializes the logger with the given configuration. Only the first
+// call to Init or InitWithRotation takes effect; use Reconfigure to replace
+// the logger afterwards.
 func Init(cfg Config) *zap.Logger {
-	once.Do(func() {
-		// Setup output
-		var sink zapcore.WriteSyncer
-		if cfg.OutputPath == "" {
-			sink = zapcore.AddSync(os.Stdout)
-		} else {
-			sink = zapcore.AddSync(&lumberjack.Logger{
-				Filename:   cfg.OutputPath,
-				MaxSize:    cfg.MaxSize,
-				MaxBackups: cfg.MaxBackups,
-				MaxAge:     cfg.MaxAge,
-				Compress:   cfg.Compress,
-			})
-		}
+	return initOnce(func() *zap.Logger { return newLogger(cfg) })
+}
+
+// InitWithRotation initializes the logger to write to stdout and to a rotated
+// file, each with its own encoding. The file sink can be disabled. Only the
+// first call to Init or InitWithRotation takes effect; use
+// ReconfigureWithRotation to replace the logger afterwards.
+func InitWithRotation(levelName string, rotationCfg RotationConfig) *zap.Logger {
+	return initOnce(func() *zap.Logger { return newRotatingLogger(levelName, rotationCfg) })
+}
 
-		// Set encoder
-		var encoder zapcore.Encoder
-		encoderConfig := zap.NewProductionEncoderConfig()
-		encoderConfig.TimeKey = "timestamp"
-		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+// Reconfigure replaces the global logger with one built from cfg, even when
+// the logger was already initialized. Log calls made concurrently go to
+// either the previous or the new logger.
+func Reconfigure(cfg Config) *zap.Logger {
+	return swap(newLogger(cfg))
+}
 
-		if cfg.JSON {
-			encoder = zapcore.NewJSONEncoder(encoderConfig)
-		} else {
-			encoder = zapcore.NewConsoleEncoder(encoderConfig)
-		}
+// ReconfigureWithRotation replaces the global logger with one writing to
+// stdout and a rotated file, like InitWithRotation, even when the logger was
+// already initialized
+func ReconfigureWithRotation(levelName string, rotationCfg RotationConfig) *zap.Logger {
+	return swap(newRotatingLogger(levelName, rotationCfg))
+}
 
-		// Set level
-		level := zap.InfoLevel
-		switch cfg.Level {
-		case "debug":
-			level = zap.DebugLevel
-		case "info":
-			level = zap.InfoLevel
-		case "warn":
-			level = zap.WarnLevel
-		case "error":
-			level = zap.ErrorLevel
-		}
+// Reset discards the global logger, so the next Init or InitWithRotation takes
+// effect again. It is intended for tests.
+func Reset() {
+	mu.Lock()
+	previous := log
+	log = nil
+	initialized = false
+	mu.Unlock()
 
-		core := zapcore.NewCore(encoder, sink, zap.NewAtomicLevelAt(level))
-		log = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
-	})
+	if previous != nil {
+		_ = previous.Sync()
+	}
+}
 
+// initOnce sets the global logger built by build unless it was already
+// initialized, and returns the global logger
+func initOnce(build func() *zap.Logger) *zap.Logger {
+	mu.Lock()
+	defer mu.Unlock()
+	if !initialized || log == nil {
+		log = build()
+		initialized = true
+	}
 	return log
 }
 
-// InitWithRotation initializes the logger with file rotation
-func InitWithRotation(level string, rotationCfg RotationConfig) *zap.Logger {
-	once.Do(func() {
+// swap makes l the global logger and flushes the one it replaces. The
+// previous logger stays usable for calls that already fetched it.
+func swap(l *zap.Logger) *zap.Logger {
+	mu.Lock()
+	previous := log
+	log = l
+	initialized = true
+	mu.Unlock()
+
+	if previous != nil {
+		_ = previous.Sync()
+	}
+	return l
+}
+
+// newLogger builds a logger writing to a single sink from cfg
+func newLogger(cfg Config) *zap.Logger {
+	// Setup output
+	var sink zapcore.WriteSyncer
+	if cfg.OutputPath == "" {
+		sink = zapcore.AddSync(os.Stdout)
+	} else {
+		sink = zapcore.AddSync(&lumberjack.Logger{
+			Filename:   cfg.OutputPath,
+			MaxSize:    cfg.MaxSize,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAge,
+			Compress:   cfg.Compress,
+		})
+	}
+
+	// Set encoder
+	encoding := EncodingConsole
+	if cfg.JSON {
+		encoding = EncodingJSON
+	}
+	encoder := newEncoder(encoding, cfg.Color)
+
+	// Unknown level names fall back to info
+	if SetLevel(cfg.Level) != nil {
+		level.SetLevel(zap.InfoLevel)
+	}
+
+	core := sample(zapcore.NewCore(encoder, sink, level), cfg.Sampling)
+	return zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+}
+
+// newRotatingLogger builds a logger writing to stdout and, unless disabled, a
+// rotated file
+func newRotatingLogger(levelName string, rotationCfg RotationConfig) *zap.Logger {
+	// Unknown level names fall back to info
+	if SetLevel(levelName) != nil {
+		level.SetLevel(zap.InfoLevel)
+	}
+
+	// Configure writers - use zapcore.AddSync to properly wrap writers
+	consoleSink := zapcore.AddSync(os.Stdout)
+	consoleEncoder := newEncoder(rotationCfg.ConsoleEncoding, rotationCfg.ConsoleColor)
+	cores := []zapcore.Core{zapcore.NewCore(consoleEncoder, consoleSink, level)}
+
+	if !rotationCfg.DisableFile {
 		// Configure rotating logger
 		rotatingLogger := &lumberjack.Logger{
 			Filename:   rotationCfg.Filename,
@@ -120,58 +271,84 @@ func InitWithRotation(level string, rotationCfg RotationConfig) *zap.Logger {
 			MaxAge:     rotationCfg.MaxAge,
 			Compress:   rotationCfg.Compress,
 		}
-
-		// Configure writers - use zapcore.AddSync to properly wrap writers
-		consoleSink := zapcore.AddSync(os.Stdout)
 		fileSink := zapcore.AddSync(rotatingLogger)
 
-		// Configure encoder
-		encoderConfig := zap.NewProductionEncoderConfig()
-		encoderConfig.TimeKey = "timestamp"
-		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-
-		consoleEncoder := zapcore.NewConsoleEncoder(encoderConfig)
-		fileEncoder := zapcore.NewJSONEncoder(encoderConfig)
-
-		// Set level
-		zapLevel := zap.InfoLevel
-		switch level {
-		case "debug":
-			zapLevel = zap.DebugLevel
-		case "info":
-			zapLevel = zap.InfoLevel
-		case "warn":
-			zapLevel = zap.WarnLevel
-		case "error":
-			zapLevel = zap.ErrorLevel
+		// The file is read by log shippers, so it is JSON unless asked otherwise
+		fileEncoding := rotationCfg.FileEncoding
+		if fileEncoding == "" {
+			fileEncoding = EncodingJSON
 		}
-		atomicLevel := zap.NewAtomicLevelAt(zapLevel)
+		cores = append(cores, zapcore.NewCore(newEncoder(fileEncoding, false), fileSink, level))
+	}
 
-		// Create core for both console and file output
-		core := zapcore.NewTee(
-			zapcore.NewCore(consoleEncoder, consoleSink, atomicLevel),
-			zapcore.NewCore(fileEncoder, fileSink, atomicLevel),
-		)
+	// Create core for console and file output
+	core := sample(zapcore.NewTee(cores...), rotationCfg.Sampling)
 
-		// Create logger
-		log = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
-	})
+	// Create logger
+	return zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+}
 
-	return log
+// sample wraps core to apply the sampling configuration, if any
+func sample(core zapcore.Core, cfg *SamplingConfig) zapcore.Core {
+	if cfg == nil {
+		return core
+	}
+	return zapcore.NewSamplerWithOptions(core, time.Second, cfg.Initial, cfg.Thereafter)
+}
+
+// SetLevel changes the minimum level of the global logger, taking effect for
+// all subsequent log calls. Names other than debug, info, warn and error are
+// rejected and leave the level unchanged.
+func SetLevel(name string) error {
+	zapLevel, ok := levels[name]
+	if !ok {
+		return fmt.Errorf("unknown log level %q: expected debug, info, warn or error", name)
+	}
+	level.SetLevel(zapLevel)
+	return nil
+}
+
+// Level returns the name of the global logger's current minimum level
+func Level() string {
+	return level.Level().String()
 }
 
 // GetLogger returns the global logger instance, initializing with defaults if necessary
 func GetLogger() *zap.Logger {
-	if log == nil {
+	mu.RLock()
+	l := log
+	mu.RUnlock()
+
+	if l == nil {
 		return Init(DefaultConfig())
 	}
-	return log
+	return l
+}
+
+// Replace swaps the global logger and returns a function that restores the
+// previous one. It is intended for tests and for embedding applications that
+// manage their own zap logger.
+func Replace(l *zap.Logger) func() {
+	mu.Lock()
+	previous := log
+	log = l
+	mu.Unlock()
+
+	return func() {
+		mu.Lock()
+		log = previous
+		mu.Unlock()
+	}
 }
 
 // Sync flushes any buffered log entries
 func Sync() error {
-	if log != nil {
-		return log.Sync()
+	mu.RLock()
+	l := log
+	mu.RUnlock()
+
+	if l != nil {
+		return l.Sync()
 	}
 	return nil
 }
@@ -181,6 +358,35 @@ func Debug(msg string, fields ...zap.Field) {
 	GetLogger().Debug(msg, fields...)
 }
 
+// Verbose logs a debug message even when the configured level would drop it.
+// It is meant for targeted debugging, such as logging a single route in detail
+// without enabling debug logging globally.
+func Verbose(msg string, fields ...zap.Field) {
+	GetLogger().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return forcedCore{core}
+	})).Debug(msg, fields...)
+}
+
+// forcedCore wraps a core so entries reach it regardless of its level
+type forcedCore struct {
+	zapcore.Core
+}
+
+// Enabled reports every level as enabled
+func (f forcedCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With adds structured context while keeping the level bypass
+func (f forcedCore) With(fields []zapcore.Field) zapcore.Core {
+	return forcedCore{f.Core.With(fields)}
+}
+
+// Check adds the wrapped core to the entry without consulting its level
+func (f forcedCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, f)
+}
+
 // Info logs an info message
 func Info(msg string, fields ...zap.Field) {
 	GetLogger().Info(msg, fields...)
@@ -201,6 +407,58 @@ func Fatal(msg string, fields ...zap.Field) {
 	GetLogger().Fatal(msg, fields...)
 }
 
+// requestIDKey is the context key under which the request ID is stored
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID id
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or "" if none
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithRequestID returns a logger that tags entries with the request ID carried
+// by ctx, such as a handler's c.Request.Context(). Without one it returns the
+// global logger unchanged.
+func WithRequestID(ctx context.Context) *zap.Logger {
+	// The global logger skips a frame for this package's wrappers, which
+	// callers of the returned logger do not go through
+	l := GetLogger().WithOptions(zap.AddCallerSkip(-1))
+	if id := RequestIDFromContext(ctx); id != "" {
+		return l.With(zap.String("request_id", id))
+	}
+	return l
+}
+
+// fieldsKey is the context key under which request-scoped log fields are stored
+type fieldsKey struct{}
+
+// IntoContext returns a copy of ctx carrying fields, in addition to those it
+// already carries, for loggers obtained with FromContext. Middleware uses it
+// to stash request-scoped fields such as the client IP once per request.
+func IntoContext(ctx context.Context, fields ...zap.Field) context.Context {
+	existing, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	// Copy so contexts derived from the same parent do not share a backing array
+	combined := make([]zap.Field, 0, len(existing)+len(fields))
+	combined = append(append(combined, existing...), fields...)
+	return context.WithValue(ctx, fieldsKey{}, combined)
+}
+
+// FromContext returns a logger that tags entries with the request ID and the
+// fields carried by ctx, such as a handler's c.Request.Context(). Without any
+// it returns the global logger unchanged.
+func FromContext(ctx context.Context) *zap.Logger {
+	l := WithRequestID(ctx)
+	if fields, _ := ctx.Value(fieldsKey{}).([]zap.Field); len(fields) > 0 {
+		return l.With(fields...)
+	}
+	return l
+}
+
 // With returns a logger with additional fields
 func With(fields ...zap.Field) *zap.Logger {
 	return GetLogger().With(fields...)
@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestParseEncoding(t *testing.T) {
+	encoding, err := ParseEncoding("json")
+	assert.NoError(t, err)
+	assert.Equal(t, EncodingJSON, encoding)
+
+	encoding, err = ParseEncoding("console")
+	assert.NoError(t, err)
+	assert.Equal(t, EncodingConsole, encoding)
+
+	_, err = ParseEncoding("text")
+	assert.Error(t, err)
+}
+
+func TestNewEncoder(t *testing.T) {
+	write := func(encoder zapcore.Encoder) string {
+		var buf bytes.Buffer
+		core := zapcore.NewCore(encoder, zapcore.AddSync(&buf), zap.DebugLevel)
+		zap.New(core).Warn("Node has no peers", zap.Int("peers", 0))
+		return buf.String()
+	}
+
+	assert.Contains(t, write(newEncoder(EncodingJSON, false)), `"level":"warn","timestamp":`)
+	assert.Contains(t, write(newEncoder(EncodingConsole, false)), "\twarn\tNode has no peers\t")
+	assert.Contains(t, write(newEncoder(EncodingConsole, true)), "\x1b[33mwarn\x1b[0m")
+
+	// Escape codes would corrupt JSON, so color is ignored there
+	assert.NotContains(t, write(newEncoder(EncodingJSON, true)), "\x1b[")
+}
+
+func TestReconfigure(t *testing.T) {
+	defer Reset()
+	Reset()
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+
+	Init(Config{Level: "info", OutputPath: first})
+	Info("To the first log")
+
+	// Later Init calls keep the existing logger
+	Init(Config{Level: "info", OutputPath: second})
+	Info("Still to the first log")
+
+	Reconfigure(Config{Level: "debug", OutputPath: second, JSON: true})
+	Debug("To the second log")
+	assert.NoError(t, Sync())
+	assert.Equal(t, "debug", Level())
+
+	firstLog, err := os.ReadFile(first)
+	require.NoError(t, err)
+	assert.Contains(t, string(firstLog), "Still to the first log")
+	assert.NotContains(t, string(firstLog), "To the second log")
+
+	secondLog, err := os.ReadFile(second)
+	require.NoError(t, err)
+	assert.Contains(t, string(secondLog), `"msg":"To the second log"`)
+
+	// After a reset the next Init takes effect again
+	Reset()
+	third := filepath.Join(dir, "third.log")
+	Init(Config{Level: "info", OutputPath: third})
+	Info("To the third log")
+	assert.NoError(t, Sync())
+	thirdLog, err := os.ReadFile(third)
+	require.NoError(t, err)
+	assert.Contains(t, string(thirdLog), "To the third log")
+}
+
+func TestReconfigureWhileLogging(t *testing.T) {
+	defer Reset()
+	Reset()
+
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					Info("Logging during a swap")
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		Reconfigure(Config{Level: "info", OutputPath: filepath.Join(dir, fmt.Sprintf("%d.log", i))})
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestSampling(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "sampled.log")
+	l := newLogger(Config{
+		Level:      "info",
+		OutputPath: output,
+		JSON:       true,
+		Sampling:   &SamplingConfig{Initial: 5, Thereafter: 100},
+	})
+
+	for i := 0; i < 1000; i++ {
+		l.Warn("Upstream unavailable")
+	}
+	l.Warn("Something else")
+	require.NoError(t, l.Sync())
+
+	written, err := os.ReadFile(output)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(written)), "\n")
+
+	// 5 initial entries and 1 in 100 of the remaining 995, plus the other message
+	assert.LessOrEqual(t, len(lines), 15)
+	assert.GreaterOrEqual(t, len(lines), 6)
+	assert.Contains(t, lines[len(lines)-1], "Something else")
+}
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	defer Replace(zap.New(core))()
+
+	ctx := ContextWithRequestID(context.Background(), "req-123")
+	ctx = IntoContext(ctx, zap.String("client_ip", "10.0.0.1"))
+	// Fields added for one lookup do not leak into its siblings
+	block := IntoContext(ctx, zap.String("block_number", "0x10"))
+	tx := IntoContext(ctx, zap.String("tx_hash", "0xabc"))
+
+	FromContext(block).Info("Block lookup")
+	FromContext(tx).Info("Transaction lookup")
+	FromContext(context.Background()).Info("Background work")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 3)
+	assert.Equal(t, map[string]interface{}{
+		"request_id":   "req-123",
+		"client_ip":    "10.0.0.1",
+		"block_number": "0x10",
+	}, entries[0].ContextMap())
+	assert.Equal(t, map[string]interface{}{
+		"request_id": "req-123",
+		"client_ip":  "10.0.0.1",
+		"tx_hash":    "0xabc",
+	}, entries[1].ContextMap())
+	assert.Empty(t, entries[2].ContextMap())
+}
@@ -1,72 +1,272 @@
 package metrics
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 var (
 	// RequestsTotal counts the total number of requests
-	RequestsTotal = promauto.NewCounterVec(
+	RequestsTotal = register(prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "blockchain_client_requests_total",
 			Help: "The total number of API requests",
 		},
 		[]string{"endpoint", "method", "status"},
-	)
+	))
 
-	// RequestDuration tracks the duration of requests
-	RequestDuration = promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "blockchain_client_request_duration_seconds",
-			Help:    "Request duration in seconds",
-			Buckets: prometheus.DefBuckets,
+	// RequestDuration tracks the duration of requests. Its buckets can be
+	// changed with SetDurationBuckets.
+	RequestDuration = register(newRequestDuration(DefaultDurationBuckets))
+
+	// SlowRequestsTotal counts requests whose handler latency exceeded the slow threshold
+	SlowRequestsTotal = register(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blockchain_client_slow_requests_total",
+			Help: "The total number of API requests slower than the configured threshold",
 		},
-		[]string{"endpoint", "method"},
-	)
+		[]string{"route"},
+	))
+
+	// CoalescedRequestsTotal counts requests answered with the response of an
+	// identical request that was already in flight
+	CoalescedRequestsTotal = register(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blockchain_client_coalesced_requests_total",
+			Help: "The total number of API requests served by sharing an identical in-flight request",
+		},
+		[]string{"route"},
+	))
 
 	// RPCRequestsTotal counts RPC requests to the blockchain
-	RPCRequestsTotal = promauto.NewCounterVec(
+	RPCRequestsTotal = register(prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "blockchain_client_rpc_requests_total",
 			Help: "The total number of RPC requests to the blockchain",
 		},
 		[]string{"method", "status"},
-	)
+	))
+
+	// RPCRequestDuration tracks the duration of RPC requests. Its buckets can
+	// be changed with SetDurationBuckets.
+	RPCRequestDuration = register(newRPCRequestDuration(DefaultDurationBuckets))
 
-	// RPCRequestDuration tracks the duration of RPC requests
-	RPCRequestDuration = promauto.NewHistogramVec(
+	// RPCRequestBytes tracks the size of outbound RPC request payloads. Its
+	// count also attributes upstream calls to the source that made them.
+	RPCRequestBytes = register(prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
-			Name:    "blockchain_client_rpc_request_duration_seconds",
-			Help:    "RPC request duration in seconds",
-			Buckets: prometheus.DefBuckets,
+			Name:    "blockchain_client_rpc_request_bytes",
+			Help:    "Size of RPC request payloads sent to the blockchain in bytes",
+			Buckets: prometheus.ExponentialBuckets(64, 4, 8), // 64B to 1MiB
+		},
+		[]string{"method", "source"},
+	))
+
+	// RPCResponseSize tracks the size of RPC response bodies, to tie memory
+	// pressure to the methods returning large payloads
+	RPCResponseSize = register(prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "blockchain_client_rpc_response_bytes",
+			Help:    "Size of RPC response bodies received from the blockchain in bytes, after decompression",
+			Buckets: prometheus.ExponentialBuckets(64, 4, 10), // 64B to 16MiB
 		},
 		[]string{"method"},
-	)
+	))
+
+	// RPCResponseBytesTotal counts the bytes of RPC response bodies received
+	RPCResponseBytesTotal = register(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blockchain_client_rpc_response_bytes_total",
+			Help: "Total bytes of RPC response bodies received from the blockchain, after decompression",
+		},
+		[]string{"method"},
+	))
+
+	// RPCErrorCodesTotal counts JSON-RPC error objects returned by the node,
+	// by method and error code. Codes outside knownRPCErrorCodes are counted
+	// as "other" to bound the label's cardinality.
+	RPCErrorCodesTotal = register(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blockchain_client_rpc_error_code_total",
+			Help: "Total number of JSON-RPC errors returned by the blockchain, by method and error code",
+		},
+		[]string{"method", "code"},
+	))
+
+	// BlockCacheHitsTotal counts block lookups served from the block cache
+	BlockCacheHitsTotal = register(prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "blockchain_client_block_cache_hits_total",
+			Help: "The total number of block lookups served from the cache",
+		},
+	))
+
+	// BlockCacheMissesTotal counts cacheable block lookups that had to go to the node
+	BlockCacheMissesTotal = register(prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "blockchain_client_block_cache_misses_total",
+			Help: "The total number of cacheable block lookups not found in the cache",
+		},
+	))
 
 	// BlockProcessingTime tracks the time to process a block
-	BlockProcessingTime = promauto.NewHistogram(
+	BlockProcessingTime = register(prometheus.NewHistogram(
 		prometheus.HistogramOpts{
 			Name:    "blockchain_client_block_processing_seconds",
 			Help:    "Time to process a block in seconds",
 			Buckets: prometheus.DefBuckets,
 		},
-	)
+	))
+
+	// RPCCircuitBreakerState tracks the state of each RPC client's circuit breaker
+	RPCCircuitBreakerState = register(prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "blockchain_client_rpc_circuit_breaker_state",
+			Help: "State of the RPC circuit breaker: 0 closed, 1 half-open, 2 open",
+		},
+		[]string{"endpoint"},
+	))
+
+	// RPCInFlightRequests tracks the HTTP requests each RPC client has in flight
+	RPCInFlightRequests = register(prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "blockchain_client_rpc_in_flight_requests",
+			Help: "Number of RPC requests currently in flight to the node",
+		},
+		[]string{"endpoint"},
+	))
+
+	// RateLimitExceededTotal counts requests rejected by each rate limiter
+	RateLimitExceededTotal = register(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blockchain_client_rate_limit_exceeded_total",
+			Help: "Total number of requests rejected with 429 by the rate limiter",
+		},
+		[]string{"group"},
+	))
 
 	// BlockchainHeight tracks the current height of the blockchain
-	BlockchainHeight = promauto.NewGauge(
+	BlockchainHeight = register(prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "blockchain_client_blockchain_height",
 			Help: "Current height of the blockchain",
 		},
+	))
+)
+
+// DefaultDurationBuckets are the RequestDuration and RPCRequestDuration
+// buckets in seconds, from cached responses at a few milliseconds to slow RPC
+// calls running into the request timeout
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// newRequestDuration creates the RequestDuration histogram with buckets
+func newRequestDuration(buckets []float64) *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "blockchain_client_request_duration_seconds",
+			Help:    "Request duration in seconds",
+			Buckets: buckets,
+		},
+		[]string{"endpoint", "method"},
+	)
+}
+
+// newRPCRequestDuration creates the RPCRequestDuration histogram with buckets
+func newRPCRequestDuration(buckets []float64) *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "blockchain_client_rpc_request_duration_seconds",
+			Help:    "RPC request duration in seconds",
+			Buckets: buckets,
+		},
+		[]string{"method"},
 	)
+}
+
+// SetDurationBuckets replaces RequestDuration and RPCRequestDuration with
+// histograms using buckets, upper bounds in seconds, so applications
+// embedding this package can fit them to their latencies. Call it at startup
+// before requests are served: observations already recorded are dropped.
+// buckets must be non-empty, positive and strictly increasing.
+func SetDurationBuckets(buckets []float64) error {
+	if len(buckets) == 0 {
+		return errors.New("no duration buckets given")
+	}
+	for i, bucket := range buckets {
+		if bucket <= 0 {
+			return fmt.Errorf("duration bucket %g is not positive", bucket)
+		}
+		if i > 0 && bucket <= buckets[i-1] {
+			return fmt.Errorf("duration buckets must be strictly increasing, got %g after %g", bucket, buckets[i-1])
+		}
+	}
+
+	prometheus.Unregister(RequestDuration)
+	RequestDuration = register(newRequestDuration(buckets))
+	prometheus.Unregister(RPCRequestDuration)
+	RPCRequestDuration = register(newRPCRequestDuration(buckets))
+	return nil
+}
+
+// Go runtime metrics (go_goroutines, go_gc_duration_seconds, go_memstats_*)
+// and process metrics (process_cpu_seconds_total, process_resident_memory_bytes,
+// process_open_fds). The default registry normally ships with both, in which
+// case these registrations share the existing collectors; registering them
+// here keeps /metrics from silently losing them if that ever changes.
+var (
+	_ = register(collectors.NewGoCollector())
+	_ = register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+)
+
+// registrationErrors collects the metrics that could not be registered
+var (
+	registrationMu     sync.Mutex
+	registrationErrors []error
 )
 
+// register adds collector to the default Prometheus registry. When an
+// application embedding this package has already registered an identical
+// collector, that one is returned and shared. Any other conflict leaves the
+// collector unregistered, so it still records but is not exported, rather than
+// panicking at import. Skipped registrations are reported by RegistrationErrors.
+func register[T prometheus.Collector](collector T) T {
+	err := prometheus.Register(collector)
+	if err == nil {
+		return collector
+	}
+
+	var already prometheus.AlreadyRegisteredError
+	if errors.As(err, &already) {
+		if existing, ok := already.ExistingCollector.(T); ok {
+			return existing
+		}
+	}
+
+	registrationMu.Lock()
+	defer registrationMu.Unlock()
+	registrationErrors = append(registrationErrors, err)
+	return collector
+}
+
+// RegistrationErrors returns the errors of metrics that could not be
+// registered with the default registry and are therefore not exported.
+// Registration happens at import, before logging is configured, so callers
+// should log these once their logger is set up.
+func RegistrationErrors() []error {
+	registrationMu.Lock()
+	defer registrationMu.Unlock()
+	return append([]error(nil), registrationErrors...)
+}
+
 // RecordAPIRequest records metrics for an API request
 func RecordAPIRequest(endpoint, method, status string, duration time.Duration) {
 	RequestsTotal.WithLabelValues(endpoint, method, status).Inc()
@@ -79,6 +279,46 @@ func RecordRPCRequest(method, status string, duration time.Duration) {
 	RPCRequestDuration.WithLabelValues(method).Observe(duration.Seconds())
 }
 
+// RecordRPCRequestSize records the payload size of an outbound RPC request
+// made on behalf of source
+func RecordRPCRequestSize(method, source string, bytes int) {
+	RPCRequestBytes.WithLabelValues(method, source).Observe(float64(bytes))
+}
+
+// RecordRPCResponseSize records the body size of an RPC response
+func RecordRPCResponseSize(method string, bytes int) {
+	RPCResponseSize.WithLabelValues(method).Observe(float64(bytes))
+	RPCResponseBytesTotal.WithLabelValues(method).Add(float64(bytes))
+}
+
+// knownRPCErrorCodes are the JSON-RPC error codes given their own label value:
+// those of the JSON-RPC 2.0 specification, the EIP-1474 Ethereum codes and the
+// code 3 nodes use for reverted calls
+var knownRPCErrorCodes = map[int]bool{
+	-32700: true, // parse error
+	-32600: true, // invalid request
+	-32601: true, // method not found
+	-32602: true, // invalid params
+	-32603: true, // internal error
+	-32000: true, // invalid input
+	-32001: true, // resource not found
+	-32002: true, // resource unavailable
+	-32003: true, // transaction rejected
+	-32004: true, // method not supported
+	-32005: true, // limit exceeded
+	-32006: true, // JSON-RPC version not supported
+	3:      true, // execution reverted
+}
+
+// RecordRPCErrorCode counts a JSON-RPC error object returned for method
+func RecordRPCErrorCode(method string, code int) {
+	label := "other"
+	if knownRPCErrorCodes[code] {
+		label = strconv.Itoa(code)
+	}
+	RPCErrorCodesTotal.WithLabelValues(method, label).Inc()
+}
+
 // RecordBlockProcessing records the time taken to process a block
 func RecordBlockProcessing(duration time.Duration) {
 	BlockProcessingTime.Observe(duration.Seconds())
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterTwiceSharesExistingCollector(t *testing.T) {
+	before := len(RegistrationErrors())
+
+	// An embedding application registering the same metric does not panic and
+	// ends up sharing this package's collector
+	counter := register(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "blockchain_client_block_cache_hits_total",
+		Help: "The total number of block lookups served from the cache",
+	}))
+	assert.Same(t, BlockCacheHitsTotal, counter)
+
+	vec := register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "blockchain_client_rpc_requests_total",
+		Help: "The total number of RPC requests to the blockchain",
+	}, []string{"method", "status"}))
+	assert.Same(t, RPCRequestsTotal, vec)
+	assert.Len(t, RegistrationErrors(), before)
+}
+
+func TestRegisterConflictingCollectorIsSkipped(t *testing.T) {
+	before := len(RegistrationErrors())
+
+	// Same name with different labels cannot be registered
+	conflicting := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "blockchain_client_requests_total",
+		Help: "The total number of API requests",
+	}, []string{"route"})
+
+	var got *prometheus.CounterVec
+	assert.NotPanics(t, func() { got = register(conflicting) })
+	assert.Same(t, conflicting, got)
+	assert.NotPanics(t, func() { got.WithLabelValues("/health").Inc() })
+	assert.Len(t, RegistrationErrors(), before+1)
+}
+
+func TestRuntimeMetricsExported(t *testing.T) {
+	router := gin.New()
+	RegisterMetricsEndpoint(router)
+
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	for _, name := range []string{"go_goroutines", "go_gc_duration_seconds", "go_memstats_heap_alloc_bytes"} {
+		assert.Contains(t, body, "\n"+name, name)
+	}
+	// Process metrics are only available where procfs is
+	if _, err := os.Stat("/proc/self/stat"); err == nil {
+		assert.Contains(t, body, "\nprocess_resident_memory_bytes")
+	}
+}
+
+func TestSetDurationBuckets(t *testing.T) {
+	t.Cleanup(func() {
+		assert.NoError(t, SetDurationBuckets(DefaultDurationBuckets))
+	})
+
+	buckets := []float64{0.05, 0.5, 5}
+	assert.NoError(t, SetDurationBuckets(buckets))
+	RecordRPCRequest("eth_chainId", "success", 100*time.Millisecond)
+
+	// The replacement is what gets exported
+	families, err := prometheus.DefaultGatherer.Gather()
+	assert.NoError(t, err)
+	var bounds []float64
+	for _, family := range families {
+		if family.GetName() != "blockchain_client_rpc_request_duration_seconds" {
+			continue
+		}
+		for _, bucket := range family.GetMetric()[0].GetHistogram().GetBucket() {
+			bounds = append(bounds, bucket.GetUpperBound())
+		}
+	}
+	assert.Equal(t, buckets, bounds)
+
+	for _, invalid := range [][]float64{nil, {0, 1}, {1, 1}, {2, 1}} {
+		assert.Error(t, SetDurationBuckets(invalid), invalid)
+	}
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Snapshot is a human-readable summary of the key operational metrics, for
+// environments that do not scrape Prometheus
+type Snapshot struct {
+	Requests         RequestSnapshot `json:"requests"`
+	RPC              RPCSnapshot     `json:"rpc"`
+	BlockchainHeight float64         `json:"blockchainHeight"`
+	BlockCache       CacheSnapshot   `json:"blockCache"`
+}
+
+// RequestSnapshot summarises the API requests served
+type RequestSnapshot struct {
+	Total float64 `json:"total"`
+	Slow  float64 `json:"slow"`
+}
+
+// RPCSnapshot summarises the RPC requests made to the blockchain, counted by
+// outcome across all methods
+type RPCSnapshot struct {
+	Total   float64 `json:"total"`
+	Success float64 `json:"success"`
+	Error   float64 `json:"error"`
+}
+
+// CacheSnapshot summarises block cache effectiveness. HitRate is the fraction
+// of cacheable lookups served from the cache, or 0 before any lookup.
+type CacheSnapshot struct {
+	Hits    float64 `json:"hits"`
+	Misses  float64 `json:"misses"`
+	HitRate float64 `json:"hitRate"`
+}
+
+// TakeSnapshot summarises the metrics collected by gatherer, normally
+// prometheus.DefaultGatherer
+func TakeSnapshot(gatherer prometheus.Gatherer) (*Snapshot, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*dto.MetricFamily, len(families))
+	for _, family := range families {
+		byName[family.GetName()] = family
+	}
+
+	snapshot := &Snapshot{
+		Requests: RequestSnapshot{
+			Total: sum(byName["blockchain_client_requests_total"], nil),
+			Slow:  sum(byName["blockchain_client_slow_requests_total"], nil),
+		},
+		RPC: RPCSnapshot{
+			Total:   sum(byName["blockchain_client_rpc_requests_total"], nil),
+			Success: sum(byName["blockchain_client_rpc_requests_total"], map[string]string{"status": "success"}),
+			Error:   sum(byName["blockchain_client_rpc_requests_total"], map[string]string{"status": "error"}),
+		},
+		BlockchainHeight: sum(byName["blockchain_client_blockchain_height"], nil),
+		BlockCache: CacheSnapshot{
+			Hits:   sum(byName["blockchain_client_block_cache_hits_total"], nil),
+			Misses: sum(byName["blockchain_client_block_cache_misses_total"], nil),
+		},
+	}
+	if lookups := snapshot.BlockCache.Hits + snapshot.BlockCache.Misses; lookups > 0 {
+		snapshot.BlockCache.HitRate = snapshot.BlockCache.Hits / lookups
+	}
+	return snapshot, nil
+}
+
+// sum adds up the counter or gauge values of the family's series whose labels
+// include all of labels. A missing family sums to 0.
+func sum(family *dto.MetricFamily, labels map[string]string) float64 {
+	if family == nil {
+		return 0
+	}
+
+	var total float64
+	for _, metric := range family.GetMetric() {
+		if !hasLabels(metric, labels) {
+			continue
+		}
+		switch {
+		case metric.Counter != nil:
+			total += metric.Counter.GetValue()
+		case metric.Gauge != nil:
+			total += metric.Gauge.GetValue()
+		}
+	}
+	return total
+}
+
+// hasLabels reports whether metric carries every label in labels
+func hasLabels(metric *dto.Metric, labels map[string]string) bool {
+	matched := 0
+	for _, pair := range metric.GetLabel() {
+		if value, ok := labels[pair.GetName()]; ok && value == pair.GetValue() {
+			matched++
+		}
+	}
+	return matched == len(labels)
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import "context"
+
+// RPC call sources, used to attribute upstream load to the feature that caused it.
+// The set is fixed to keep metric cardinality bounded.
+const (
+	SourceAPI    = "api"
+	SourcePoller = "poller"
+	SourceStats  = "stats"
+	SourceProxy  = "proxy"
+	SourceWarmer = "warmer"
+)
+
+// sourceKey is the context key carrying the RPC call source
+type sourceKey struct{}
+
+// WithSource returns a context that tags RPC calls made with it as coming from
+// source. Unknown sources are recorded as SourceAPI.
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// SourceFromContext returns the RPC call source carried by ctx, defaulting to SourceAPI
+func SourceFromContext(ctx context.Context) string {
+	switch source, _ := ctx.Value(sourceKey{}).(string); source {
+	case SourcePoller, SourceStats, SourceProxy, SourceWarmer:
+		return source
+	default:
+		return SourceAPI
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"blockchain-client/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxBodySize is the default limit, in bytes, on request bodies
+const DefaultMaxBodySize = 1 << 20
+
+// MaxBodySize returns a middleware that limits request bodies to n bytes.
+// Requests declaring a larger Content-Length are rejected before the handler
+// runs. Other bodies are read through http.MaxBytesReader, so binding fails
+// once the limit is passed; the handler's binding error is then superseded by
+// a validation error that ErrorHandler answers with 413. An n of zero or less
+// disables the limit.
+func MaxBodySize(n int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if n <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > n {
+			c.Error(bodyTooLargeError(n, nil))
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, n)
+		c.Next()
+
+		// Handlers wrap binding failures in their own validation errors
+		for _, ginErr := range c.Errors {
+			var tooLarge *http.MaxBytesError
+			if stderrors.As(ginErr.Err, &tooLarge) {
+				c.Error(bodyTooLargeError(n, ginErr.Err))
+				return
+			}
+		}
+	}
+}
+
+// bodyTooLargeError reports a request body exceeding limit bytes
+func bodyTooLargeError(limit int64, err error) *errors.AppError {
+	return errors.NewValidationError(fmt.Sprintf("Request body exceeds %d bytes", limit), err).
+		WithCode(errors.CodeBodyTooLarge).
+		WithData(map[string]interface{}{"limit": limit})
+}
@@ -1,41 +1,124 @@
 package middleware
 
 import (
+	"blockchain-client/pkg/errors"
 	"blockchain-client/pkg/logger"
 	"blockchain-client/pkg/metrics"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ulule/limiter/v3"
-	"github.com/ulule/limiter/v3/drivers/store/memory"
 	"go.uber.org/zap"
 )
 
 // RateLimiterConfig defines configuration for rate limiting middleware
 type RateLimiterConfig struct {
-	Limit          int
-	Period         time.Duration
-	BlockDuration  time.Duration
-	ClientIPHeader string
+	Limit         int
+	Period        time.Duration
+	BlockDuration time.Duration
+	// Name separates this limiter's counters from others sharing the same store
+	// and labels its metrics
+	Name string
+	// Store holds the counters. Nil uses a private in-memory store.
+	Store RateLimiterStore
+	// KeyFunc extracts the identifier of the bucket a request is counted in, so
+	// requests yielding the same key share one limit. Nil, or an empty result,
+	// falls back to the client IP so unidentified requests are never pooled
+	// into a single bucket.
+	KeyFunc func(*gin.Context) string
 }
 
 // DefaultRateLimiterConfig returns a default rate limiter configuration
 func DefaultRateLimiterConfig() RateLimiterConfig {
 	return RateLimiterConfig{
-		Limit:          100,
-		Period:         time.Minute,
-		BlockDuration:  time.Minute * 5,
-		ClientIPHeader: "X-Forwarded-For",
+		Limit:         100,
+		Period:        time.Minute,
+		BlockDuration: time.Minute * 5,
+	}
+}
+
+// APIKeyHeader is the request header carrying a client's API key
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyOrIP returns a RateLimiterConfig.KeyFunc that limits requests per API
+// key when the X-API-Key header carries one of keys. Keys are hashed so raw API
+// keys are never written to the limiter store. Requests without a key, or with
+// one not in keys, return "" and are therefore limited by client IP, so a
+// client cannot get a fresh bucket by making up keys.
+func APIKeyOrIP(keys []string) func(*gin.Context) string {
+	buckets := make(map[[sha256.Size]byte]string, len(keys))
+	for _, key := range keys {
+		if key != "" {
+			sum := sha256.Sum256([]byte(key))
+			buckets[sum] = "key:" + hex.EncodeToString(sum[:])
+		}
+	}
+
+	return func(c *gin.Context) string {
+		apiKey := c.GetHeader(APIKeyHeader)
+		if apiKey == "" {
+			return ""
+		}
+		return buckets[sha256.Sum256([]byte(apiKey))]
+	}
+}
+
+// LoggerConfig defines configuration for the request logging middleware
+type LoggerConfig struct {
+	// SlowThreshold is the handler latency above which a request is logged at
+	// Warn and counted as slow. Zero disables slow request detection.
+	SlowThreshold time.Duration
+	// VerboseRoutes lists route templates, such as "/api/v1/call", whose
+	// request bodies and response summaries are logged at Debug even when the
+	// global log level is higher. Sensitive body fields are redacted.
+	VerboseRoutes []string
+}
+
+// DefaultLoggerConfig returns a default request logging configuration
+func DefaultLoggerConfig() LoggerConfig {
+	return LoggerConfig{
+		SlowThreshold: 2 * time.Second,
 	}
 }
 
 // Logger returns a middleware that logs HTTP requests
 func Logger() gin.HandlerFunc {
+	return LoggerWithConfig(DefaultLoggerConfig())
+}
+
+// LoggerWithConfig returns a middleware that logs HTTP requests, flagging
+// requests slower than the configured threshold
+func LoggerWithConfig(config LoggerConfig) gin.HandlerFunc {
+	verbose := make(map[string]bool, len(config.VerboseRoutes))
+	for _, route := range config.VerboseRoutes {
+		verbose[route] = true
+	}
+
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
 
+		// Handlers and the RPC client tag their logs with these through
+		// logger.FromContext, so they need not repeat them
+		endpoint := c.FullPath()
+		if endpoint == "" {
+			endpoint = "unmatched"
+		}
+		c.Request = c.Request.WithContext(logger.IntoContext(c.Request.Context(),
+			zap.String("client_ip", c.ClientIP()),
+			zap.String("http_method", c.Request.Method),
+			zap.String("endpoint", endpoint)))
+
+		if verbose[c.FullPath()] {
+			logVerboseRequest(c)
+			defer logVerboseResponse(c, start)
+		}
+
 		// Process request
 		c.Next()
 
@@ -45,13 +128,25 @@ func Logger() gin.HandlerFunc {
 		clientIP := c.ClientIP()
 		method := c.Request.Method
 
-		logger.Info("HTTP Request",
+		fields := []zap.Field{
 			zap.String("path", path),
 			zap.String("method", method),
 			zap.Int("status", status),
 			zap.String("client_ip", clientIP),
 			zap.Duration("latency", latency),
-		)
+		}
+		fields = append(fields, requestIDFields(c)...)
+
+		if config.SlowThreshold > 0 && latency > config.SlowThreshold {
+			// Label by the route template to keep metric cardinality bounded
+			metrics.SlowRequestsTotal.WithLabelValues(endpoint).Inc()
+
+			logger.Warn("Slow HTTP Request",
+				append(fields, zap.Duration("threshold", config.SlowThreshold))...)
+			return
+		}
+
+		logger.Info("HTTP Request", fields...)
 	}
 }
 
@@ -79,9 +174,9 @@ func Recovery() gin.HandlerFunc {
 		defer func() {
 			if err := recover(); err != nil {
 				logger.Error("Request panicked",
-					zap.Any("error", err),
-					zap.String("path", c.Request.URL.Path),
-				)
+					append(requestIDFields(c),
+						zap.Any("error", err),
+						zap.String("path", c.Request.URL.Path))...)
 				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 					"error": "Internal server error",
 				})
@@ -95,7 +190,10 @@ func Recovery() gin.HandlerFunc {
 // RateLimiter returns a middleware that limits request rates
 func RateLimiter(config RateLimiterConfig) gin.HandlerFunc {
 	// Create rate limiter store
-	store := memory.NewStore()
+	store := config.Store
+	if store == nil {
+		store = NewMemoryRateLimiterStore()
+	}
 
 	// Create rate limiter instance
 	rate := limiter.Rate{
@@ -105,15 +203,28 @@ func RateLimiter(config RateLimiterConfig) gin.HandlerFunc {
 
 	rateLimiter := limiter.New(store, rate)
 
+	exceeded := metrics.RateLimitExceededTotal.WithLabelValues(config.Name)
+
 	return func(c *gin.Context) {
-		// Get client IP from header or fallback to RemoteAddr
-		clientIP := c.ClientIP()
-		if header := c.GetHeader(config.ClientIPHeader); header != "" {
-			clientIP = header
+		// Identify the bucket from the key function, if any
+		clientKey := ""
+		if config.KeyFunc != nil {
+			clientKey = config.KeyFunc(c)
+		}
+
+		// Otherwise use the client IP. Forwarding headers are only honoured
+		// from the engine's trusted proxies, so clients cannot pick a bucket
+		// by spoofing X-Forwarded-For.
+		if clientKey == "" {
+			clientKey = c.ClientIP()
 		}
 
 		// Get limiter context for this request
-		limiterCtx, err := rateLimiter.Get(c, clientIP)
+		key := clientKey
+		if config.Name != "" {
+			key = config.Name + ":" + clientKey
+		}
+		limiterCtx, err := rateLimiter.Get(c, key)
 		if err != nil {
 			logger.Error("Rate limiter error", zap.Error(err))
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
@@ -124,8 +235,9 @@ func RateLimiter(config RateLimiterConfig) gin.HandlerFunc {
 
 		// Check if request is limited
 		if limiterCtx.Reached {
+			exceeded.Inc()
 			logger.Warn("Rate limit exceeded",
-				zap.String("client_ip", clientIP),
+				zap.String("client_key", clientKey),
 				zap.Int("limit", config.Limit),
 				zap.Duration("period", config.Period))
 
@@ -139,9 +251,55 @@ func RateLimiter(config RateLimiterConfig) gin.HandlerFunc {
 	}
 }
 
+// AdminAuth returns a middleware that only admits requests carrying token as
+// an "Authorization: Bearer" header. Other requests are rejected with 401.
+// An empty token rejects every request, so admin routes are never left open.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			logger.Warn("Unauthorized admin request",
+				zap.String("path", c.Request.URL.Path),
+				zap.String("client_ip", c.ClientIP()))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Unauthorized",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ErrorHandlerConfig defines configuration for the error handling middleware
+type ErrorHandlerConfig struct {
+	// PublicMessages maps an AppError type to the message shown to clients in
+	// place of the internal one. A "{message}" placeholder in a template is
+	// replaced with the internal message. The internal message is always logged.
+	PublicMessages map[string]string
+}
+
+// DefaultErrorHandlerConfig returns a default error handling configuration,
+// which shows the messages of client errors and timeouts and hides all others
+func DefaultErrorHandlerConfig() ErrorHandlerConfig {
+	return ErrorHandlerConfig{}
+}
+
 // ErrorHandler returns a middleware that handles errors from handlers
 func ErrorHandler() gin.HandlerFunc {
+	return ErrorHandlerWithConfig(DefaultErrorHandlerConfig())
+}
+
+// errorHandlerConfigKey is the context key under which ErrorHandler exposes
+// its configuration to Timeout, which answers timed out requests itself
+const errorHandlerConfigKey = "error_handler_config"
+
+// ErrorHandlerWithConfig returns a middleware that handles errors from handlers,
+// replacing client-facing messages according to the configuration
+func ErrorHandlerWithConfig(config ErrorHandlerConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		c.Set(errorHandlerConfigKey, config)
+
 		// Process request
 		c.Next()
 
@@ -155,55 +313,101 @@ func ErrorHandler() gin.HandlerFunc {
 
 		// Log the error
 		logger.Error("Request error",
-			zap.String("path", c.Request.URL.Path),
-			zap.String("method", c.Request.Method),
-			zap.Error(err.Err))
-
-		// Determine the error type and appropriate status code
-		statusCode := http.StatusInternalServerError
-		errorMessage := "Internal server error"
-
-		// Check for known error types
-		if err.IsType(gin.ErrorTypePublic) {
-			// Public errors can be shown to the client
-			errorMessage = err.Error()
-		} else if err.IsType(gin.ErrorTypeBind) {
-			// Binding errors (invalid request parameters)
-			statusCode = http.StatusBadRequest
-			errorMessage = "Invalid request parameters"
-		}
+			append(requestIDFields(c),
+				zap.String("path", c.Request.URL.Path),
+				zap.String("method", c.Request.Method),
+				zap.Error(err.Err))...)
 
 		// Record metrics for errors
 		metrics.RPCRequestsTotal.WithLabelValues(c.Request.Method, "error").Inc()
 
 		// Send error response if one hasn't been sent already
 		if !c.Writer.Written() {
-			c.JSON(statusCode, gin.H{
-				"error": errorMessage,
-			})
+			c.JSON(errorResponse(config, err, c.GetString(RequestIDKey)))
 		}
 	}
 }
 
-// ConfigureRateLimiters sets up rate limiting for various API endpoints
-func ConfigureRateLimiters(router *gin.Engine) {
-	// API endpoints - allow more frequent access
-	apiConfig := DefaultRateLimiterConfig()
-	apiConfig.Limit = 200 // Higher limit for API calls
-
-	// Block height endpoint - very frequent access allowed
-	blockHeightConfig := DefaultRateLimiterConfig()
-	blockHeightConfig.Limit = 500 // Even higher limit for block height queries
+// errorResponse returns the status code and body answering err. AppErrors get
+// the structured envelope; anything else keeps the plain shape. Both carry the
+// request ID, when set, so clients can quote it.
+func errorResponse(config ErrorHandlerConfig, err *gin.Error, requestID string) (int, gin.H) {
+	// Determine the error type and appropriate status code
+	statusCode := http.StatusInternalServerError
+	errorMessage := "Internal server error"
+
+	// Check for known error types
+	appErr, isAppErr := errors.Cause(err.Err)
+	exposed := false
+	if err.IsType(gin.ErrorTypePublic) {
+		// Public errors can be shown to the client
+		errorMessage = err.Error()
+	} else if err.IsType(gin.ErrorTypeBind) {
+		// Binding errors (invalid request parameters)
+		statusCode = http.StatusBadRequest
+		errorMessage = "Invalid request parameters"
+	} else if isAppErr {
+		statusCode = errors.HTTPStatus(appErr)
+		// Client errors and timeouts describe the request rather than our
+		// internals, so their messages and data are safe to show
+		if statusCode < http.StatusInternalServerError || statusCode == http.StatusGatewayTimeout {
+			errorMessage = appErr.Message
+			exposed = true
+		}
+	}
 
-	// Setup rate limiting for specific API groups
-	router.Group("/api").
-		Use(RateLimiter(apiConfig))
+	// Operators may override the client-facing message per error type
+	if isAppErr {
+		if template, ok := config.PublicMessages[appErr.Type]; ok {
+			errorMessage = strings.ReplaceAll(template, "{message}", appErr.Message)
+		}
+	}
 
-	// Higher limits for block queries
-	router.Group("/api/blocks").
-		Use(RateLimiter(blockHeightConfig))
+	if !isAppErr {
+		response := gin.H{"error": errorMessage}
+		if requestID != "" {
+			response["requestId"] = requestID
+		}
+		return statusCode, response
+	}
+	body := gin.H{
+		"type":    appErr.Type,
+		"code":    appErr.ErrorCode(),
+		"message": errorMessage,
+	}
+	if exposed {
+		// Invalid input fields get their own key so clients can
+		// highlight them; the rest of the data is passed through
+		data := appErr.Data
+		if fields, ok := data[errors.FieldsKey]; ok {
+			body["fields"] = fields
+			data = make(map[string]interface{}, len(appErr.Data))
+			for k, v := range appErr.Data {
+				if k != errors.FieldsKey {
+					data[k] = v
+				}
+			}
+		}
+		if len(data) > 0 {
+			body["data"] = data
+		}
+	}
+	if requestID != "" {
+		body["requestId"] = requestID
+	}
+	return statusCode, gin.H{"error": body}
+}
 
-	// Default rate limiting for all other endpoints
+// ConfigureRateLimiters sets up rate limiting for all endpoints, keeping
+// counters in store and bucketing requests by keyFunc. A nil store keeps
+// counters in memory per instance; a nil keyFunc limits per client IP.
+//
+// Every request counts against a single "default" limiter, so a client's
+// requests share one bucket whichever endpoint they hit.
+func ConfigureRateLimiters(router *gin.Engine, store RateLimiterStore, keyFunc func(*gin.Context) string) {
 	defaultConfig := DefaultRateLimiterConfig()
+	defaultConfig.Name = "default"
+	defaultConfig.Store = store
+	defaultConfig.KeyFunc = keyFunc
 	router.Use(RateLimiter(defaultConfig))
 }
@@ -0,0 +1,582 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLoggerFlagsSlowRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(LoggerWithConfig(LoggerConfig{SlowThreshold: 10 * time.Millisecond}))
+	router.GET("/slow/:id", func(c *gin.Context) {
+		time.Sleep(20 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+	router.GET("/fast/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	slowBefore := testutil.ToFloat64(metrics.SlowRequestsTotal.WithLabelValues("/slow/:id"))
+	fastBefore := testutil.ToFloat64(metrics.SlowRequestsTotal.WithLabelValues("/fast/:id"))
+
+	for _, path := range []string{"/slow/1", "/fast/1"} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// Only the slow handler is counted, labeled by its route template
+	assert.Equal(t, slowBefore+1, testutil.ToFloat64(metrics.SlowRequestsTotal.WithLabelValues("/slow/:id")))
+	assert.Equal(t, fastBefore, testutil.ToFloat64(metrics.SlowRequestsTotal.WithLabelValues("/fast/:id")))
+}
+
+func TestErrorHandlerPublicMessageOverride(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	core, logs := observer.New(zapcore.ErrorLevel)
+	defer logger.Replace(zap.New(core))()
+
+	router := gin.New()
+	router.Use(ErrorHandlerWithConfig(ErrorHandlerConfig{
+		PublicMessages: map[string]string{
+			errors.ErrorTypeBlockchain: "The blockchain node is unavailable, please retry",
+			errors.ErrTypeNotFound:     "Nothing here: {message}",
+		},
+	}))
+	router.GET("/blockchain", func(c *gin.Context) {
+		c.Error(errors.NewBlockchainError("upstream 10.0.0.5:8545 refused connection", nil))
+	})
+	router.GET("/missing", func(c *gin.Context) {
+		c.Error(errors.NewNotFoundError("Block not found", nil))
+	})
+	router.GET("/internal", func(c *gin.Context) {
+		c.Error(errors.NewInternalError("nil pointer in cache", nil))
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The override reaches the client while the internal message is only logged
+	w := serve("/blockchain")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.JSONEq(t, `{"error":{"type":"blockchain_error","code":"BLOCKCHAIN_ERROR","message":"The blockchain node is unavailable, please retry"}}`, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "10.0.0.5")
+	assert.Equal(t, 1, logs.FilterMessage("Request error").FilterFieldKey("error").Len())
+	assert.Contains(t, logs.All()[0].ContextMap()["error"], "10.0.0.5:8545 refused connection")
+
+	// Templates can embed the internal message, and the status is unchanged
+	w = serve("/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":{"type":"not_found_error","code":"NOT_FOUND_ERROR","message":"Nothing here: Block not found"}}`, w.Body.String())
+
+	// Types without an override keep the default behavior
+	w = serve("/internal")
+	assert.JSONEq(t, `{"error":{"type":"internal_error","code":"INTERNAL_ERROR","message":"Internal server error"}}`, w.Body.String())
+}
+
+func TestErrorHandlerEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/block", func(c *gin.Context) {
+		c.Error(errors.NewNotFoundError("Block not found", nil).
+			WithCode(errors.CodeBlockNotFound).
+			WithData(map[string]interface{}{"block_number": "0x10"}))
+	})
+	router.GET("/upstream", func(c *gin.Context) {
+		c.Error(errors.NewBlockchainError("RPC error", nil).
+			WithData(map[string]interface{}{"response": "internal node details"}))
+	})
+	router.GET("/plain", func(c *gin.Context) {
+		c.Error(fmt.Errorf("something broke"))
+	})
+	router.GET("/fields", func(c *gin.Context) {
+		var fields errors.FieldErrors
+		fields.Add("to", "to is required")
+		fields.Add("gas", "gas must be a 0x-prefixed hex quantity")
+		c.Error(fields.Err())
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/block")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":{
+		"type": "not_found_error",
+		"code": "BLOCK_NOT_FOUND",
+		"message": "Block not found",
+		"data": {"block_number": "0x10"}
+	}}`, w.Body.String())
+
+	// Data of hidden server errors is not exposed either
+	w = serve("/upstream")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.JSONEq(t, `{"error":{"type":"blockchain_error","code":"BLOCKCHAIN_ERROR","message":"Internal server error"}}`, w.Body.String())
+
+	// Errors that are not AppErrors keep the plain shape
+	w = serve("/plain")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"error":"Internal server error"}`, w.Body.String())
+
+	// Invalid fields are listed beside the data rather than in it
+	w = serve("/fields")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":{
+		"type": "validation_error",
+		"code": "VALIDATION_ERROR",
+		"message": "Invalid fields: gas, to",
+		"fields": {"to": "to is required", "gas": "gas must be a 0x-prefixed hex quantity"}
+	}}`, w.Body.String())
+}
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	defer logger.Replace(zap.New(core))()
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.Use(Logger())
+	router.Use(ErrorHandler())
+	router.GET("/fail", func(c *gin.Context) {
+		logger.FromContext(c.Request.Context()).Info("Handling request")
+		c.Error(errors.NewValidationError("Invalid block number", nil))
+	})
+
+	send := func(id string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/fail", nil)
+		if id != "" {
+			req.Header.Set(RequestIDHeader, id)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// A client-supplied ID is echoed and tags the handler, error and access logs
+	w := send("client-42")
+	assert.Equal(t, "client-42", w.Header().Get(RequestIDHeader))
+	var body struct {
+		Error struct {
+			RequestID string `json:"requestId"`
+		} `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "client-42", body.Error.RequestID)
+
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 3)
+	for _, entry := range entries {
+		assert.Equal(t, "client-42", entry.ContextMap()["request_id"], entry.Message)
+	}
+
+	// The handler's logger is also tagged with the request's fields
+	handled := entries[0].ContextMap()
+	assert.Equal(t, "Handling request", entries[0].Message)
+	assert.Equal(t, "/fail", handled["endpoint"])
+	assert.Equal(t, http.MethodGet, handled["http_method"])
+	assert.Contains(t, handled, "client_ip")
+
+	// Missing or unusable IDs are replaced with a generated UUID
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for _, id := range []string{"", "forged\nline", strings.Repeat("x", maxRequestIDLength+1)} {
+		w = send(id)
+		generated := w.Header().Get(RequestIDHeader)
+		assert.Regexp(t, uuid, generated, id)
+		assert.Contains(t, w.Body.String(), generated)
+	}
+	assert.NotEqual(t, send("").Header().Get(RequestIDHeader), send("").Header().Get(RequestIDHeader))
+}
+
+func TestRateLimiterSharedRedisStore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	redisServer := miniredis.RunT(t)
+	store, err := NewRateLimiterStore("redis://" + redisServer.Addr())
+	assert.NoError(t, err)
+
+	// Two routers stand in for two replicas sharing the same Redis
+	config := DefaultRateLimiterConfig()
+	config.Limit = 3
+	config.Name = "api"
+	config.Store = store
+
+	replicas := make([]*gin.Engine, 2)
+	for i := range replicas {
+		replicas[i] = gin.New()
+		replicas[i].Use(RateLimiter(config))
+		replicas[i].GET("/ping", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+	}
+
+	var codes []int
+	for i := 0; i < 4; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		w := httptest.NewRecorder()
+		replicas[i%2].ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+
+	// The limit applies to the combined traffic, not to each replica
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+}
+
+func TestNewRateLimiterStoreFallsBackToMemory(t *testing.T) {
+	store, err := NewRateLimiterStore("")
+	assert.NoError(t, err)
+	assert.NotNil(t, store)
+
+	_, err = NewRateLimiterStore("not a url")
+	assert.Error(t, err)
+}
+
+func TestConfigureRateLimitersSharesOneBucket(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	ConfigureRateLimiters(router, nil, nil)
+	router.GET("/api/v1/block/:number", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/api/v1/blocks", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(path string) int {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Forwarded-For", "10.0.0.2")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	exceeded := metrics.RateLimitExceededTotal.WithLabelValues("default")
+	exceededBefore := testutil.ToFloat64(exceeded)
+
+	// Requests to different endpoints draw from the same bucket
+	limit := DefaultRateLimiterConfig().Limit
+	for i := 0; i < limit; i++ {
+		path := "/api/v1/block/0x10"
+		if i%2 == 1 {
+			path = "/api/v1/blocks"
+		}
+		assert.Equal(t, http.StatusOK, serve(path), i)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, serve("/api/v1/blocks"))
+	assert.Equal(t, exceededBefore+1, testutil.ToFloat64(exceeded))
+}
+
+func TestRateLimiterByAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	config := DefaultRateLimiterConfig()
+	config.Limit = 2
+	config.Name = "by-key"
+	config.KeyFunc = APIKeyOrIP([]string{"alice", "bob"})
+
+	router := gin.New()
+	router.Use(RateLimiter(config))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// All traffic arrives through the same proxy IP
+	serve := func(apiKey string) int {
+		req, _ := http.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set("X-Forwarded-For", "10.0.0.1")
+		if apiKey != "" {
+			req.Header.Set(APIKeyHeader, apiKey)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	exceeded := metrics.RateLimitExceededTotal.WithLabelValues(config.Name)
+	exceededBefore := testutil.ToFloat64(exceeded)
+
+	// Each API key gets its own bucket
+	assert.Equal(t, http.StatusOK, serve("alice"))
+	assert.Equal(t, http.StatusOK, serve("alice"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("alice"))
+	assert.Equal(t, exceededBefore+1, testutil.ToFloat64(exceeded))
+	assert.Equal(t, http.StatusOK, serve("bob"))
+
+	// Requests without a key fall back to the client IP bucket
+	assert.Equal(t, http.StatusOK, serve(""))
+	assert.Equal(t, http.StatusOK, serve(""))
+	assert.Equal(t, http.StatusTooManyRequests, serve(""))
+	assert.Equal(t, http.StatusOK, serve("bob"))
+
+	// Unknown keys do too, so rotating made-up keys does not escape the limit
+	assert.Equal(t, http.StatusTooManyRequests, serve("mallory"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("mallory-2"))
+}
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	release := make(chan struct{})
+	lateDone := make(chan struct{})
+	router := gin.New()
+	router.Use(ErrorHandlerWithConfig(ErrorHandlerConfig{
+		PublicMessages: map[string]string{errors.ErrTypeTimeout: "Too slow: {message}"},
+	}))
+	router.Use(Timeout(20 * time.Millisecond))
+	router.GET("/wedged", func(c *gin.Context) {
+		// Work bound to the request context observes the deadline
+		<-c.Request.Context().Done()
+	})
+	router.GET("/fast", func(c *gin.Context) {
+		c.Header("X-Fast", "yes")
+		c.JSON(http.StatusCreated, gin.H{"status": "ok"})
+	})
+	router.GET("/late", func(c *gin.Context) {
+		// This handler ignores its context entirely
+		defer close(lateDone)
+		<-release
+		_, err := c.Writer.WriteString(`{"status":"ok"}`)
+		assert.ErrorIs(t, err, http.ErrHandlerTimeout)
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/wedged")
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.JSONEq(t, `{"error":{"type":"timeout_error","code":"TIMEOUT_ERROR","message":"Too slow: Request timed out"}}`, w.Body.String())
+
+	w = serve("/fast")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "yes", w.Header().Get("X-Fast"))
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+
+	// The 504 reaches the client at the deadline even though the handler is
+	// still running, and what the handler writes afterwards is dropped
+	recorder := &flushRecorder{ResponseRecorder: httptest.NewRecorder(), flushed: make(chan struct{})}
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		req, _ := http.NewRequest(http.MethodGet, "/late", nil)
+		router.ServeHTTP(recorder, req)
+	}()
+	select {
+	case <-recorder.flushed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout response was not sent while the handler was running")
+	}
+	close(release)
+	<-lateDone
+	<-served
+	assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+	assert.JSONEq(t, `{"error":{"type":"timeout_error","code":"TIMEOUT_ERROR","message":"Too slow: Request timed out"}}`, recorder.Body.String())
+
+	// Panics surface in the request's goroutine so Recovery still sees them
+	assert.PanicsWithValue(t, "boom", func() { serve("/panic") })
+}
+
+// flushRecorder is a ResponseRecorder that signals its first flush
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushed chan struct{}
+	once    sync.Once
+}
+
+func (r *flushRecorder) Flush() {
+	r.ResponseRecorder.Flush()
+	r.once.Do(func() { close(r.flushed) })
+}
+
+func TestMaxBodySize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handled := 0
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.POST("/echo", MaxBodySize(32), func(c *gin.Context) {
+		handled++
+		var body map[string]string
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.Error(errors.NewValidationError("Request body must be a JSON object", err))
+			return
+		}
+		c.JSON(http.StatusOK, body)
+	})
+
+	serve := func(body string, chunked bool) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/echo", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if chunked {
+			// The size is only discovered while the body is read
+			req.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(`{"to":"0x1"}`, false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"to":"0x1"}`, w.Body.String())
+
+	tooLarge := `{"error":{"type":"validation_error","code":"BODY_TOO_LARGE","message":"Request body exceeds 32 bytes","data":{"limit":32}}}`
+	large := `{"data":"0x` + strings.Repeat("00", 32) + `"}`
+
+	// A declared length over the limit is rejected without running the handler
+	w = serve(large, false)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.JSONEq(t, tooLarge, w.Body.String())
+	assert.Equal(t, 1, handled)
+
+	// Otherwise binding fails at the limit and the binding error is replaced
+	w = serve(large, true)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.JSONEq(t, tooLarge, w.Body.String())
+	assert.Equal(t, 2, handled)
+
+	// Malformed bodies within the limit keep the handler's error
+	w = serve(`{"to":`, true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestLoggerVerboseRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Debug entries are dropped globally
+	core, logs := observer.New(zapcore.InfoLevel)
+	defer logger.Replace(zap.New(core))()
+
+	router := gin.New()
+	router.Use(LoggerWithConfig(LoggerConfig{VerboseRoutes: []string{"/call"}}))
+	router.POST("/call", func(c *gin.Context) {
+		var body map[string]interface{}
+		assert.NoError(t, c.ShouldBindJSON(&body))
+		assert.Equal(t, "hunter2", body["password"])
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.POST("/other", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	body := `{"to":"0xc2132d05d31c914a87c6611c10748aeb04b58e8f","password":"hunter2","auth":{"api_key":"k"}}`
+	for _, path := range []string{"/call?apiKey=secret&block=latest", "/other"} {
+		req, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// Only the configured route is logged in detail, with secrets redacted
+	requests := logs.FilterMessage("HTTP Request Detail").All()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, zapcore.DebugLevel, requests[0].Level)
+	assert.Equal(t, "/call", requests[0].ContextMap()["path"])
+	assert.JSONEq(t, `{
+		"to": "0xc2132d05d31c914a87c6611c10748aeb04b58e8f",
+		"password": "[REDACTED]",
+		"auth": {"api_key": "[REDACTED]"}
+	}`, requests[0].ContextMap()["body"].(string))
+	assert.Equal(t, "apiKey=%5BREDACTED%5D&block=latest", requests[0].ContextMap()["query"])
+
+	responses := logs.FilterMessage("HTTP Response Detail").All()
+	assert.Len(t, responses, 1)
+	assert.Equal(t, int64(http.StatusOK), responses[0].ContextMap()["status"])
+
+	// The regular access log still covers both routes
+	assert.Equal(t, 2, logs.FilterMessage("HTTP Request").Len())
+}
+
+func TestScrubBodyOmitsUnscrubbableBodies(t *testing.T) {
+	assert.Equal(t, "[non-JSON body omitted]", scrubBody([]byte("password=hunter2")))
+	assert.Equal(t, "[body larger than 4096 bytes omitted]", scrubBody([]byte(strings.Repeat("x", verboseBodyLimit+1))))
+	assert.Equal(t, "", scrubBody(nil))
+}
+
+func TestTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+	previousPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previousPropagator)
+
+	var handlerSpan trace.SpanContext
+	router := gin.New()
+	router.Use(RequestID(), Tracing(), ErrorHandler())
+	router.GET("/api/v1/block/:number", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.Error(errors.NewBlockchainError("node unavailable", nil))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/block/0x10", nil)
+	req.Header.Set(RequestIDHeader, "trace-1")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		span := spans[0]
+		assert.Equal(t, "GET /api/v1/block/:number", span.Name())
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+		assert.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID(), "handlers see the request span")
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.Parent().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", w.Code))
+		assert.Contains(t, span.Attributes(), attribute.String("request.id", "trace-1"))
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"blockchain-client/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader is the header carrying a request's correlation ID
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the gin context key under which the request ID is stored
+const RequestIDKey = "request_id"
+
+// maxRequestIDLength bounds client-supplied IDs, which end up in every log line
+const maxRequestIDLength = 128
+
+// RequestID returns a middleware that tags each request with an ID, taken from
+// the X-Request-ID header when the client sent a usable one and generated
+// otherwise. The ID is echoed in the response header, stored on the gin
+// context and the request's context, and included in request logs and error
+// bodies. It should run before the other middleware.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Set(RequestIDKey, id)
+		c.Request = c.Request.WithContext(logger.ContextWithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+
+		c.Next()
+	}
+}
+
+// validRequestID reports whether a client-supplied ID is short and printable
+// ASCII, so it cannot forge log lines or bloat them
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates a random (version 4) UUID
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		logger.Warn("Failed to generate request ID", zap.Error(err))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// requestIDFields returns the log field carrying the request's ID, if it has one
+func requestIDFields(c *gin.Context) []zap.Field {
+	if id := c.GetString(RequestIDKey); id != "" {
+		return []zap.Field{zap.String("request_id", id)}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"fmt"
+
+	libredis "github.com/redis/go-redis/v9"
+	"github.com/ulule/limiter/v3"
+	"github.com/ulule/limiter/v3/drivers/store/memory"
+	"github.com/ulule/limiter/v3/drivers/store/redis"
+)
+
+// rateLimiterKeyPrefix namespaces rate limiter keys in a shared store
+const rateLimiterKeyPrefix = "blockchain_client_ratelimit"
+
+// RateLimiterStore holds rate limiter counters. An in-memory store counts
+// requests per instance; a Redis store shares the counts across replicas.
+type RateLimiterStore = limiter.Store
+
+// NewMemoryRateLimiterStore returns a store that keeps counters in process memory
+func NewMemoryRateLimiterStore() RateLimiterStore {
+	return memory.NewStore()
+}
+
+// NewRedisRateLimiterStore returns a store that keeps counters in the Redis
+// server at redisURL (e.g. redis://localhost:6379/0) so that every replica
+// enforces the same limits. The server must be reachable at construction.
+func NewRedisRateLimiterStore(redisURL string) (RateLimiterStore, error) {
+	options, err := libredis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+
+	store, err := redis.NewStoreWithOptions(libredis.NewClient(options), limiter.StoreOptions{
+		Prefix: rateLimiterKeyPrefix,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Redis rate limiter store: %w", err)
+	}
+	return store, nil
+}
+
+// NewRateLimiterStore returns a Redis-backed store when redisURL is set and an
+// in-memory store otherwise
+func NewRateLimiterStore(redisURL string) (RateLimiterStore, error) {
+	if redisURL == "" {
+		return NewMemoryRateLimiterStore(), nil
+	}
+	return NewRedisRateLimiterStore(redisURL)
+}
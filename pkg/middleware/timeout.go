@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	stderrors "errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"go.uber.org/zap"
+)
+
+// Timeout returns a middleware that bounds request handling to d. The deadline
+// is set on c.Request's context so RPC calls made with it are cancelled when it
+// passes. Like http.TimeoutHandler, the handler runs in its own goroutine and
+// writes into a buffer; if it has not finished by the deadline the client is
+// answered 504 at once and anything the handler writes afterwards is dropped.
+// The middleware still waits for the handler to return before handing the
+// context back to gin, so handlers that ignore ctx keep their goroutine busy
+// but no longer hold up the response. A d of zero or less disables the timeout.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		// Everything the handler goroutine may also touch is read up front
+		value, _ := c.Get(errorHandlerConfigKey)
+		config, _ := value.(ErrorHandlerConfig)
+		requestID := c.GetString(RequestIDKey)
+		w := c.Writer
+		tw := newTimeoutWriter(w)
+		c.Writer = tw
+
+		finished := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				tw.finish()
+				finished <- recover()
+			}()
+			c.Next()
+		}()
+
+		var p interface{}
+		timedOut := false
+		select {
+		case p = <-finished:
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded && tw.timeout() {
+				timedOut = true
+				logger.Warn("Request timed out",
+					zap.String("path", c.Request.URL.Path),
+					zap.Duration("timeout", d))
+				status, body := errorResponse(config, &gin.Error{
+					Err:  errors.NewTimeoutError("Request timed out", ctx.Err()),
+					Type: gin.ErrorTypePrivate,
+				}, requestID)
+				w.WriteHeader(status)
+				if err := (render.JSON{Data: body}).Render(w); err != nil {
+					logger.Warn("Failed to write timeout response", zap.Error(err))
+				}
+				w.Flush()
+			}
+			// gin reuses c once the chain returns, so the handler must be done
+			p = <-finished
+		}
+
+		c.Writer = w
+		if p != nil {
+			panic(p)
+		}
+		if timedOut {
+			// ErrorHandler logs and counts it; the response is already written
+			c.Error(errors.NewTimeoutError("Request timed out", ctx.Err()))
+			return
+		}
+		tw.flushTo(w)
+	}
+}
+
+// timeoutWriter buffers a handler's response so Timeout can discard it in
+// favour of a 504 once the deadline passes
+type timeoutWriter struct {
+	gin.ResponseWriter
+
+	// header is only touched by the handler until it returns
+	header http.Header
+
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	status   int
+	size     int
+	done     bool
+	timedOut bool
+}
+
+// newTimeoutWriter returns a timeoutWriter starting from w's headers
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{
+		ResponseWriter: w,
+		header:         w.Header().Clone(),
+		status:         w.Status(),
+		size:           -1,
+	}
+}
+
+// timeout marks the response as timed out so later writes are dropped. It
+// reports false when the handler has finished in the meantime.
+func (w *timeoutWriter) timeout() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done {
+		return false
+	}
+	w.timedOut = true
+	return true
+}
+
+// finish records that the handler has returned
+func (w *timeoutWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.done = true
+}
+
+// flushTo copies the buffered response to dst once the handler has returned
+func (w *timeoutWriter) flushTo(dst gin.ResponseWriter) {
+	for key, values := range w.header {
+		dst.Header()[key] = values
+	}
+	dst.WriteHeader(w.status)
+	if w.size < 0 {
+		return
+	}
+	dst.WriteHeaderNow()
+	if w.buf.Len() > 0 {
+		if _, err := dst.Write(w.buf.Bytes()); err != nil {
+			logger.Warn("Failed to write response", zap.Error(err))
+		}
+	}
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if code > 0 && w.size < 0 {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size < 0 {
+		w.size = 0
+	}
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.size < 0 {
+		w.size = 0
+	}
+	n, err := w.buf.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.size
+}
+
+func (w *timeoutWriter) Written() bool {
+	return w.Size() >= 0
+}
+
+// Flush is a no-op; the response is only sent once the handler returns
+func (w *timeoutWriter) Flush() {}
+
+// Hijack is not supported since the response is buffered
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, stderrors.New("hijacking is not supported under a request timeout")
+}
+
+// Pusher is not supported since the response is buffered
+func (w *timeoutWriter) Pusher() http.Pusher {
+	return nil
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"blockchain-client/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// verboseBodyLimit is how much of a request body verbose logging captures
+const verboseBodyLimit = 4 << 10
+
+// redacted replaces the values of sensitive fields in logged bodies
+const redacted = "[REDACTED]"
+
+// sensitiveFields are body field names, compared case-insensitively with
+// dashes and underscores ignored, whose values are never logged
+var sensitiveFields = map[string]bool{
+	"password":      true,
+	"secret":        true,
+	"token":         true,
+	"accesstoken":   true,
+	"refreshtoken":  true,
+	"apikey":        true,
+	"authorization": true,
+	"privatekey":    true,
+	"mnemonic":      true,
+	"seed":          true,
+}
+
+// logVerboseRequest logs a request to a verbose route, including its body for
+// methods that carry one. The body is restored so handlers can still read it.
+func logVerboseRequest(c *gin.Context) {
+	fields := []zap.Field{
+		zap.String("path", c.Request.URL.Path),
+		zap.String("method", c.Request.Method),
+		zap.String("query", scrubQuery(c.Request.URL.Query())),
+	}
+
+	switch c.Request.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		if c.Request.Body != nil {
+			captured, err := io.ReadAll(io.LimitReader(c.Request.Body, verboseBodyLimit+1))
+			if err != nil {
+				fields = append(fields, zap.NamedError("body_error", err))
+			}
+			// Hand the handler the captured prefix followed by whatever was not read
+			c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(captured), c.Request.Body), c.Request.Body}
+			fields = append(fields, zap.String("body", scrubBody(captured)))
+		}
+	}
+
+	logger.Verbose("HTTP Request Detail", fields...)
+}
+
+// logVerboseResponse logs a summary of the response to a verbose route
+func logVerboseResponse(c *gin.Context, start time.Time) {
+	logger.Verbose("HTTP Response Detail",
+		zap.String("path", c.Request.URL.Path),
+		zap.String("method", c.Request.Method),
+		zap.Int("status", c.Writer.Status()),
+		zap.Int("bytes", c.Writer.Size()),
+		zap.String("content_type", c.Writer.Header().Get("Content-Type")),
+		zap.Strings("errors", c.Errors.Errors()),
+		zap.Duration("latency", time.Since(start)))
+}
+
+// scrubBody renders a captured request body for logging with sensitive fields
+// redacted. Bodies that are not JSON, or were truncated, cannot be scrubbed
+// reliably and are summarized by size instead.
+func scrubBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if len(body) > verboseBodyLimit {
+		return fmt.Sprintf("[body larger than %d bytes omitted]", verboseBodyLimit)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return "[non-JSON body omitted]"
+	}
+	scrubbed, err := json.Marshal(scrubValue(value))
+	if err != nil {
+		return "[unencodable body omitted]"
+	}
+	return string(scrubbed)
+}
+
+// scrubQuery renders query parameters for logging with sensitive values redacted
+func scrubQuery(query url.Values) string {
+	for key := range query {
+		if isSensitiveField(key) {
+			query[key] = []string{redacted}
+		}
+	}
+	return query.Encode()
+}
+
+// scrubValue redacts sensitive fields in a decoded JSON value, recursively
+func scrubValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSensitiveField(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = scrubValue(field)
+		}
+	case []interface{}:
+		for i, element := range v {
+			v[i] = scrubValue(element)
+		}
+	}
+	return value
+}
+
+// isSensitiveField reports whether a body field name holds a secret
+func isSensitiveField(name string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+	return sensitiveFields[normalized]
+}
+
+// readCloser pairs a replacement body reader with the original body's Close
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,50 @@
+// Package rlp implements the encoding half of Ethereum's Recursive Length
+// Prefix serialization, enough to re-encode transactions and trie nodes.
+package rlp
+
+import "math/big"
+
+// EncodeBytes encodes a byte string
+func EncodeBytes(b []byte) []byte {
+	// A single byte below 0x80 is its own encoding
+	if len(b) == 1 && b[0] < 0x80 {
+		return []byte{b[0]}
+	}
+	return append(header(0x80, len(b)), b...)
+}
+
+// EncodeBig encodes a non-negative integer as its minimal big-endian bytes,
+// so zero encodes as the empty string
+func EncodeBig(i *big.Int) []byte {
+	return EncodeBytes(i.Bytes())
+}
+
+// EncodeUint encodes a non-negative integer
+func EncodeUint(i uint64) []byte {
+	return EncodeBig(new(big.Int).SetUint64(i))
+}
+
+// EncodeList encodes a list whose items are already RLP encoded
+func EncodeList(items ...[]byte) []byte {
+	size := 0
+	for _, item := range items {
+		size += len(item)
+	}
+
+	encoded := header(0xc0, size)
+	for _, item := range items {
+		encoded = append(encoded, item...)
+	}
+	return encoded
+}
+
+// header returns the prefix of a string (offset 0x80) or list (offset 0xc0)
+// whose payload is size bytes long
+func header(offset byte, size int) []byte {
+	if size < 56 {
+		return []byte{offset + byte(size)}
+	}
+
+	length := big.NewInt(int64(size)).Bytes()
+	return append([]byte{offset + 55 + byte(len(length))}, length...)
+}
@@ -0,0 +1,34 @@
+package rlp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	// Examples from the RLP specification
+	assert.Equal(t, "83646f67", hex.EncodeToString(EncodeBytes([]byte("dog"))))
+	assert.Equal(t, "c88363617483646f67", hex.EncodeToString(EncodeList(EncodeBytes([]byte("cat")), EncodeBytes([]byte("dog")))))
+	assert.Equal(t, "80", hex.EncodeToString(EncodeBytes(nil)))
+	assert.Equal(t, "c0", hex.EncodeToString(EncodeList()))
+	assert.Equal(t, "80", hex.EncodeToString(EncodeUint(0)))
+	assert.Equal(t, "0f", hex.EncodeToString(EncodeUint(15)))
+	assert.Equal(t, "820400", hex.EncodeToString(EncodeUint(1024)))
+	assert.Equal(t, "00", hex.EncodeToString(EncodeBytes([]byte{0})))
+	assert.Equal(t, "c7c0c1c0c3c0c1c0", hex.EncodeToString(
+		EncodeList(EncodeList(), EncodeList(EncodeList()), EncodeList(EncodeList(), EncodeList(EncodeList())))))
+
+	// Strings of 56 bytes and more use a length-of-length prefix
+	lorem := []byte("Lorem ipsum dolor sit amet, consectetur adipisicing elit")
+	assert.Equal(t, append([]byte{0xb8, 0x38}, lorem...), EncodeBytes(lorem))
+
+	long := bytes.Repeat([]byte{0xaa}, 1024)
+	assert.Equal(t, []byte{0xb9, 0x04, 0x00}, EncodeBytes(long)[:3])
+
+	huge, _ := new(big.Int).SetString("100102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f", 16)
+	assert.Equal(t, "a0100102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f", hex.EncodeToString(EncodeBig(huge)))
+}
@@ -0,0 +1,143 @@
+// Package trie computes Merkle-Patricia trie roots, as committed to by block
+// headers, without keeping a trie in memory.
+package trie
+
+import (
+	"bytes"
+	"sort"
+
+	"blockchain-client/pkg/rlp"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// EmptyRoot is the root hash of a trie without entries
+var EmptyRoot = Keccak256(rlp.EncodeBytes(nil))
+
+// Keccak256 returns the Keccak-256 hash Ethereum uses throughout
+func Keccak256(data ...[]byte) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	for _, b := range data {
+		hasher.Write(b)
+	}
+	return hasher.Sum(nil)
+}
+
+// DeriveRoot returns the root of the trie mapping each value's RLP-encoded
+// index to the value, the layout of the transactions and receipts tries
+func DeriveRoot(values [][]byte) []byte {
+	entries := make(map[string][]byte, len(values))
+	for i, value := range values {
+		entries[string(rlp.EncodeUint(uint64(i)))] = value
+	}
+	return Root(entries)
+}
+
+// Root returns the root hash of the trie holding entries
+func Root(entries map[string][]byte) []byte {
+	pairs := make([]pair, 0, len(entries))
+	for key, value := range entries {
+		pairs = append(pairs, pair{key: nibbles([]byte(key)), value: value})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return bytes.Compare(pairs[i].key, pairs[j].key) < 0 })
+
+	return Keccak256(encodeNode(pairs))
+}
+
+// pair is a trie entry with its key split into nibbles
+type pair struct {
+	key   []byte
+	value []byte
+}
+
+// encodeNode returns the RLP encoding of the node holding pairs, whose keys
+// are sorted and relative to the node's position in the trie
+func encodeNode(pairs []pair) []byte {
+	switch len(pairs) {
+	case 0:
+		return rlp.EncodeBytes(nil)
+	case 1:
+		return rlp.EncodeList(rlp.EncodeBytes(compactKey(pairs[0].key, true)), rlp.EncodeBytes(pairs[0].value))
+	}
+
+	// Keys sharing a prefix hang below an extension node
+	if prefix := commonPrefix(pairs); prefix > 0 {
+		rest := make([]pair, len(pairs))
+		for i, p := range pairs {
+			rest[i] = pair{key: p.key[prefix:], value: p.value}
+		}
+		return rlp.EncodeList(rlp.EncodeBytes(compactKey(pairs[0].key[:prefix], false)), reference(encodeNode(rest)))
+	}
+
+	// Otherwise branch on the first nibble, with a key ending here stored in
+	// the seventeenth slot. Sorting puts such a key first.
+	slots := make([][]byte, 17)
+	for i := range slots {
+		slots[i] = rlp.EncodeBytes(nil)
+	}
+	if len(pairs[0].key) == 0 {
+		slots[16] = rlp.EncodeBytes(pairs[0].value)
+		pairs = pairs[1:]
+	}
+	for start := 0; start < len(pairs); {
+		nibble := pairs[start].key[0]
+		end := start
+		var children []pair
+		for ; end < len(pairs) && pairs[end].key[0] == nibble; end++ {
+			children = append(children, pair{key: pairs[end].key[1:], value: pairs[end].value})
+		}
+		slots[nibble] = reference(encodeNode(children))
+		start = end
+	}
+	return rlp.EncodeList(slots...)
+}
+
+// reference returns how a parent refers to an encoded child: nodes shorter than
+// a hash are embedded, larger ones are referenced by their hash
+func reference(node []byte) []byte {
+	if len(node) < 32 {
+		return node
+	}
+	return rlp.EncodeBytes(Keccak256(node))
+}
+
+// commonPrefix returns how many leading nibbles all keys of sorted pairs share
+func commonPrefix(pairs []pair) int {
+	first, last := pairs[0].key, pairs[len(pairs)-1].key
+	n := 0
+	for n < len(first) && n < len(last) && first[n] == last[n] {
+		n++
+	}
+	return n
+}
+
+// nibbles splits key into 4-bit nibbles, high nibble first
+func nibbles(key []byte) []byte {
+	out := make([]byte, 0, len(key)*2)
+	for _, b := range key {
+		out = append(out, b>>4, b&0x0f)
+	}
+	return out
+}
+
+// compactKey packs nibbles into the hex-prefix encoding, whose first nibble
+// flags whether the node is a leaf and whether the path has odd length
+func compactKey(path []byte, leaf bool) []byte {
+	flag := byte(0)
+	if leaf {
+		flag = 2
+	}
+
+	if len(path)%2 == 1 {
+		flag++
+	} else {
+		path = append([]byte{0}, path...)
+	}
+
+	out := make([]byte, (len(path)+1)/2)
+	out[0] = flag<<4 | path[0]
+	for i := 1; i < len(path); i += 2 {
+		out[(i+1)/2] = path[i]<<4 | path[i+1]
+	}
+	return out
+}
@@ -0,0 +1,37 @@
+package trie
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoot(t *testing.T) {
+	assert.Equal(t, "56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421", hex.EncodeToString(EmptyRoot))
+	assert.Equal(t, EmptyRoot, Root(nil))
+	assert.Equal(t, EmptyRoot, DeriveRoot(nil))
+
+	// Reference vectors from the go-ethereum and ethereum/tests trie suites
+	root := Root(map[string][]byte{
+		"doe":          []byte("reindeer"),
+		"dog":          []byte("puppy"),
+		"dogglesworth": []byte("cat"),
+	})
+	assert.Equal(t, "8aad789dff2f538bca5d8ea56e8abe10f4c7ba3a5dea95fea4cd6e7c3a1168d3", hex.EncodeToString(root))
+
+	root = Root(map[string][]byte{
+		"do":    []byte("verb"),
+		"dog":   []byte("puppy"),
+		"doge":  []byte("coin"),
+		"horse": []byte("stallion"),
+	})
+	assert.Equal(t, "5991bb8c6514148a29db676a14ac506cd2cd5775ace63c30a4fe457715e9ac84", hex.EncodeToString(root))
+}
+
+func TestCompactKey(t *testing.T) {
+	assert.Equal(t, []byte{0x11, 0x23, 0x45}, compactKey([]byte{1, 2, 3, 4, 5}, false))
+	assert.Equal(t, []byte{0x00, 0x01, 0x23, 0x45}, compactKey([]byte{0, 1, 2, 3, 4, 5}, false))
+	assert.Equal(t, []byte{0x20, 0x0f, 0x1c, 0xb8}, compactKey([]byte{0, 15, 1, 12, 11, 8}, true))
+	assert.Equal(t, []byte{0x3f, 0x1c, 0xb8}, compactKey([]byte{15, 1, 12, 11, 8}, true))
+}
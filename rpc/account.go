@@ -0,0 +1,200 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// addressPattern matches a 0x-prefixed 20-byte hex address
+	addressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	// hexQuantityPattern matches a 0x-prefixed hex quantity such as a block number
+	hexQuantityPattern = regexp.MustCompile(`^0x[0-9a-fA-F]+$`)
+	// hashPattern matches a 0x-prefixed 32-byte hex hash
+	hashPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+)
+
+// validateAddress checks that an address is a 0x-prefixed 20-byte hex string
+func validateAddress(address string) error {
+	if !addressPattern.MatchString(address) {
+		return errors.NewValidationError("Address must be a 0x-prefixed 20-byte hex string", nil).
+			WithCode(errors.CodeInvalidAddress).
+			WithData(map[string]interface{}{"address": address})
+	}
+	return nil
+}
+
+// validateHash checks that a block or transaction hash is a 0x-prefixed
+// 32-byte hex string
+func validateHash(hash string) error {
+	if !hashPattern.MatchString(hash) {
+		return errors.NewValidationError("Hash must be a 0x-prefixed 32-byte hex string", nil).
+			WithCode(errors.CodeInvalidHash).
+			WithData(map[string]interface{}{"hash": hash})
+	}
+	return nil
+}
+
+// normalizeBlockTag defaults an empty block tag to "latest" and checks that it
+// is either a named tag or a 0x-prefixed hex block number
+func normalizeBlockTag(blockTag string) (string, error) {
+	switch blockTag {
+	case "":
+		return "latest", nil
+	case "latest", "earliest", "pending":
+		return blockTag, nil
+	}
+
+	if !hexQuantityPattern.MatchString(blockTag) {
+		return "", errors.NewValidationError(
+			"Block must be latest, earliest, pending or a 0x-prefixed hex block number", nil).
+			WithData(map[string]interface{}{"block": blockTag})
+	}
+	return blockTag, nil
+}
+
+// GetBalance retrieves the wei balance of an address at the given block tag,
+// returned as a hex quantity. An empty block tag means "latest".
+func (c *EnhancedClient) GetBalance(ctx context.Context, address, blockTag string) (string, error) {
+	if err := validateAddress(address); err != nil {
+		return "", err
+	}
+
+	blockTag, err := normalizeBlockTag(blockTag)
+	if err != nil {
+		return "", err
+	}
+
+	// Create JSON-RPC request
+	requestBody := models.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_getBalance",
+		Params:  []interface{}{address, blockTag},
+		ID:      1,
+	}
+
+	var response models.StringResponse
+	err = c.doRequestCtx(ctx, requestBody, &response)
+	if err != nil {
+		logger.Error("Failed to get balance",
+			zap.String("address", address),
+			zap.String("block", blockTag),
+			zap.Error(err))
+		return "", errors.NewBlockchainError(fmt.Sprintf("Failed to get balance for address %s", address), err)
+	}
+
+	return response.Result, nil
+}
+
+// BatchGetBalances retrieves the balances of several addresses at the given
+// block tag in a single batch request. The returned balances are aligned with
+// the addresses. Malformed addresses are not sent; like lookups the node fails,
+// they leave an empty balance and an entry in the returned *BatchError. An
+// empty block tag means "latest".
+func (c *EnhancedClient) BatchGetBalances(ctx context.Context, addresses []string, blockTag string) ([]string, error) {
+	blockTag, err := normalizeBlockTag(blockTag)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]string, len(addresses))
+	errs := make([]error, len(addresses))
+	failed := false
+
+	// Only well-formed addresses are sent, remembering where each belongs
+	requests := make([]models.RPCRequest, 0, len(addresses))
+	positions := make([]int, 0, len(addresses))
+	for i, address := range addresses {
+		if err := validateAddress(address); err != nil {
+			errs[i] = err
+			failed = true
+			continue
+		}
+		requests = append(requests, models.RPCRequest{
+			JSONRPC: "2.0",
+			Method:  "eth_getBalance",
+			Params:  []interface{}{address, blockTag},
+			ID:      len(requests) + 1,
+		})
+		positions = append(positions, i)
+	}
+
+	results, err := c.BatchCall(ctx, requests)
+	if err != nil {
+		failed = true
+		batchErr, partial := err.(*BatchError)
+		for j, i := range positions {
+			switch {
+			case !partial:
+				// The whole batch failed, so every address sent shares the error
+				errs[i] = errors.NewBlockchainError(fmt.Sprintf("Failed to get balance for address %s", addresses[i]), err)
+			case batchErr.Errors[j] != nil:
+				errs[i] = batchErr.Errors[j]
+			}
+		}
+		if !partial {
+			logger.Error("Failed to get balances",
+				zap.Int("addresses", len(requests)),
+				zap.String("block", blockTag),
+				zap.Error(err))
+			return balances, &BatchError{Errors: errs}
+		}
+	}
+
+	for j, i := range positions {
+		if errs[i] != nil {
+			continue
+		}
+		if err := json.Unmarshal(results[j], &balances[i]); err != nil {
+			errs[i] = errors.NewInternalError("Failed to unmarshal balance in batch response", err)
+			failed = true
+		}
+	}
+
+	if failed {
+		return balances, &BatchError{Errors: errs}
+	}
+	return balances, nil
+}
+
+// GetCode retrieves the bytecode deployed at an address at the given block
+// tag, returned as hex. Externally owned accounts have no code and return
+// "0x". An empty block tag means "latest".
+func (c *EnhancedClient) GetCode(ctx context.Context, address, blockTag string) (string, error) {
+	if err := validateAddress(address); err != nil {
+		return "", err
+	}
+
+	blockTag, err := normalizeBlockTag(blockTag)
+	if err != nil {
+		return "", err
+	}
+
+	// Create JSON-RPC request
+	requestBody := models.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_getCode",
+		Params:  []interface{}{address, blockTag},
+		ID:      1,
+	}
+
+	var response models.StringResponse
+	err = c.doRequestCtx(ctx, requestBody, &response)
+	if err != nil {
+		logger.Error("Failed to get code",
+			zap.String("address", address),
+			zap.String("block", blockTag),
+			zap.Error(err))
+		return "", errors.NewBlockchainError(fmt.Sprintf("Failed to get code for address %s", address), err)
+	}
+
+	return response.Result, nil
+}
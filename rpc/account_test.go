@@ -0,0 +1,132 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBalance(t *testing.T) {
+	var params []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "eth_getBalance", request.Method)
+		params = request.Params
+
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0xde0b6b3a7640000"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+	address := "0xc2132d05d31c914a87c6611c10748aeb04b58e8f"
+
+	// An empty block tag defaults to latest
+	balance, err := client.GetBalance(context.Background(), address, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "0xde0b6b3a7640000", balance)
+	assert.Equal(t, []interface{}{address, "latest"}, params)
+
+	_, err = client.GetBalance(context.Background(), address, "0x10")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{address, "0x10"}, params)
+}
+
+func TestGetBalanceValidation(t *testing.T) {
+	client := NewEnhancedClient("http://localhost", 10*time.Second)
+
+	tests := []struct {
+		address  string
+		blockTag string
+	}{
+		{"0x1234", "latest"},
+		{"c2132d05d31c914a87c6611c10748aeb04b58e8f", "latest"},
+		{"0xc2132d05d31c914a87c6611c10748aeb04b58e8f", "finalized-ish"},
+		{"0xc2132d05d31c914a87c6611c10748aeb04b58e8f", "0xzz"},
+		{"0xc2132d05d31c914a87c6611c10748aeb04b58e8f", "100"},
+	}
+
+	for _, tt := range tests {
+		_, err := client.GetBalance(context.Background(), tt.address, tt.blockTag)
+		assert.True(t, errors.IsType(err, errors.ErrTypeValidation), tt)
+	}
+}
+
+func TestGetCode(t *testing.T) {
+	var params []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "eth_getCode", request.Method)
+		params = request.Params
+
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x6080604052"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+	address := "0xc2132d05d31c914a87c6611c10748aeb04b58e8f"
+
+	code, err := client.GetCode(context.Background(), address, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x6080604052", code)
+	assert.Equal(t, []interface{}{address, "latest"}, params)
+
+	// Invalid input is rejected before any RPC call is made
+	params = nil
+	_, err = client.GetCode(context.Background(), "0x1234", "latest")
+	assert.True(t, errors.IsType(err, errors.ErrTypeValidation), err)
+	_, err = client.GetCode(context.Background(), address, "100")
+	assert.True(t, errors.IsType(err, errors.ErrTypeValidation), err)
+	assert.Nil(t, params)
+}
+
+func TestBatchGetBalances(t *testing.T) {
+	var requests []models.RPCRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&requests))
+
+		_, err := w.Write([]byte(`[
+			{"jsonrpc":"2.0","id":2,"error":{"code":-32000,"message":"header not found"}},
+			{"jsonrpc":"2.0","id":1,"result":"0xde0b6b3a7640000"}
+		]`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+	addresses := []string{
+		"0xc2132d05d31c914a87c6611c10748aeb04b58e8f",
+		"0x1234",
+		"0xa7d9ddbe1f17865597fbd27ec712455208b6b76d",
+	}
+
+	balances, err := client.BatchGetBalances(context.Background(), addresses, "0x10")
+	assert.Equal(t, []string{"0xde0b6b3a7640000", "", ""}, balances)
+
+	// The malformed address is not sent
+	assert.Len(t, requests, 2)
+	assert.Equal(t, []interface{}{addresses[0], "0x10"}, requests[0].Params)
+	assert.Equal(t, []interface{}{addresses[2], "0x10"}, requests[1].Params)
+
+	batchErr, ok := err.(*BatchError)
+	assert.True(t, ok, err)
+	assert.NoError(t, batchErr.Errors[0])
+	assert.True(t, errors.IsType(batchErr.Errors[1], errors.ErrTypeValidation), batchErr.Errors[1])
+	assert.Error(t, batchErr.Errors[2])
+	assert.False(t, errors.IsType(batchErr.Errors[2], errors.ErrTypeValidation))
+
+	// An invalid block tag fails every address
+	_, err = client.BatchGetBalances(context.Background(), addresses, "100")
+	assert.True(t, errors.IsType(err, errors.ErrTypeValidation), err)
+}
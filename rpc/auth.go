@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"encoding/base64"
+	"net/http"
+)
+
+// WithBearerToken authenticates requests to the primary endpoint, and the
+// WebSocket handshake, with an "Authorization: Bearer" header. Fallback
+// endpoints never receive it. It replaces any credentials set by
+// WithBasicAuth or an Authorization header given to WithHeaders.
+func WithBearerToken(token string) Option {
+	return func(c *EnhancedClient) {
+		c.authorization = "Bearer " + token
+	}
+}
+
+// WithBasicAuth authenticates requests to the primary endpoint, and the
+// WebSocket handshake, with HTTP basic authentication. Fallback endpoints
+// never receive it. It replaces any credentials set by WithBearerToken or an
+// Authorization header given to WithHeaders.
+func WithBasicAuth(username, password string) Option {
+	return func(c *EnhancedClient) {
+		c.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	}
+}
+
+// WithAPIKeyPath appends key to the primary endpoint URL's path, after any
+// path set by WithRPCPath, for providers that take the key as the last path
+// segment. The key is redacted wherever URLs are logged and is never sent to
+// fallback endpoints.
+func WithAPIKeyPath(key string) Option {
+	return func(c *EnhancedClient) {
+		c.apiKeyPath = key
+	}
+}
+
+// applyAuth sets the configured credentials on header
+func (c *EnhancedClient) applyAuth(header http.Header) {
+	if c.authorization != "" {
+		header.Set("Authorization", c.authorization)
+	}
+}
+
+// primaryHeader returns the headers sent to the primary endpoint only
+func (c *EnhancedClient) primaryHeader() http.Header {
+	header := c.headers.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	c.applyAuth(header)
+	return header
+}
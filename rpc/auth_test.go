@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCredentials(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{"bearer token", []Option{WithBearerToken("t0ken")}, "Bearer t0ken"},
+		{"basic auth", []Option{WithBasicAuth("alice", "s3cret")}, "Basic YWxpY2U6czNjcmV0"},
+		{"overrides headers", []Option{WithHeaders(map[string]string{"Authorization": "Bearer stale"}), WithBearerToken("t0ken")}, "Bearer t0ken"},
+		{"none", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewEnhancedClient(server.URL, 10*time.Second, tt.opts...)
+			_, err := client.GetLatestBlockNumber()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, authorization)
+		})
+	}
+}
+
+func TestClientURLCredentialsAreRedacted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/k3y", r.URL.Path)
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "alice", username)
+		assert.Equal(t, "s3cret", password)
+
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	// The key goes after the RPC path and the userinfo is sent as basic auth
+	rpcURL := strings.Replace(server.URL, "http://", "http://alice:s3cret@", 1)
+	client := NewEnhancedClient(rpcURL, 10*time.Second, WithRPCPath("v3"), WithAPIKeyPath("k3y"))
+
+	_, err := client.GetLatestBlockNumber()
+	require.NoError(t, err)
+
+	statuses := client.EndpointStatuses()
+	expected := strings.Replace(server.URL, "http://", "http://alice:REDACTED@", 1) + "/v3/REDACTED"
+	assert.Equal(t, expected, statuses[0].URL)
+}
+
+func TestCredentialsOnlySentToPrimaryEndpoint(t *testing.T) {
+	var primaryAuth string
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		assert.NoError(t, err)
+	}))
+	defer backup.Close()
+
+	client := NewEnhancedClient(primary.URL, 10*time.Second,
+		WithBearerToken("t0ken"), WithEndpoints(backup.URL), WithRetry(RetryConfig{}))
+
+	_, err := client.GetLatestBlockNumber()
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer t0ken", primaryAuth)
+}
@@ -0,0 +1,225 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// AutoBatchConfig controls coalescing of concurrent GetBlockByNumber calls into
+// a single batch request
+type AutoBatchConfig struct {
+	// MaxWait is how long the first call in a batch waits for others to join
+	MaxWait time.Duration
+	// MaxBatchSize flushes a batch as soon as it holds this many calls
+	MaxBatchSize int
+	// FlushTimeout bounds each batch request, which does not stop when its
+	// callers give up; zero leaves it unbounded
+	FlushTimeout time.Duration
+}
+
+// DefaultAutoBatchConfig returns a default auto-batching configuration
+func DefaultAutoBatchConfig() AutoBatchConfig {
+	return AutoBatchConfig{
+		MaxWait:      5 * time.Millisecond,
+		MaxBatchSize: 20,
+		FlushTimeout: 30 * time.Second,
+	}
+}
+
+// WithAutoBatch enables coalescing of GetBlockByNumber calls that arrive within
+// MaxWait of each other into one batch request to the provider
+func WithAutoBatch(cfg AutoBatchConfig) Option {
+	return func(c *EnhancedClient) {
+		if cfg.MaxBatchSize < 1 {
+			cfg.MaxBatchSize = 1
+		}
+		c.batcher = &blockBatcher{client: c, config: cfg}
+	}
+}
+
+// blockQuery identifies a block lookup within a batch
+type blockQuery struct {
+	number              string
+	includeTransactions bool
+}
+
+// blockResult is the outcome of a single batched block lookup
+type blockResult struct {
+	block *models.Block
+	err   error
+}
+
+// blockCall is a caller waiting for its block in the next batch
+type blockCall struct {
+	// ctx is the caller's context, whose values the batch carries
+	ctx   context.Context
+	query blockQuery
+	done  chan blockResult
+}
+
+// blockBatcher buffers block lookups and flushes them as batch requests
+type blockBatcher struct {
+	client *EnhancedClient
+	config AutoBatchConfig
+
+	mu      sync.Mutex
+	pending []*blockCall
+	timer   *time.Timer
+}
+
+// getBlock queues a block lookup and waits for the batch containing it to
+// complete. The batch is shared with other callers, so ctx only stops this
+// caller from waiting; the batch itself still runs to completion.
+func (b *blockBatcher) getBlock(ctx context.Context, query blockQuery) (*models.Block, error) {
+	call := &blockCall{ctx: ctx, query: query, done: make(chan blockResult, 1)}
+
+	b.mu.Lock()
+	b.pending = append(b.pending, call)
+	if len(b.pending) >= b.config.MaxBatchSize {
+		// A full batch is flushed right away by the caller that filled it
+		batch := b.take()
+		b.mu.Unlock()
+		b.flush(batch)
+	} else {
+		if b.timer == nil {
+			b.timer = time.AfterFunc(b.config.MaxWait, b.flushPending)
+		}
+		b.mu.Unlock()
+	}
+
+	// done is buffered, so the batch can still deliver after the caller gives up
+	select {
+	case result := <-call.done:
+		return result.block, result.err
+	case <-ctx.Done():
+		return nil, errors.NewTimeoutError("Block request cancelled", ctx.Err())
+	}
+}
+
+// take removes and returns the pending calls. The caller must hold b.mu.
+func (b *blockBatcher) take() []*blockCall {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	batch := b.pending
+	b.pending = nil
+	return batch
+}
+
+// flushPending flushes whatever is pending once MaxWait has elapsed
+func (b *blockBatcher) flushPending() {
+	b.mu.Lock()
+	batch := b.take()
+	b.mu.Unlock()
+
+	if len(batch) > 0 {
+		b.flush(batch)
+	}
+}
+
+// flush sends a batch and routes each result back to its caller
+func (b *blockBatcher) flush(batch []*blockCall) {
+	queries := make([]blockQuery, len(batch))
+	for i, call := range batch {
+		queries[i] = call.query
+	}
+
+	logger.Debug("Flushing auto-batched block requests", zap.Int("size", len(batch)))
+
+	// The batch carries the first caller's values, such as its request ID,
+	// call source and trace span, so upstream calls stay attributed. It serves
+	// every caller, so it runs to completion rather than stopping with the
+	// first caller, bounded by FlushTimeout instead.
+	ctx := context.WithoutCancel(batch[0].ctx)
+	if b.config.FlushTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.config.FlushTimeout)
+		defer cancel()
+	}
+
+	blocks, errs := b.client.getBlocks(ctx, queries)
+	for i, call := range batch {
+		call.done <- blockResult{block: blocks[i], err: errs[i]}
+	}
+}
+
+// BatchGetBlocksByNumber retrieves several blocks in a single batch request.
+// The returned blocks are aligned with the requested numbers; when only some
+// lookups fail, the successful blocks are returned with a *BatchError.
+func (c *EnhancedClient) BatchGetBlocksByNumber(ctx context.Context, blockNumbers []string, includeTransactions bool) ([]*models.Block, error) {
+	queries := make([]blockQuery, len(blockNumbers))
+	for i, number := range blockNumbers {
+		queries[i] = blockQuery{number: number, includeTransactions: includeTransactions}
+	}
+
+	blocks, errs := c.getBlocks(ctx, queries)
+	for _, err := range errs {
+		if err != nil {
+			return blocks, &BatchError{Errors: errs}
+		}
+	}
+	return blocks, nil
+}
+
+// getBlocks fetches blocks with a single batch call, returning a block or an
+// error for every query
+func (c *EnhancedClient) getBlocks(ctx context.Context, queries []blockQuery) ([]*models.Block, []error) {
+	blocks := make([]*models.Block, len(queries))
+	errs := make([]error, len(queries))
+
+	requests := make([]models.RPCRequest, len(queries))
+	for i, query := range queries {
+		requests[i] = models.RPCRequest{
+			JSONRPC: "2.0",
+			Method:  "eth_getBlockByNumber",
+			Params:  []interface{}{query.number, query.includeTransactions},
+			ID:      i + 1,
+		}
+	}
+
+	results, err := c.BatchCall(ctx, requests)
+	if err != nil {
+		batchErr, partial := err.(*BatchError)
+		if !partial {
+			// The whole batch failed, so every query shares the error
+			for i := range errs {
+				errs[i] = err
+			}
+			return blocks, errs
+		}
+		copy(errs, batchErr.Errors)
+	}
+
+	for i, result := range results {
+		if errs[i] != nil {
+			continue
+		}
+
+		var block *models.Block
+		if err := json.Unmarshal(result, &block); err != nil {
+			errs[i] = errors.NewInternalError("Failed to unmarshal block in batch response", err)
+			continue
+		}
+		if block == nil {
+			errs[i] = errors.NewNotFoundError("Block not found", nil).WithCode(errors.CodeBlockNotFound).
+				WithData(map[string]interface{}{"block_number": queries[i].number})
+			continue
+		}
+		if err := c.checkTransactionDecoding(block); err != nil {
+			errs[i] = err
+			continue
+		}
+		blocks[i] = block
+	}
+
+	return blocks, errs
+}
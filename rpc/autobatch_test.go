@@ -0,0 +1,215 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newBatchNode returns a mock node answering batches of eth_getBlockByNumber,
+// reporting block 0x0 as missing, and counting HTTP requests and batch sizes
+func newBatchNode(t *testing.T, requests *int32, sizes *[]int, mu *sync.Mutex) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+
+		var batch []models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+
+		mu.Lock()
+		*sizes = append(*sizes, len(batch))
+		mu.Unlock()
+
+		responses := make([]string, len(batch))
+		for i, request := range batch {
+			number := request.Params[0].(string)
+			result := fmt.Sprintf(`{"number":%q,"hash":"0xhash"}`, number)
+			if number == "0x0" {
+				result = "null"
+			}
+			responses[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, request.ID, result)
+		}
+
+		_, err := fmt.Fprintf(w, "[%s]", strings.Join(responses, ","))
+		assert.NoError(t, err)
+	}))
+}
+
+func TestAutoBatchCoalescesConcurrentCalls(t *testing.T) {
+	var requests int32
+	var sizes []int
+	var mu sync.Mutex
+	server := newBatchNode(t, &requests, &sizes, &mu)
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second,
+		WithAutoBatch(AutoBatchConfig{MaxWait: 50 * time.Millisecond, MaxBatchSize: 100}))
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 10; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			number := fmt.Sprintf("0x%x", n)
+			block, err := client.GetBlockByNumber(number)
+			assert.NoError(t, err)
+			assert.Equal(t, number, block.Number)
+		}(i)
+	}
+	wg.Wait()
+
+	// All ten lookups travelled in a single batch request
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Equal(t, []int{10}, sizes)
+}
+
+func TestAutoBatchFlushesAtMaxBatchSize(t *testing.T) {
+	var requests int32
+	var sizes []int
+	var mu sync.Mutex
+	server := newBatchNode(t, &requests, &sizes, &mu)
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second,
+		WithAutoBatch(AutoBatchConfig{MaxWait: time.Second, MaxBatchSize: 3}))
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 1; i <= 3; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			_, err := client.GetBlockByNumber(fmt.Sprintf("0x%x", n))
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	// A full batch does not wait for MaxWait to elapse
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestAutoBatchCarriesCallerContext(t *testing.T) {
+	requestIDs := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestIDs <- r.Header.Get(DefaultRequestIDHeader)
+		_, err := w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":{"number":"0x1"}}]`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithAutoBatch(DefaultAutoBatchConfig()))
+
+	// The batch carries the caller's request ID to the node
+	ctx, cancel := context.WithCancel(logger.ContextWithRequestID(context.Background(), "req-1"))
+	block, err := client.GetBlockByNumberCtx(ctx, "0x1")
+	cancel()
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", block.Number)
+	assert.Equal(t, "req-1", <-requestIDs)
+}
+
+func TestBatchGetBlocksByNumber(t *testing.T) {
+	var requests int32
+	var sizes []int
+	var mu sync.Mutex
+	server := newBatchNode(t, &requests, &sizes, &mu)
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	blocks, err := client.BatchGetBlocksByNumber(context.Background(), []string{"0x1", "0x0", "0x2"}, false)
+	assert.Equal(t, "0x1", blocks[0].Number)
+	assert.Nil(t, blocks[1])
+	assert.Equal(t, "0x2", blocks[2].Number)
+
+	// The missing block is reported for its own entry only
+	batchErr, ok := err.(*BatchError)
+	assert.True(t, ok)
+	assert.True(t, errors.IsType(batchErr.Errors[1], errors.ErrTypeNotFound))
+	assert.NoError(t, batchErr.Errors[0])
+	assert.NoError(t, batchErr.Errors[2])
+}
+
+// newScriptedBatchNode returns a mock node answering every batch with a fixed body
+func newScriptedBatchNode(t *testing.T, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+}
+
+func TestBatchGetBlocksByNumberShuffledResponse(t *testing.T) {
+	server := newScriptedBatchNode(t, `[
+		{"jsonrpc":"2.0","id":3,"result":{"number":"0x3"}},
+		{"jsonrpc":"2.0","id":1,"result":{"number":"0x1"}},
+		{"jsonrpc":"2.0","id":2,"result":{"number":"0x2"}}
+	]`)
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	blocks, err := client.BatchGetBlocksByNumber(context.Background(), []string{"0x1", "0x2", "0x3"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", blocks[0].Number)
+	assert.Equal(t, "0x2", blocks[1].Number)
+	assert.Equal(t, "0x3", blocks[2].Number)
+}
+
+func TestBatchGetBlocksByNumberPartialResponse(t *testing.T) {
+	// The provider silently drops the entry for ID 2
+	server := newScriptedBatchNode(t, `[
+		{"jsonrpc":"2.0","id":3,"result":{"number":"0x3"}},
+		{"jsonrpc":"2.0","id":1,"result":{"number":"0x1"}}
+	]`)
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	blocks, err := client.BatchGetBlocksByNumber(context.Background(), []string{"0x1", "0x2", "0x3"}, false)
+	assert.Equal(t, "0x1", blocks[0].Number)
+	assert.Nil(t, blocks[1])
+	assert.Equal(t, "0x3", blocks[2].Number)
+
+	batchErr, ok := err.(*BatchError)
+	assert.True(t, ok)
+	assert.NoError(t, batchErr.Errors[0])
+	assert.True(t, errors.IsType(batchErr.Errors[1], errors.ErrorTypeBlockchain))
+	assert.NoError(t, batchErr.Errors[2])
+}
+
+func TestBatchGetBlocksByNumberCorrelateByPosition(t *testing.T) {
+	// IDs are not echoed, so only the order ties responses to requests
+	server := newScriptedBatchNode(t, `[
+		{"jsonrpc":"2.0","result":{"number":"0x1"}},
+		{"jsonrpc":"2.0","result":{"number":"0x2"}}
+	]`)
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithBatchCorrelation(CorrelateByPosition))
+
+	blocks, err := client.BatchGetBlocksByNumber(context.Background(), []string{"0x1", "0x2"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", blocks[0].Number)
+	assert.Equal(t, "0x2", blocks[1].Number)
+
+	// A short response cannot be aligned positionally, so every entry fails
+	blocks, err = client.BatchGetBlocksByNumber(context.Background(), []string{"0x1", "0x2", "0x3"}, false)
+	assert.Error(t, err)
+	assert.Nil(t, blocks[0])
+	_, partial := err.(*BatchError)
+	assert.True(t, partial)
+}
@@ -0,0 +1,199 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// BatchCorrelation selects how batch responses are matched back to requests
+type BatchCorrelation int
+
+const (
+	// CorrelateByID matches responses to requests by JSON-RPC ID, tolerating
+	// reordered and omitted entries. This is the default.
+	CorrelateByID BatchCorrelation = iota
+	// CorrelateByPosition matches responses to requests by array position, for
+	// providers that do not echo request IDs. Since omissions cannot be detected
+	// positionally, a response with the wrong number of entries fails the batch.
+	CorrelateByPosition
+)
+
+// String returns the strategy name used in logs
+func (s BatchCorrelation) String() string {
+	switch s {
+	case CorrelateByID:
+		return "id"
+	case CorrelateByPosition:
+		return "position"
+	default:
+		return fmt.Sprintf("BatchCorrelation(%d)", int(s))
+	}
+}
+
+// WithBatchCorrelation sets how batch responses are matched back to requests
+func WithBatchCorrelation(strategy BatchCorrelation) Option {
+	return func(c *EnhancedClient) {
+		c.correlation = strategy
+	}
+}
+
+// BatchError is returned by BatchCall when only some entries of a batch failed.
+// Errors is aligned with the request slice; entries for successful calls are nil.
+type BatchError struct {
+	Errors []error
+}
+
+// Error implements the error interface
+func (e *BatchError) Error() string {
+	failed := 0
+	for _, err := range e.Errors {
+		if err != nil {
+			failed++
+		}
+	}
+	return fmt.Sprintf("%d of %d batch requests failed", failed, len(e.Errors))
+}
+
+// Unwrap returns the errors of the failed entries
+func (e *BatchError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errors {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// BatchCall sends several JSON-RPC requests in a single HTTP round trip using the
+// spec-compliant array form. Results are matched back to requests according to
+// the client's BatchCorrelation (by ID unless configured otherwise, since nodes
+// may answer out of order) and returned aligned with the request slice.
+// When only some entries fail, the successful results are returned together
+// with a *BatchError describing each failed entry.
+func (c *EnhancedClient) BatchCall(ctx context.Context, requests []models.RPCRequest) (_ []json.RawMessage, err error) {
+	if len(requests) == 0 {
+		return []json.RawMessage{}, nil
+	}
+
+	ctx, span := startSpan(ctx, "batch")
+	span.SetAttributes(attribute.Int("rpc.batch.size", len(requests)))
+	defer func() { endSpan(span, err) }()
+
+	// Index requests by ID so responses can be matched regardless of order
+	positions := make(map[int]int, len(requests))
+	for i, request := range requests {
+		if _, exists := positions[request.ID]; exists {
+			return nil, errors.NewValidationError(
+				fmt.Sprintf("Duplicate request ID %d in batch", request.ID), nil)
+		}
+		positions[request.ID] = i
+	}
+
+	requestJSON, err := json.Marshal(requests)
+	if err != nil {
+		return nil, errors.NewInternalError("Failed to marshal JSON batch request", err)
+	}
+
+	// A batch is only as safe to resend as its least idempotent request
+	batchIdempotent := true
+	for _, request := range requests {
+		batchIdempotent = batchIdempotent && idempotent(request.Method)
+	}
+
+	bodyBytes, err := c.post(ctx, "batch", batchIdempotent, requestJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []models.RPCResponse
+	if err := json.Unmarshal(bodyBytes, &responses); err != nil {
+		// Nodes reject a malformed or oversized batch with a single error object
+		var rpcError models.RPCErrorResponse
+		if json.Unmarshal(bodyBytes, &rpcError) == nil && rpcError.Error.Code != 0 {
+			return nil, c.newRPCResponseError(rpcError.Error)
+		}
+
+		logger.Error("Failed to unmarshal batch response",
+			zap.Error(err),
+			zap.String("response", string(bodyBytes)))
+		return nil, errors.NewInternalError("Failed to unmarshal JSON batch response", err)
+	}
+
+	matched, err := c.correlate(requests, positions, responses)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]json.RawMessage, len(requests))
+	entryErrors := make([]error, len(requests))
+
+	for i, response := range matched {
+		if response == nil {
+			entryErrors[i] = errors.NewBlockchainError(
+				fmt.Sprintf("No response for batch request ID %d", requests[i].ID), nil).
+				WithData(map[string]interface{}{"method": requests[i].Method})
+			continue
+		}
+		if response.Error != nil {
+			metrics.RecordRPCErrorCode(requests[i].Method, response.Error.Code)
+			entryErrors[i] = c.newRPCResponseError(*response.Error).
+				WithData(map[string]interface{}{"method": requests[i].Method})
+			continue
+		}
+		results[i] = response.Result
+	}
+
+	failed := false
+	for _, err := range entryErrors {
+		if err != nil {
+			failed = true
+		}
+	}
+
+	logger.Debug("Received batch RPC response",
+		zap.Int("requests", len(requests)),
+		zap.Int("responses", len(responses)))
+
+	if failed {
+		return results, &BatchError{Errors: entryErrors}
+	}
+	return results, nil
+}
+
+// correlate aligns batch responses with requests according to the client's
+// correlation strategy. Requests without a response get a nil entry.
+func (c *EnhancedClient) correlate(requests []models.RPCRequest, positions map[int]int, responses []models.RPCResponse) ([]*models.RPCResponse, error) {
+	matched := make([]*models.RPCResponse, len(requests))
+
+	if c.correlation == CorrelateByPosition {
+		if len(responses) != len(requests) {
+			return nil, errors.NewBlockchainError(
+				fmt.Sprintf("Batch response has %d entries for %d requests", len(responses), len(requests)), nil).
+				WithData(map[string]interface{}{"correlation": c.correlation.String()})
+		}
+		for i := range responses {
+			matched[i] = &responses[i]
+		}
+		return matched, nil
+	}
+
+	for i := range responses {
+		position, ok := positions[responses[i].ID]
+		if !ok {
+			logger.Warn("Batch response contains unknown request ID", zap.Int("id", responses[i].ID))
+			continue
+		}
+		matched[position] = &responses[i]
+	}
+	return matched, nil
+}
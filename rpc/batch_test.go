@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blockchain-client/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchCall(t *testing.T) {
+	// Create a mock server that answers out of order with one failed entry
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requests []models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&requests))
+		assert.Len(t, requests, 3)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(`[
+			{"jsonrpc":"2.0","id":3,"result":"0x3"},
+			{"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"invalid argument"}},
+			{"jsonrpc":"2.0","id":1,"result":"0x1"}
+		]`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	results, err := client.BatchCall(context.Background(), []models.RPCRequest{
+		{JSONRPC: "2.0", Method: "eth_blockNumber", ID: 1},
+		{JSONRPC: "2.0", Method: "eth_getBalance", ID: 2},
+		{JSONRPC: "2.0", Method: "eth_chainId", ID: 3},
+	})
+
+	// Successful entries are returned in request order despite the shuffle
+	assert.Len(t, results, 3)
+	assert.JSONEq(t, `"0x1"`, string(results[0]))
+	assert.Nil(t, results[1])
+	assert.JSONEq(t, `"0x3"`, string(results[2]))
+
+	// Only the failed entry carries an error
+	batchErr, ok := err.(*BatchError)
+	assert.True(t, ok)
+	assert.NoError(t, batchErr.Errors[0])
+	assert.Error(t, batchErr.Errors[1])
+	assert.NoError(t, batchErr.Errors[2])
+}
+
+func TestBatchCallMissingEntry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":"0x1"}]`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	results, err := client.BatchCall(context.Background(), []models.RPCRequest{
+		{JSONRPC: "2.0", Method: "eth_blockNumber", ID: 1},
+		{JSONRPC: "2.0", Method: "eth_blockNumber", ID: 2},
+	})
+
+	assert.JSONEq(t, `"0x1"`, string(results[0]))
+	batchErr, ok := err.(*BatchError)
+	assert.True(t, ok)
+	assert.NoError(t, batchErr.Errors[0])
+	assert.Error(t, batchErr.Errors[1])
+}
+
+func TestBatchCallDuplicateIDs(t *testing.T) {
+	client := NewEnhancedClient("http://localhost", 10*time.Second)
+
+	_, err := client.BatchCall(context.Background(), []models.RPCRequest{
+		{JSONRPC: "2.0", Method: "eth_blockNumber", ID: 1},
+		{JSONRPC: "2.0", Method: "eth_chainId", ID: 1},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package rpc
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"blockchain-client/pkg/errors"
+)
+
+// DefaultMaxResponseSize caps the decompressed size of an RPC response body.
+// It comfortably fits a batch of full blocks while bounding memory use.
+const DefaultMaxResponseSize int64 = 32 << 20
+
+// WithMaxResponseSize sets the largest RPC response body, after decompression,
+// that the client will read. Larger responses fail instead of exhausting memory.
+func WithMaxResponseSize(size int64) Option {
+	return func(c *EnhancedClient) {
+		if size > 0 {
+			c.maxResponseSize = size
+		}
+	}
+}
+
+// readBody reads a response body, decompressing gzip responses, and fails once
+// more than limit bytes have been produced. A small gzip payload can expand to
+// gigabytes, so the cap applies to the decompressed stream, not the wire size.
+func readBody(resp *http.Response, limit int64) ([]byte, error) {
+	var body io.Reader = resp.Body
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.NewBlockchainError("Failed to decompress RPC response", err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	// Read one byte past the limit to tell an exact fit from an overflow
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, errors.NewInternalError("Failed to read response body", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, errors.NewBlockchainError(
+			fmt.Sprintf("RPC response exceeds maximum size of %d bytes", limit), nil).
+			WithData(map[string]interface{}{"max_response_size": limit})
+	}
+	return data, nil
+}
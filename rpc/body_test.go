@@ -0,0 +1,79 @@
+package rpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blockchain-client/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gzipBytes compresses data with gzip
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestGzipResponseIsDecompressed(t *testing.T) {
+	body := gzipBytes(t, []byte(`{"jsonrpc":"2.0","id":1,"result":"0x134e82a"}`))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Encoding", "gzip")
+		_, err := w.Write(body)
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	blockNumber, err := client.GetLatestBlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, "0x134e82a", blockNumber)
+}
+
+func TestGzipBombIsRejected(t *testing.T) {
+	// 64MiB of zeros compresses to well under 200KiB on the wire
+	bomb := gzipBytes(t, make([]byte, 64<<20))
+	assert.Less(t, len(bomb), 200<<10)
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Encoding", "gzip")
+		_, err := w.Write(bomb)
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithMaxResponseSize(1<<20))
+
+	_, err := client.GetBlockByNumber("0x1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds maximum size of 1048576 bytes")
+	assert.True(t, errors.IsType(err, errors.ErrorTypeBlockchain))
+
+	// An oversized response is not retried
+	assert.Equal(t, 1, requests)
+}
+
+func TestOversizedPlainResponseIsRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x134e82a"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithMaxResponseSize(16))
+
+	_, err := client.GetLatestBlockNumber()
+	assert.Error(t, err)
+}
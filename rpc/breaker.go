@@ -0,0 +1,202 @@
+package rpc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// BreakerState is the state of a circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed lets every call through. This is the normal state.
+	BreakerClosed BreakerState = iota
+	// BreakerHalfOpen lets a single probe call through to test whether the
+	// node has recovered, failing every other call fast
+	BreakerHalfOpen
+	// BreakerOpen fails every call fast until the cooldown has passed
+	BreakerOpen
+)
+
+// String returns the state name used in logs
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// BreakerConfig configures the circuit breaker in front of the RPC endpoints
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed calls that opens
+	// the breaker. Zero disables the breaker.
+	FailureThreshold int
+	// Cooldown is how long the breaker stays open before probing the node
+	Cooldown time.Duration
+}
+
+// DefaultBreakerConfig returns the circuit breaker configuration used by main
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 5,
+		Cooldown:         30 * time.Second,
+	}
+}
+
+// WithCircuitBreaker stops calling the node after cfg.FailureThreshold
+// consecutive failures, failing calls immediately for cfg.Cooldown instead of
+// letting each one wait out the timeout. A single probe call then decides
+// whether the breaker closes again or stays open for another cooldown.
+func WithCircuitBreaker(cfg BreakerConfig) Option {
+	return func(c *EnhancedClient) {
+		if cfg.FailureThreshold <= 0 {
+			c.breakerConfig = nil
+			return
+		}
+		c.breakerConfig = &cfg
+	}
+}
+
+// circuitBreaker tracks consecutive call failures and decides whether calls
+// may reach the node
+type circuitBreaker struct {
+	config BreakerConfig
+	// name identifies the client in logs and the state gauge
+	name  string
+	gauge prometheus.Gauge
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	// probing is set while the half-open probe call is in flight
+	probing bool
+}
+
+// newCircuitBreaker creates a closed breaker reporting its state to gauge
+func newCircuitBreaker(cfg BreakerConfig, name string, gauge prometheus.Gauge) *circuitBreaker {
+	b := &circuitBreaker{config: cfg, name: name, gauge: gauge}
+	b.gauge.Set(float64(BreakerClosed))
+	return b
+}
+
+// allow reports whether a call may proceed, returning the error to fail it
+// with otherwise, and whether the call is the half-open probe. A call that is
+// allowed must be followed by done.
+func (b *circuitBreaker) allow() (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		remaining := b.config.Cooldown - time.Since(b.openedAt)
+		if remaining > 0 {
+			return false, b.openError(remaining)
+		}
+		b.transition(BreakerHalfOpen)
+	case BreakerHalfOpen:
+		if b.probing {
+			return false, b.openError(0)
+		}
+	default:
+		return false, nil
+	}
+	b.probing = true
+	return true, nil
+}
+
+// done records the outcome of an allowed call. Calls abandoned by their
+// caller count as neither success nor failure.
+func (b *circuitBreaker) done(probe, failed, abandoned bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+
+	switch {
+	case abandoned:
+	case !failed:
+		b.failures = 0
+		if probe {
+			b.transition(BreakerClosed)
+		}
+	case probe:
+		b.open()
+	default:
+		b.failures++
+		if b.state == BreakerClosed && b.failures >= b.config.FailureThreshold {
+			b.open()
+		}
+	}
+}
+
+// current returns the breaker's state
+func (b *circuitBreaker) current() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// open starts a new cooldown. The caller must hold b.mu.
+func (b *circuitBreaker) open() {
+	b.openedAt = time.Now()
+	b.transition(BreakerOpen)
+}
+
+// transition moves the breaker to state, logging the change and updating the
+// gauge. The caller must hold b.mu.
+func (b *circuitBreaker) transition(state BreakerState) {
+	if b.state == state {
+		return
+	}
+	previous := b.state
+	b.state = state
+	b.gauge.Set(float64(state))
+
+	fields := []zap.Field{
+		zap.String("endpoint", b.name),
+		zap.String("previous", previous.String()),
+		zap.String("state", state.String()),
+	}
+	if state == BreakerOpen {
+		logger.Warn("RPC circuit breaker opened", append(fields,
+			zap.Int("consecutive_failures", b.failures),
+			zap.Duration("cooldown", b.config.Cooldown))...)
+		return
+	}
+	logger.Info("RPC circuit breaker changed state", fields...)
+}
+
+// openError is the error returned for calls rejected by the breaker
+func (b *circuitBreaker) openError(retryAfter time.Duration) *errors.AppError {
+	return errors.NewUnavailableError("RPC node is unavailable, circuit breaker is open", nil).
+		WithCode(errors.CodeCircuitOpen).
+		WithData(map[string]interface{}{
+			"state":          b.state.String(),
+			"retry_after_ms": retryAfter.Milliseconds(),
+		})
+}
+
+// BreakerState returns the state of the client's circuit breaker, which is
+// always closed when the breaker is disabled
+func (c *EnhancedClient) BreakerState() BreakerState {
+	if c.breaker == nil {
+		return BreakerClosed
+	}
+	return c.breaker.current()
+}
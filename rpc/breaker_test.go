@@ -0,0 +1,91 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var calls int32
+	var healthy atomic.Bool
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+		assert.NoError(t, err)
+	}))
+	defer node.Close()
+
+	cooldown := 50 * time.Millisecond
+	client := NewEnhancedClient(node.URL, 10*time.Second,
+		WithCircuitBreaker(BreakerConfig{FailureThreshold: 2, Cooldown: cooldown}), WithName("breaker-test"))
+	gauge := metrics.RPCCircuitBreakerState.WithLabelValues("breaker-test")
+
+	// Consecutive failures open the breaker
+	for i := 0; i < 2; i++ {
+		_, err := client.GetLatestBlockNumber()
+		assert.Error(t, err)
+	}
+	assert.Equal(t, BreakerOpen, client.BreakerState())
+	assert.Equal(t, float64(BreakerOpen), testutil.ToFloat64(gauge))
+
+	// Calls now fail fast without reaching the node
+	_, err := client.GetLatestBlockNumber()
+	assert.ErrorIs(t, err, errors.ErrUnavailable)
+	if appErr, ok := errors.IsAppError(err); assert.True(t, ok) {
+		cause, ok := errors.IsAppError(appErr.Err)
+		assert.True(t, ok)
+		assert.Equal(t, errors.CodeCircuitOpen, cause.ErrorCode())
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// A failed probe after the cooldown reopens the breaker
+	time.Sleep(cooldown)
+	_, err = client.GetLatestBlockNumber()
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, BreakerOpen, client.BreakerState())
+
+	// A successful probe closes it
+	healthy.Store(true)
+	time.Sleep(cooldown)
+	blockNumber, err := client.GetLatestBlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, "0x10", blockNumber)
+	assert.Equal(t, BreakerClosed, client.BreakerState())
+	assert.Equal(t, float64(BreakerClosed), testutil.ToFloat64(gauge))
+}
+
+func TestCircuitBreakerIgnoresRPCErrors(t *testing.T) {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"invalid argument"}}`))
+		assert.NoError(t, err)
+	}))
+	defer node.Close()
+
+	client := NewEnhancedClient(node.URL, 10*time.Second,
+		WithCircuitBreaker(BreakerConfig{FailureThreshold: 1, Cooldown: time.Minute}))
+
+	// The node answered, so it is up even though the call failed
+	_, err := client.GetLatestBlockNumber()
+	assert.Error(t, err)
+	assert.Equal(t, BreakerClosed, client.BreakerState())
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	client := NewEnhancedClient("http://localhost", time.Second, WithCircuitBreaker(BreakerConfig{}))
+	assert.Nil(t, client.breaker)
+	assert.Equal(t, BreakerClosed, client.BreakerState())
+}
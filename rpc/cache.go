@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"container/list"
+	"sync"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/metrics"
+)
+
+// WithCache enables an in-memory LRU cache of up to size finalized blocks for
+// GetBlockByNumber. A size of zero or less leaves caching disabled.
+func WithCache(size int) Option {
+	return func(c *EnhancedClient) {
+		if size > 0 {
+			c.cache = newBlockCache(size)
+		}
+	}
+}
+
+// blockCacheEntry is a cached block and the key it is stored under
+type blockCacheEntry struct {
+	number string
+	block  *models.Block
+}
+
+// blockCache is a fixed-size LRU cache of blocks keyed by formatted block number
+type blockCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // front is most recently used
+	entries map[string]*list.Element
+}
+
+// newBlockCache creates a cache holding at most size blocks
+func newBlockCache(size int) *blockCache {
+	return &blockCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+// get returns the cached block for number, marking it as recently used
+func (bc *blockCache) get(number string) (*models.Block, bool) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	element, ok := bc.entries[number]
+	if !ok {
+		metrics.BlockCacheMissesTotal.Inc()
+		return nil, false
+	}
+
+	bc.order.MoveToFront(element)
+	metrics.BlockCacheHitsTotal.Inc()
+	return element.Value.(*blockCacheEntry).block, true
+}
+
+// add stores a block, evicting the least recently used entry when full
+func (bc *blockCache) add(number string, block *models.Block) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if element, ok := bc.entries[number]; ok {
+		element.Value.(*blockCacheEntry).block = block
+		bc.order.MoveToFront(element)
+		return
+	}
+
+	bc.entries[number] = bc.order.PushFront(&blockCacheEntry{number: number, block: block})
+	if bc.order.Len() > bc.size {
+		oldest := bc.order.Back()
+		bc.order.Remove(oldest)
+		delete(bc.entries, oldest.Value.(*blockCacheEntry).number)
+	}
+}
+
+// len returns the number of cached blocks
+func (bc *blockCache) len() int {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	return bc.order.Len()
+}
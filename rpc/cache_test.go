@@ -0,0 +1,98 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// newBlockNode returns a mock node answering eth_getBlockByNumber and counting calls
+func newBlockNode(t *testing.T, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		_, err := fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"number":%q}}`, request.Params[0])
+		assert.NoError(t, err)
+	}))
+}
+
+func TestCacheServesFinalizedBlocks(t *testing.T) {
+	var calls int32
+	server := newBlockNode(t, &calls)
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithCache(8), WithFinalityMargin(10))
+	confirmHead(client, 100)
+
+	hits := testutil.ToFloat64(metrics.BlockCacheHitsTotal)
+	misses := testutil.ToFloat64(metrics.BlockCacheMissesTotal)
+
+	for i := 0; i < 3; i++ {
+		block, err := client.GetBlockByNumber("0x10")
+		assert.NoError(t, err)
+		assert.Equal(t, "0x10", block.Number)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, hits+2, testutil.ToFloat64(metrics.BlockCacheHitsTotal))
+	assert.Equal(t, misses+1, testutil.ToFloat64(metrics.BlockCacheMissesTotal))
+}
+
+func TestCacheSkipsTagsAndRecentBlocks(t *testing.T) {
+	var calls int32
+	server := newBlockNode(t, &calls)
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithCache(8), WithFinalityMargin(10))
+	confirmHead(client, 100)
+
+	// Block 0x60 (96) is within the finality margin of the head
+	for _, number := range []string{"latest", "pending", "0x60", "latest", "pending", "0x60"} {
+		_, err := client.GetBlockByNumber(number)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(6), atomic.LoadInt32(&calls))
+	assert.Equal(t, 0, client.cache.len())
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	var calls int32
+	server := newBlockNode(t, &calls)
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithCache(2), WithFinalityMargin(10))
+	confirmHead(client, 100)
+
+	for _, number := range []string{"0x1", "0x2", "0x1", "0x3"} {
+		_, err := client.GetBlockByNumber(number)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, 2, client.cache.len())
+
+	// 0x1 was used more recently than 0x2, so 0x2 was evicted
+	_, err := client.GetBlockByNumber("0x1")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	_, err = client.GetBlockByNumber("0x2")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
+
+func TestCacheDisabledByDefault(t *testing.T) {
+	client := NewEnhancedClient("http://localhost", 10*time.Second, WithCache(0))
+	assert.Nil(t, client.cache)
+}
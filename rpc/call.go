@@ -0,0 +1,49 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Call executes a read-only contract call with eth_call against the state at
+// blockTag and returns the raw hex result. A call the contract rejects is
+// returned as an errors.ErrTypeReverted error carrying the node's revert reason.
+func (c *EnhancedClient) Call(ctx context.Context, msg models.CallMsg, blockTag string) (string, error) {
+	if err := validateAddress(msg.To); err != nil {
+		return "", err
+	}
+
+	blockTag, err := normalizeBlockTag(blockTag)
+	if err != nil {
+		return "", err
+	}
+
+	// Create JSON-RPC request
+	requestBody := models.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_call",
+		Params:  []interface{}{msg, blockTag},
+		ID:      1,
+	}
+
+	var response models.StringResponse
+	err = c.doRequestCtx(ctx, requestBody, &response)
+	if err != nil {
+		if errors.IsType(err, errors.ErrTypeReverted) {
+			return "", err
+		}
+		logger.Error("Failed to execute call",
+			zap.String("to", msg.To),
+			zap.String("block", blockTag),
+			zap.Error(err))
+		return "", errors.NewBlockchainError(fmt.Sprintf("Failed to execute call to %s", msg.To), err)
+	}
+
+	return response.Result, nil
+}
@@ -0,0 +1,61 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCall(t *testing.T) {
+	const token = "0xc2132d05d31c914a87c6611c10748aeb04b58e8f"
+	const balanceOf = "0x70a08231000000000000000000000000a7d9ddbe1f17865597fbd27ec712455208b6b76d"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "eth_call", request.Method)
+		assert.JSONEq(t, `{"to":"`+token+`","data":"`+balanceOf+`"}`, string(request.Params[0]))
+		assert.Equal(t, `"latest"`, string(request.Params[1]))
+
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x00000000000000000000000000000000000000000000000000000000000f4240"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	result, err := client.Call(context.Background(), models.CallMsg{To: token, Data: balanceOf}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x00000000000000000000000000000000000000000000000000000000000f4240", result)
+
+	_, err = client.Call(context.Background(), models.CallMsg{To: "0x1234"}, "latest")
+	assert.True(t, errors.IsType(err, errors.ErrTypeValidation))
+}
+
+func TestCallReverted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted: Ownable: caller is not the owner","data":"0x08c379a0"}}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	_, err := client.Call(context.Background(), models.CallMsg{To: "0xc2132d05d31c914a87c6611c10748aeb04b58e8f"}, "latest")
+	assert.True(t, errors.IsType(err, errors.ErrTypeReverted))
+
+	appErr, _ := errors.IsAppError(err)
+	assert.Equal(t, "execution reverted: Ownable: caller is not the owner", appErr.Message)
+	assert.Equal(t, "0x08c379a0", appErr.Data["revert_data"])
+}
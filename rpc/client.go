@@ -3,47 +3,203 @@ package rpc
 import (
 	"blockchain-client/models"
 	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
 	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
 // EnhancedClient implements JSON-RPC over HTTP for blockchain interactions
 // with improved error handling and logging
 type EnhancedClient struct {
 	rpcURL  string
+	// name labels the client's metrics; see WithName
+	name    string
 	httpClient  *http.Client
 	timeout time.Duration
+
+	// wsURL is the WebSocket endpoint used for subscriptions; wsDisplay is
+	// its redacted form for logs
+	wsURL     string
+	wsDisplay string
+	// wsPingInterval and wsPongTimeout keep subscriptions alive; see readNewHeads
+	wsPingInterval time.Duration
+	wsPongTimeout  time.Duration
+
+	// transport applies to every HTTP request made by httpClient; headers are
+	// sent to the primary endpoint and the WebSocket endpoint
+	transport http.RoundTripper
+	headers   http.Header
+
+	// endpoints are tried in turn starting from the preferred (last known-good) one
+	endpoints    []*endpoint
+	preferred    atomic.Int32
+	fallbackURLs []string
+
+	// limiter paces outgoing HTTP requests when rate limiting is enabled
+	limiter *rate.Limiter
+
+	// slots caps the requests in flight when a concurrency limit is set;
+	// inFlight counts them either way
+	slots    chan struct{}
+	inFlight prometheus.Gauge
+
+	// head is the latest known block number, fed via SetHead
+	head           atomic.Uint64
+	finalityMargin uint64
+
+	// maxHeadJump bounds how far a single report may move the head, and
+	// headCandidate holds the last report rejected for exceeding it
+	maxHeadJump   uint64
+	headCandidate atomic.Uint64
+
+	retry RetryConfig
+
+	// correlation matches batch responses back to their requests
+	correlation BatchCorrelation
+
+	// batcher coalesces concurrent block lookups when auto-batching is enabled
+	batcher *blockBatcher
+
+	// cache holds finalized blocks when caching is enabled
+	cache *blockCache
+
+	// warmer keeps the blocks near the head cached when cache warming is enabled
+	warmer *cacheWarmer
+
+	// maxResponseSize caps the decompressed size of response bodies
+	maxResponseSize int64
+
+	// maxLogBlockRange caps the block span of eth_getLogs queries; 0 disables the cap
+	maxLogBlockRange uint64
+
+	// faults, when set, injects synthetic failures for chaos testing
+	faults *FaultConfig
+
+	// dumper, when set, writes sampled HTTP exchanges for provider debugging
+	dumper *requestDumper
+
+	// rpcPath, apiKeyParam, apiKey and apiKeyPath are applied to the primary endpoint URL
+	rpcPath     string
+	apiKeyParam string
+	apiKey      string
+	apiKeyPath  string
+
+	// authorization is the Authorization header value sent to the primary
+	// endpoint and the WebSocket endpoint
+	authorization string
+
+	// serverErrorPatterns classify the node's -32000 errors
+	serverErrorPatterns []ServerErrorPattern
+
+	// tolerantDecoding returns blocks with undecodable transactions instead of failing them
+	tolerantDecoding bool
+
+	// requestIDHeader forwards the triggering API request's ID to the node; empty disables it
+	requestIDHeader string
+
+	// breaker fails calls fast while the node is down; nil when disabled
+	breakerConfig *BreakerConfig
+	breaker       *circuitBreaker
 }
 
-// NewEnhancedClient creates a new RPC client with enhanced error handling
-func NewEnhancedClient(rpcURL string, timeout time.Duration) *EnhancedClient {
-	if timeout <= 0 {
-		timeout = 10 * time.Second // Default timeout
+// Option configures optional behaviour of an EnhancedClient
+type Option func(*EnhancedClient)
+
+// DefaultTimeout bounds each RPC attempt unless WithTimeout says otherwise
+const DefaultTimeout = 10 * time.Second
+
+// NewClient creates a new RPC client for rpcURL, configured by opts
+func NewClient(rpcURL string, opts ...Option) *EnhancedClient {
+	client := &EnhancedClient{
+		rpcURL:           rpcURL,
+		name:             DefaultClientName,
+		timeout:          DefaultTimeout,
+		finalityMargin:   DefaultFinalityMargin,
+		maxHeadJump:      DefaultMaxHeadJump,
+		maxResponseSize:  DefaultMaxResponseSize,
+		maxLogBlockRange: DefaultMaxLogBlockRange,
+		wsPingInterval:   pingInterval,
+		wsPongTimeout:    pongTimeout,
+
+		serverErrorPatterns: DefaultServerErrorPatterns,
+		requestIDHeader:     DefaultRequestIDHeader,
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	if client.httpClient == nil {
+		if client.transport == nil {
+			client.transport = newTransport(DefaultTransportConfig())
+		}
+		client.httpClient = &http.Client{
+			Timeout:   client.timeout,
+			Transport: client.transport,
+		}
+	}
+
+	rpcURLs := append([]string{rpcURL}, client.fallbackURLs...)
+	client.endpoints = make([]*endpoint, len(rpcURLs))
+	displayURLs := make([]string, len(rpcURLs))
+	for i, url := range rpcURLs {
+		client.endpoints[i] = newEndpoint(url)
+		client.resolveEndpoint(client.endpoints[i], i == 0)
+		if i == 0 {
+			client.endpoints[i].header = client.primaryHeader()
+		}
+		displayURLs[i] = client.endpoints[i].display
+	}
+	logger.Debug("Initializing enhanced RPC client",
+		zap.Strings("rpc_urls", displayURLs),
+		zap.Duration("timeout", client.timeout),
+		zap.Any("headers", redactHeaders(client.headers)))
+	client.inFlight = metrics.RPCInFlightRequests.WithLabelValues(client.name)
+	if client.breakerConfig != nil {
+		client.breaker = newCircuitBreaker(*client.breakerConfig, client.name, metrics.RPCCircuitBreakerState.WithLabelValues(client.name))
 	}
 
-	logger.Debug("Initializing enhanced RPC client", 
-		zap.String("rpc_url", rpcURL), 
-		zap.Duration("timeout", timeout))
+	return client
+}
+
+// NewEnhancedClient creates a new RPC client with enhanced error handling. It
+// is NewClient with the timeout given as WithTimeout.
+func NewEnhancedClient(rpcURL string, timeout time.Duration, opts ...Option) *EnhancedClient {
+	return NewClient(rpcURL, append([]Option{WithTimeout(timeout)}, opts...)...)
+}
 
-	return &EnhancedClient{
-		rpcURL: rpcURL,
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-		timeout: timeout,
+// NewEnhancedClientWithEndpoints creates a new RPC client that fails over between
+// several RPC endpoints. The first URL is preferred until it fails. It is
+// NewClient with the timeout and remaining URLs given as WithTimeout and WithEndpoints.
+func NewEnhancedClientWithEndpoints(rpcURLs []string, timeout time.Duration, opts ...Option) *EnhancedClient {
+	if len(rpcURLs) == 0 {
+		rpcURLs = []string{""}
 	}
+	return NewClient(rpcURLs[0], append([]Option{WithTimeout(timeout), WithEndpoints(rpcURLs[1:]...)}, opts...)...)
 }
 
 // GetLatestBlockNumber gets the latest block number from the blockchain
 func (c *EnhancedClient) GetLatestBlockNumber() (string, error) {
+	return c.GetLatestBlockNumberCtx(context.Background())
+}
+
+// GetLatestBlockNumberCtx gets the latest block number from the blockchain,
+// aborting the request when ctx is done
+func (c *EnhancedClient) GetLatestBlockNumberCtx(ctx context.Context) (string, error) {
 	// Create JSON-RPC request
 	requestBody := models.RPCRequest{
 		JSONRPC: "2.0",
@@ -53,7 +209,7 @@ func (c *EnhancedClient) GetLatestBlockNumber() (string, error) {
 	}
 	
 	var response models.BlockNumberResponse
-	err := c.doRequest(requestBody, &response)
+	err := c.doRequestCtx(ctx, requestBody, &response)
 	if err != nil {
 		logger.Error("Failed to get latest block number", zap.Error(err))
 		return "", errors.NewBlockchainError("Failed to get latest block number", err)
@@ -64,13 +220,86 @@ func (c *EnhancedClient) GetLatestBlockNumber() (string, error) {
 }
 
 // GetBlockByNumber retrieves a block by its number
-// To maintain backward compatibility, we default includeTransactions to true
 func (c *EnhancedClient) GetBlockByNumber(blockNumber string) (*models.Block, error) {
-	return c.getBlockByNumber(blockNumber, true)
+	return c.GetBlockByNumberCtx(context.Background(), blockNumber)
+}
+
+// GetBlockByNumberCtx retrieves a block by its number, aborting the request
+// when ctx is done.
+// To maintain backward compatibility, we default includeTransactions to true.
+// Finalized blocks are served from the cache when one is configured; tags
+// such as "latest" are never final and so never cached. Blocks near the head
+// are served from the warmed window when cache warming is enabled.
+func (c *EnhancedClient) GetBlockByNumberCtx(ctx context.Context, blockNumber string) (*models.Block, error) {
+	cacheable := c.cache != nil && c.IsFinalized(blockNumber)
+	if cacheable {
+		if block, ok := c.cache.get(blockNumber); ok {
+			logger.Debug("Served block from cache", zap.String("block_number", blockNumber))
+			return block, nil
+		}
+	}
+	if block, ok := c.warmer.get(blockNumber); ok {
+		logger.Debug("Served block from warmed cache", zap.String("block_number", blockNumber))
+		return block, nil
+	}
+
+	var block *models.Block
+	var err error
+	if c.batcher != nil {
+		block, err = c.batcher.getBlock(ctx, blockQuery{number: blockNumber, includeTransactions: true})
+	} else {
+		block, err = c.getBlockByNumber(ctx, blockNumber, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Blocks with undecodable transactions may decode cleanly when refetched
+	if cacheable && len(block.TransactionErrors) == 0 {
+		c.cache.add(blockNumber, block)
+	}
+	return block, nil
+}
+
+// GetBlockHeaderByNumber retrieves a block without its transaction objects,
+// which keeps the payload small for callers that only need header fields.
+// The returned block has an empty Transactions slice; the hashes of its
+// transactions are in TransactionHashes. Headers bypass the block cache.
+func (c *EnhancedClient) GetBlockHeaderByNumber(ctx context.Context, blockNumber string) (*models.Block, error) {
+	var block *models.Block
+	var err error
+	if c.batcher != nil {
+		block, err = c.batcher.getBlock(ctx, blockQuery{number: blockNumber, includeTransactions: false})
+	} else {
+		block, err = c.getBlockByNumber(ctx, blockNumber, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block.Transactions = []models.Transaction{}
+	return block, nil
+}
+
+// GetLatestBlockFull retrieves the latest block in a single eth_getBlockByNumber
+// call instead of resolving the number first, and records its number as the head
+func (c *EnhancedClient) GetLatestBlockFull(ctx context.Context, includeTransactions bool) (*models.Block, error) {
+	block, err := c.getBlockByNumber(ctx, "latest", includeTransactions)
+	if err != nil {
+		return nil, err
+	}
+
+	if number, err := hexutil.DecodeUint64(block.Number); err == nil {
+		c.SetHead(number)
+	} else {
+		logger.Warn("Unparseable latest block number", zap.String("block_number", block.Number), zap.Error(err))
+	}
+
+	return block, nil
 }
 
 // getBlockByNumber is the internal implementation that allows control over the includeTransactions parameter
-func (c *EnhancedClient) getBlockByNumber(blockNumber string, includeTransactions bool) (*models.Block, error) {
+func (c *EnhancedClient) getBlockByNumber(ctx context.Context, blockNumber string, includeTransactions bool) (*models.Block, error) {
 	// Create JSON-RPC request
 	requestBody := models.RPCRequest{
 		JSONRPC: "2.0",
@@ -80,8 +309,12 @@ func (c *EnhancedClient) getBlockByNumber(blockNumber string, includeTransaction
 	}
 	
 	var response models.BlockResponse
-	err := c.doRequest(requestBody, &response)
+	err := c.doRequestCtx(ctx, requestBody, &response)
 	if err != nil {
+		// Nodes that have not seen the block yet answer with a server error
+		if errors.IsType(err, errors.ErrTypeNotFound) {
+			return nil, err
+		}
 		logger.Error("Failed to get block by number", 
 			zap.String("block_number", blockNumber), 
 			zap.Error(err))
@@ -92,96 +325,372 @@ func (c *EnhancedClient) getBlockByNumber(blockNumber string, includeTransaction
 		logger.Warn("Block not found", zap.String("block_number", blockNumber))
 		errData := make(map[string]interface{})
 		errData["block_number"] = blockNumber
-		return nil, errors.NewNotFoundError("Block not found", nil).WithData(errData)
+		return nil, errors.NewNotFoundError("Block not found", nil).WithCode(errors.CodeBlockNotFound).WithData(errData)
 	}
 	
+	if err := c.checkTransactionDecoding(response.Result); err != nil {
+		return nil, err
+	}
 	return response.Result, nil
 }
 
+// GetBlockByHash retrieves a block by block hash, with its transaction objects
+// when includeTransactions is set. Otherwise the returned block has an empty
+// Transactions slice and the hashes of its transactions are in
+// TransactionHashes. Blocks looked up by hash bypass the block cache.
+func (c *EnhancedClient) GetBlockByHash(ctx context.Context, hash string, includeTransactions bool) (*models.Block, error) {
+	if err := validateHash(hash); err != nil {
+		return nil, err
+	}
+
+	// Create JSON-RPC request
+	requestBody := models.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_getBlockByHash",
+		Params:  []interface{}{hash, includeTransactions},
+		ID:      1,
+	}
+
+	var response models.BlockResponse
+	err := c.doRequestCtx(ctx, requestBody, &response)
+	if err != nil {
+		logger.Error("Failed to get block by hash",
+			zap.String("block_hash", hash),
+			zap.Error(err))
+		return nil, errors.NewBlockchainError(fmt.Sprintf("Failed to get block data for block %s", hash), err)
+	}
+
+	if response.Result == nil {
+		logger.Warn("Block not found", zap.String("block_hash", hash))
+		return nil, errors.NewNotFoundError("Block not found", nil).
+			WithCode(errors.CodeBlockNotFound).
+			WithData(map[string]interface{}{"block_hash": hash})
+	}
+
+	if !includeTransactions {
+		response.Result.Transactions = []models.Transaction{}
+		return response.Result, nil
+	}
+	if err := c.checkTransactionDecoding(response.Result); err != nil {
+		return nil, err
+	}
+	return response.Result, nil
+}
+
+// WithTolerantDecoding makes block lookups return the transactions that could
+// be decoded, listing the others in the block's TransactionErrors, instead of
+// failing the whole block over a single malformed transaction
+func WithTolerantDecoding(enabled bool) Option {
+	return func(c *EnhancedClient) {
+		c.tolerantDecoding = enabled
+	}
+}
+
+// checkTransactionDecoding fails a block with undecodable transactions unless
+// tolerant decoding is enabled
+func (c *EnhancedClient) checkTransactionDecoding(block *models.Block) error {
+	if c.tolerantDecoding || len(block.TransactionErrors) == 0 {
+		return nil
+	}
+
+	logger.Error("Block contains transactions that failed to decode",
+		zap.String("block_number", block.Number),
+		zap.Int("failed", len(block.TransactionErrors)),
+		zap.String("first_error", block.TransactionErrors[0].Error))
+	return errors.NewBlockchainError(
+		fmt.Sprintf("Failed to decode %d transactions of block %s", len(block.TransactionErrors), block.Number), nil).
+		WithData(map[string]interface{}{
+			"block_number":       block.Number,
+			"transaction_errors": block.TransactionErrors,
+		})
+}
+
 // doRequest performs an HTTP request to the RPC endpoint
 func (c *EnhancedClient) doRequest(request models.RPCRequest, response interface{}) error {
+	return c.doRequestCtx(context.Background(), request, response)
+}
+
+// doRequestCtx performs an HTTP request to the RPC endpoint, aborting when ctx is done
+func (c *EnhancedClient) doRequestCtx(ctx context.Context, request models.RPCRequest, response interface{}) (err error) {
+	ctx, span := startSpan(ctx, request.Method)
+	defer func() { endSpan(span, err) }()
+
 	requestJSON, err := json.Marshal(request)
 	if err != nil {
 		return errors.NewInternalError("Failed to marshal JSON request", err)
 	}
 	
-	// Create a context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	for attempt := 0; ; attempt++ {
+		// Each attempt applies the client timeout on its own
+		bodyBytes, err := c.post(ctx, request.Method, idempotent(request.Method), requestJSON)
+		if err != nil {
+			return err
+		}
+
+		err = json.Unmarshal(bodyBytes, response)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to unmarshal response",
+				zap.Error(err),
+				zap.String("response", string(bodyBytes)))
+			return errors.NewInternalError("Failed to unmarshal JSON response", err)
+		}
+
+		// Check for RPC error response
+		var rpcError models.RPCErrorResponse
+		if err := json.Unmarshal(bodyBytes, &rpcError); err == nil && rpcError.Error.Code != 0 {
+			span.SetAttributes(semconv.RPCJsonrpcErrorCode(rpcError.Error.Code))
+			metrics.RecordRPCErrorCode(request.Method, rpcError.Error.Code)
+			appErr := c.newRPCResponseError(rpcError.Error)
+
+			// Errors matching a Retryable server error pattern may clear on
+			// their own, so they are retried like transient HTTP failures
+			retryable, _ := appErr.Data["retryable"].(bool)
+			if !retryable || !idempotent(request.Method) || attempt >= c.retry.MaxRetries {
+				return appErr
+			}
+			if err := c.waitToRetry(ctx, request.Method, attempt, c.retry.backoff(attempt), appErr); err != nil {
+				return err
+			}
+			continue
+		}
+
+		return nil
+	}
+}
+
+// post sends a JSON payload to the RPC endpoint and returns the raw response body,
+// retrying transient failures according to the client's retry configuration.
+// While the circuit breaker is open the payload is not sent at all.
+// The method is only used for logging and may describe a batch. Payloads
+// that are not idempotent are neither retried nor failed over.
+func (c *EnhancedClient) post(ctx context.Context, method string, idempotent bool, payload []byte) ([]byte, error) {
+	if err := c.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	if c.breaker == nil {
+		bodyBytes, _, err := c.postWithRetry(ctx, method, idempotent, payload)
+		return bodyBytes, err
+	}
+
+	probe, err := c.breaker.allow()
+	if err != nil {
+		logger.FromContext(ctx).Debug("RPC request rejected by circuit breaker", zap.String("method", method))
+		return nil, err
+	}
+
+	// Only failures that suggest the node is down count towards opening the breaker
+	bodyBytes, status, err := c.postWithRetry(ctx, method, idempotent, payload)
+	c.breaker.done(probe, err != nil && shouldFailover(status), ctx.Err() != nil)
+	return bodyBytes, err
+}
+
+// postWithRetry implements post, also returning the HTTP status of the last attempt
+func (c *EnhancedClient) postWithRetry(ctx context.Context, method string, idempotent bool, payload []byte) ([]byte, int, error) {
+	// Record the payload once per logical request rather than per attempt
+	metrics.RecordRPCRequestSize(method, metrics.SourceFromContext(ctx), len(payload))
+
+	for attempt := 0; ; attempt++ {
+		// A slot is held per attempt, so backing off frees it for other callers
+		if err := c.acquireSlot(ctx); err != nil {
+			return nil, 0, err
+		}
+		bodyBytes, status, retryAfter, err := c.sendWithFailover(ctx, method, idempotent, payload)
+		c.releaseSlot()
+		recordHTTPStatus(ctx, status)
+		if err == nil {
+			metrics.RecordRPCResponseSize(method, len(bodyBytes))
+			return bodyBytes, status, nil
+		}
+		// Transport failures have no status and are worth another try, unless
+		// the request may have taken effect before it failed
+		retryable := idempotent && (status == 0 || isRetryableStatus(status))
+		if ctx.Err() != nil || !retryable || attempt >= c.retry.MaxRetries {
+			return nil, status, err
+		}
+		
+		backoff := c.retry.backoff(attempt)
+		if retryAfter > 0 {
+			// Waiting beyond the configured ceiling would stall the caller
+			if retryAfter > c.retry.MaxBackoff {
+				return nil, status, err
+			}
+			backoff = retryAfter
+		}
+		
+		if err := c.waitToRetry(ctx, method, attempt, backoff, err); err != nil {
+			return nil, status, err
+		}
+	}
+}
+
+// waitToRetry waits out the backoff before retrying a request that failed with
+// cause, returning an error if ctx is done first
+func (c *EnhancedClient) waitToRetry(ctx context.Context, method string, attempt int, backoff time.Duration, cause error) error {
+	logger.FromContext(ctx).Debug("Retrying RPC request",
+		zap.String("method", method),
+		zap.Int("attempt", attempt+1),
+		zap.Duration("backoff", backoff),
+		zap.Error(cause))
+
+	timer := time.NewTimer(backoff)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return errors.NewTimeoutError("RPC request cancelled while waiting to retry", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// send performs a single HTTP exchange with an RPC endpoint. It returns the
+// HTTP status (zero when no response was received) and how long the server
+// asked us to wait before retrying.
+func (c *EnhancedClient) send(ctx context.Context, ep *endpoint, method string, payload []byte) (bodyBytes []byte, status int, retryAfter time.Duration, err error) {
+	// Create a context with timeout for this attempt
+	attemptCtx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
+
+	if c.faults != nil {
+		if status, err := c.injectFault(attemptCtx, method); err != nil {
+			return nil, status, 0, err
+		}
+	}
 	
 	reqStartTime := time.Now()
-	logger.Debug("Sending RPC request", 
-		zap.String("method", request.Method), 
-		zap.String("url", c.rpcURL))
+	logger.FromContext(ctx).Debug("Sending RPC request", 
+		zap.String("method", method), 
+		zap.String("url", ep.display))
 	
 	// Create HTTP request with context
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.rpcURL, bytes.NewReader(requestJSON))
+	req, err := http.NewRequestWithContext(attemptCtx, http.MethodPost, ep.url, bytes.NewReader(payload))
 	if err != nil {
-		return errors.NewInternalError("Failed to create HTTP request", err)
+		return nil, 0, 0, errors.NewInternalError("Failed to create HTTP request", err)
 	}
 	
+	for name, values := range ep.header {
+		req.Header[name] = values
+	}
 	req.Header.Set("Content-Type", "application/json")
+	// Requesting gzip explicitly turns off the transport's transparent
+	// decompression, so readBody can bound the decompressed size itself
+	req.Header.Set("Accept-Encoding", "gzip")
+	c.setRequestIDHeader(req)
+	injectTraceContext(attemptCtx, req.Header)
+	dumped := c.dumper.sample()
 	
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			logger.Warn("RPC request timed out",
-				zap.String("method", request.Method),
+		// Transport errors quote the request URL, which may carry an API key
+		if urlErr, ok := err.(*url.Error); ok {
+			urlErr.URL = ep.display
+		}
+		if dumped {
+			c.dumper.dump(ep, method, req, payload, nil, nil, err, time.Since(reqStartTime))
+		}
+
+		// The caller gave up, so there is nothing left to retry for
+		if ctx.Err() != nil {
+			return nil, 0, 0, errors.NewTimeoutError("RPC request cancelled", err)
+		}
+		
+		if attemptCtx.Err() == context.DeadlineExceeded {
+			logger.FromContext(ctx).Warn("RPC request timed out",
+				zap.String("method", method),
+				zap.String("url", ep.display),
 				zap.Duration("elapsed", time.Since(reqStartTime)))
-			return errors.NewTimeoutError("RPC request timed out", err)
+			return nil, 0, 0, errors.NewTimeoutError("RPC request timed out", err)
 		}
 		
-		logger.Error("RPC request failed", 
-			zap.String("method", request.Method), 
+		logger.FromContext(ctx).Error("RPC request failed", 
+			zap.String("method", method), 
+			zap.String("url", ep.display),
 			zap.Error(err))
-		return errors.NewInternalError("Failed to execute HTTP request", err)
+		return nil, 0, 0, errors.NewInternalError("Failed to execute HTTP request", err)
 	}
 	defer resp.Body.Close()
 	
-	bodyBytes, err := io.ReadAll(resp.Body)
+	bodyBytes, err = readBody(resp, c.maxResponseSize)
 	if err != nil {
-		return errors.NewInternalError("Failed to read response body", err)
+		logger.FromContext(ctx).Error("Failed to read RPC response",
+			zap.String("method", method),
+			zap.String("url", ep.display),
+			zap.Error(err))
+		return nil, resp.StatusCode, 0, err
+	}
+	if dumped {
+		c.dumper.dump(ep, method, req, payload, resp, bodyBytes, nil, time.Since(reqStartTime))
 	}
 	
 	// Log response status and time
-	logger.Debug("Received RPC response", 
-		zap.String("method", request.Method),
+	logger.FromContext(ctx).Debug("Received RPC response", 
+		zap.String("method", method),
 		zap.Int("status", resp.StatusCode),
 		zap.Duration("elapsed", time.Since(reqStartTime)))
 	
 	if resp.StatusCode != http.StatusOK {
-		logger.Warn("Non-200 response from RPC",
+		logger.FromContext(ctx).Warn("Non-200 response from RPC",
 			zap.Int("status", resp.StatusCode),
 			zap.String("body", string(bodyBytes)))
 		
 		errData := make(map[string]interface{})
 		errData["status_code"] = resp.StatusCode
 		errData["response"] = string(bodyBytes)
-		return errors.NewBlockchainError(
-			fmt.Sprintf("RPC server returned non-200 response: %d", resp.StatusCode), nil).WithData(errData)
+		return nil, resp.StatusCode, parseRetryAfter(resp.Header.Get("Retry-After")),
+			errors.NewBlockchainError(
+				fmt.Sprintf("RPC server returned non-200 response: %d", resp.StatusCode), nil).WithData(errData)
 	}
 	
-	err = json.Unmarshal(bodyBytes, response)
-	if err != nil {
-		logger.Error("Failed to unmarshal response",
-			zap.Error(err),
-			zap.String("response", string(bodyBytes)))
-		return errors.NewInternalError("Failed to unmarshal JSON response", err)
+	return bodyBytes, resp.StatusCode, 0, nil
+}
+
+// newRPCResponseError converts a JSON-RPC error object into an AppError.
+// Reverted calls are the contract's answer rather than a node failure, so
+// they get their own error type and keep any revert data the node returned.
+// Queries refused for matching too much data and methods the node does not
+// offer are also distinguished, since callers can recover from both, as are
+// the -32000 server errors matching the client's ServerErrorPatterns.
+func (c *EnhancedClient) newRPCResponseError(rpcErr models.RPCError) *errors.AppError {
+	errData := make(map[string]interface{})
+	errData["error_code"] = rpcErr.Code
+	errData["error_message"] = rpcErr.Message
+
+	if strings.Contains(strings.ToLower(rpcErr.Message), "execution reverted") {
+		logger.Debug("RPC call reverted",
+			zap.Int("error_code", rpcErr.Code),
+			zap.String("error_message", rpcErr.Message))
+		if len(rpcErr.Data) > 0 {
+			errData["revert_data"] = strings.Trim(string(rpcErr.Data), `"`)
+		}
+		return errors.NewRevertedError(rpcErr.Message, nil).WithData(errData)
 	}
-	
-	// Check for RPC error response
-	var rpcError models.RPCErrorResponse
-	if err := json.Unmarshal(bodyBytes, &rpcError); err == nil && rpcError.Error.Code != 0 {
-		logger.Error("RPC returned error",
-			zap.Int("error_code", rpcError.Error.Code),
-			zap.String("error_message", rpcError.Error.Message))
-		
-		errData := make(map[string]interface{})
-		errData["error_code"] = rpcError.Error.Code
-		errData["error_message"] = rpcError.Error.Message
-		return errors.NewBlockchainError(
-			fmt.Sprintf("RPC error: %s (code: %d)", rpcError.Error.Message, rpcError.Error.Code), nil).WithData(errData)
+
+	if isUnsupportedMethod(rpcErr) {
+		logger.Debug("RPC method not supported by node",
+			zap.Int("error_code", rpcErr.Code),
+			zap.String("error_message", rpcErr.Message))
+		return errors.NewUnsupportedError(rpcErr.Message, nil).WithData(errData)
 	}
-	
-	return nil
+
+	if isTooManyResults(rpcErr.Message) {
+		logger.Warn("RPC query returned too many results",
+			zap.Int("error_code", rpcErr.Code),
+			zap.String("error_message", rpcErr.Message))
+		return errors.NewTooManyResultsError(rpcErr.Message, nil).WithData(errData)
+	}
+
+	if pattern, ok := c.classifyServerError(rpcErr); ok {
+		logger.Debug("Classified RPC server error",
+			zap.Int("error_code", rpcErr.Code),
+			zap.String("error_message", rpcErr.Message),
+			zap.String("error_type", pattern.Type))
+		errData["retryable"] = pattern.Retryable
+		return errors.NewAppError(pattern.Type, rpcErr.Message, nil).WithData(errData)
+	}
+
+	logger.Error("RPC returned error",
+		zap.Int("error_code", rpcErr.Code),
+		zap.String("error_message", rpcErr.Message))
+	return errors.NewBlockchainError(
+		fmt.Sprintf("RPC error: %s (code: %d)", rpcErr.Message, rpcErr.Code), nil).WithData(errData)
 }
@@ -1,11 +1,19 @@
 package rpc
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -105,3 +113,337 @@ func TestErrorHandling(t *testing.T) {
 	_, err := client.GetLatestBlockNumber()
 	assert.Error(t, err)
 }
+
+func TestGetLatestBlockFull(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		// The full block must come from a single eth_getBlockByNumber call
+		assert.Equal(t, "eth_getBlockByNumber", request.Method)
+		assert.Equal(t, []interface{}{"latest", false}, request.Params)
+
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x134e82a","hash":"0x1234"}}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	block, err := client.GetLatestBlockFull(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x134e82a", block.Number)
+
+	// The second report confirms the first head
+	_, err = client.GetLatestBlockFull(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0x134e82a), client.Head())
+}
+
+func TestGetBlockHeaderByNumber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		// Transaction objects must not be requested
+		assert.Equal(t, []interface{}{"0x10", false}, request.Params)
+
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x10","transactions":["0xabc","0xdef"]}}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	block, err := client.GetBlockHeaderByNumber(context.Background(), "0x10")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x10", block.Number)
+	assert.NotNil(t, block.Transactions)
+	assert.Empty(t, block.Transactions)
+	assert.Equal(t, []string{"0xabc", "0xdef"}, block.TransactionHashes)
+}
+
+func TestGetBlockByHash(t *testing.T) {
+	const hash = "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "eth_getBlockByHash", request.Method)
+
+		result := `{"number":"0x10","hash":"` + hash + `","transactions":[{"hash":"0xabc"}]}`
+		switch {
+		case request.Params[0] != hash:
+			result = "null"
+		case request.Params[1] == false:
+			result = `{"number":"0x10","hash":"` + hash + `","transactions":["0xabc"]}`
+		}
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":` + result + `}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	block, err := client.GetBlockByHash(context.Background(), hash, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x10", block.Number)
+	assert.Len(t, block.Transactions, 1)
+
+	block, err = client.GetBlockByHash(context.Background(), hash, false)
+	assert.NoError(t, err)
+	assert.NotNil(t, block.Transactions)
+	assert.Empty(t, block.Transactions)
+	assert.Equal(t, []string{"0xabc"}, block.TransactionHashes)
+
+	_, err = client.GetBlockByHash(context.Background(), "0x"+strings.Repeat("0", 64), true)
+	assert.True(t, errors.IsType(err, errors.ErrorTypeNotFound), err)
+
+	// Malformed hashes are rejected without calling the node
+	_, err = client.GetBlockByHash(context.Background(), "0x10", true)
+	assert.True(t, errors.IsType(err, errors.ErrorTypeValidation), err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestGetBlockByNumberTolerantDecoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := `{"jsonrpc":"2.0","id":1,"result":{"number":"0x10","transactions":[
+			{"hash":"0x01","gas":"0x5208"},
+			{"hash":"0x02","gas":{"unexpected":true}},
+			{"hash":"0x03","gas":"0x5208"}
+		]}}`
+		body, _ := io.ReadAll(r.Body)
+		if body[0] == '[' {
+			response = "[" + response + "]"
+		}
+		_, err := w.Write([]byte(response))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	// By default one malformed transaction fails the block
+	strict := NewEnhancedClient(server.URL, 10*time.Second)
+	_, err := strict.GetBlockByNumberCtx(context.Background(), "0x10")
+	assert.True(t, errors.IsType(err, errors.ErrorTypeBlockchain))
+	_, err = strict.BatchGetBlocksByNumber(context.Background(), []string{"0x10"}, true)
+	assert.Error(t, err)
+
+	// Tolerant clients return the good transactions and list the bad one
+	tolerant := NewEnhancedClient(server.URL, 10*time.Second, WithTolerantDecoding(true))
+	block, err := tolerant.GetBlockByNumberCtx(context.Background(), "0x10")
+	assert.NoError(t, err)
+	assert.Len(t, block.Transactions, 2)
+	assert.Equal(t, []string{"0x01", "0x03"}, []string{block.Transactions[0].Hash, block.Transactions[1].Hash})
+	assert.Len(t, block.TransactionErrors, 1)
+	assert.Equal(t, "0x02", block.TransactionErrors[0].Hash)
+
+	blocks, err := tolerant.BatchGetBlocksByNumber(context.Background(), []string{"0x10"}, true)
+	assert.NoError(t, err)
+	assert.Len(t, blocks[0].TransactionErrors, 1)
+}
+
+// confirmHead records head as the chain head, reporting it twice since the
+// first head is only accepted once a second report confirms it
+func confirmHead(client *EnhancedClient, head uint64) {
+	client.SetHead(head)
+	client.SetHead(head)
+}
+
+func TestIsFinalizedAtMarginBoundary(t *testing.T) {
+	client := NewEnhancedClient("http://localhost", 10*time.Second, WithFinalityMargin(10))
+
+	// Nothing is final before a head is known
+	assert.False(t, client.IsFinalized("0x1"))
+
+	confirmHead(client, 100)
+	assert.Equal(t, uint64(100), client.Head())
+
+	// Block 90 is exactly margin blocks behind the head
+	assert.True(t, client.IsFinalized("0x5a"))
+	assert.False(t, client.IsFinalized("0x5b"))
+	assert.False(t, client.IsFinalized("latest"))
+	assert.False(t, client.IsFinalized("pending"))
+
+	// Older heads must not rewind the recorded head
+	client.SetHead(50)
+	assert.Equal(t, uint64(100), client.Head())
+
+	client.SetHead(101)
+	assert.True(t, client.IsFinalized("0x5b"))
+}
+
+func TestSetHeadRejectsImplausibleJumps(t *testing.T) {
+	client := NewEnhancedClient("http://localhost", 10*time.Second, WithFinalityMargin(10), WithMaxHeadJump(100))
+
+	// The first head has nothing to be compared against, so a bogus one must
+	// be confirmed before it can become the baseline
+	client.SetHead(math.MaxUint64)
+	assert.Equal(t, uint64(0), client.Head())
+	assert.False(t, client.IsFinalized("0x1000"))
+
+	// A genuine first head is confirmed by the next report
+	client.SetHead(1000)
+	assert.Equal(t, uint64(0), client.Head())
+	client.SetHead(1100)
+	assert.Equal(t, uint64(1100), client.Head())
+
+	// A single bogus head must not make unfinalized blocks final
+	client.SetHead(math.MaxUint64)
+	assert.Equal(t, uint64(1100), client.Head())
+	assert.False(t, client.IsFinalized("0x1000"))
+
+	// An unconfirmed jump is not accepted on the strength of a different one
+	client.SetHead(5000)
+	assert.Equal(t, uint64(1100), client.Head())
+
+	// A genuine jump, such as after a long outage, is confirmed by the next report
+	client.SetHead(5002)
+	assert.Equal(t, uint64(5002), client.Head())
+}
+
+func TestSetHeadJumpCheckCanBeDisabled(t *testing.T) {
+	client := NewEnhancedClient("http://localhost", 10*time.Second, WithMaxHeadJump(0))
+
+	client.SetHead(100)
+	assert.Equal(t, uint64(100), client.Head())
+	client.SetHead(1_000_000)
+	assert.Equal(t, uint64(1_000_000), client.Head())
+}
+
+func TestGetTransactionByHash(t *testing.T) {
+	hash := "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"
+
+	// Create a mock server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(`{
+			"jsonrpc":"2.0",
+			"id":1,
+			"result":{
+				"blockHash":"0x1d59ff54b1eb26b013ce3cb5fc9dab3705b415a67127a003c3e61eb445bb8df2",
+				"blockNumber":"0x5daf3b",
+				"from":"0xa7d9ddbe1f17865597fbd27ec712455208b6b76d",
+				"gas":"0xc350",
+				"gasPrice":"0x4a817c800",
+				"hash":"0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b",
+				"input":"0x68656c6c6f21",
+				"nonce":"0x15",
+				"to":"0xf02c1c8e6114b1dbe8937a39260b5b0a374432bb",
+				"transactionIndex":"0x41",
+				"value":"0xf3dbb76162000",
+				"type":"0x0"
+			}
+		}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	tx, err := client.GetTransactionByHash(context.Background(), hash)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, tx.Hash)
+	assert.Equal(t, "0xa7d9ddbe1f17865597fbd27ec712455208b6b76d", tx.From)
+}
+
+func TestGetTransactionByHashNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	_, err := client.GetTransactionByHash(context.Background(), "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b")
+	assert.True(t, errors.IsType(err, errors.ErrTypeNotFound))
+}
+
+func TestGetTransactionReceipt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(`{
+			"jsonrpc":"2.0",
+			"id":1,
+			"result":{
+				"transactionHash":"0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b",
+				"blockNumber":"0x5daf3b",
+				"status":"0x1",
+				"gasUsed":"0x5208",
+				"cumulativeGasUsed":"0x33bc",
+				"effectiveGasPrice":"0x4a817c800",
+				"contractAddress":null,
+				"logsBloom":"0x00",
+				"logs":[{
+					"address":"0xc2132d05d31c914a87c6611c10748aeb04b58e8f",
+					"topics":["0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"],
+					"data":"0x01",
+					"blockNumber":"0x5daf3b",
+					"logIndex":"0x0"
+				}]
+			}
+		}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	receipt, err := client.GetTransactionReceipt(context.Background(), "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", receipt.Status)
+	assert.Equal(t, "0x5208", receipt.GasUsed)
+	assert.Empty(t, receipt.ContractAddress)
+	assert.Len(t, receipt.Logs, 1)
+	assert.Equal(t, "0xc2132d05d31c914a87c6611c10748aeb04b58e8f", receipt.Logs[0].Address)
+	assert.Len(t, receipt.Logs[0].Topics, 1)
+}
+
+func TestGetTransactionReceiptPending(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	_, err := client.GetTransactionReceipt(context.Background(), "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b")
+	assert.True(t, errors.IsType(err, errors.ErrTypeNotFound))
+}
+
+func TestContextCancellationAbortsBlockRequests(t *testing.T) {
+	// The node never answers; it only reports when the client hangs up
+	aborted := make(chan struct{}, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.Copy(io.Discard, r.Body)
+		assert.NoError(t, err)
+
+		<-r.Context().Done()
+		aborted <- struct{}{}
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithRetry(RetryConfig{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.GetLatestBlockNumberCtx(ctx)
+	assert.Error(t, err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.GetBlockByNumberCtx(ctx, "0x1")
+	assert.Error(t, err)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-aborted:
+		case <-time.After(time.Second):
+			t.Fatal("in-flight request was not aborted")
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package rpc
+
+import (
+	"context"
+
+	"blockchain-client/pkg/errors"
+)
+
+// WithMaxConcurrency caps how many HTTP requests the client has in flight to
+// the node at once, since public endpoints throttle clients that open too
+// many. Callers over the limit wait for a free slot, unless their context ends
+// first. A non-positive limit removes the cap.
+func WithMaxConcurrency(limit int) Option {
+	return func(c *EnhancedClient) {
+		if limit <= 0 {
+			c.slots = nil
+			return
+		}
+		c.slots = make(chan struct{}, limit)
+	}
+}
+
+// acquireSlot blocks until a request may be sent or ctx is done. A nil error
+// must be followed by releaseSlot once the request completes.
+func (c *EnhancedClient) acquireSlot(ctx context.Context) error {
+	if c.slots != nil {
+		select {
+		case c.slots <- struct{}{}:
+		case <-ctx.Done():
+			return errors.NewTimeoutError("RPC request timed out waiting for a free request slot", ctx.Err())
+		}
+	}
+	c.inFlight.Inc()
+	return nil
+}
+
+// releaseSlot frees the slot taken by acquireSlot
+func (c *EnhancedClient) releaseSlot() {
+	c.inFlight.Dec()
+	if c.slots != nil {
+		<-c.slots
+	}
+}
@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxConcurrency(t *testing.T) {
+	var current, peak int32
+	release := make(chan struct{})
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		defer atomic.AddInt32(&current, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		<-release
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+		assert.NoError(t, err)
+	}))
+	defer node.Close()
+
+	client := NewEnhancedClient(node.URL, 10*time.Second, WithMaxConcurrency(2), WithName("concurrency-test"))
+	gauge := metrics.RPCInFlightRequests.WithLabelValues("concurrency-test")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetLatestBlockNumber()
+			assert.NoError(t, err)
+		}()
+	}
+
+	// Only two requests reach the node while the rest wait for a slot
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&current) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, float64(2), testutil.ToFloat64(gauge))
+
+	// A caller whose context ends while waiting gets a timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.GetLatestBlockNumberCtx(ctx)
+	assert.ErrorIs(t, err, errors.ErrTimeout)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&peak))
+	assert.Equal(t, float64(0), testutil.ToFloat64(gauge))
+}
@@ -0,0 +1,80 @@
+package rpc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"blockchain-client/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// DumpConfig configures dumping of complete outbound HTTP exchanges
+type DumpConfig struct {
+	// Writer receives the dumps. Use a rotating writer such as a
+	// lumberjack.Logger to bound disk usage.
+	Writer io.Writer
+	// SampleRate is the fraction of attempts, from 0 to 1, that are dumped
+	SampleRate float64
+}
+
+// WithRequestDump writes a sample of outbound HTTP requests and their
+// responses, headers and bodies included, to cfg.Writer for debugging
+// provider behaviour. Credentials in URLs and headers are redacted. A nil
+// writer or a non-positive sample rate disables dumping.
+func WithRequestDump(cfg DumpConfig) Option {
+	return func(c *EnhancedClient) {
+		if cfg.Writer == nil || cfg.SampleRate <= 0 {
+			c.dumper = nil
+			return
+		}
+		c.dumper = &requestDumper{config: cfg}
+	}
+}
+
+// requestDumper serializes dumps so concurrent exchanges do not interleave
+type requestDumper struct {
+	config DumpConfig
+	mu     sync.Mutex
+}
+
+// sample reports whether the next attempt should be dumped. It is safe to
+// call on a nil dumper, which never samples.
+func (d *requestDumper) sample() bool {
+	return d != nil && rand.Float64() < d.config.SampleRate
+}
+
+// dump writes one exchange. resp and respBody are nil when the request failed
+// with transportErr before a response arrived.
+func (d *requestDumper) dump(ep *endpoint, method string, req *http.Request, reqBody []byte, resp *http.Response, respBody []byte, transportErr error, elapsed time.Duration) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "=== %s method=%s elapsed=%s\n",
+		time.Now().UTC().Format(time.RFC3339Nano), method, elapsed)
+
+	fmt.Fprintf(&buf, "%s %s %s\n", req.Method, ep.display, req.Proto)
+	redactHeaders(req.Header).Write(&buf)
+	buf.WriteString("\n")
+	buf.Write(reqBody)
+	buf.WriteString("\n\n")
+
+	if resp == nil {
+		fmt.Fprintf(&buf, "--- error: %v\n\n", transportErr)
+	} else {
+		fmt.Fprintf(&buf, "%s %s\n", resp.Proto, resp.Status)
+		redactHeaders(resp.Header).Write(&buf)
+		buf.WriteString("\n")
+		buf.Write(respBody)
+		buf.WriteString("\n\n")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, err := d.config.Writer.Write(buf.Bytes()); err != nil {
+		logger.Warn("Failed to write RPC request dump", zap.String("method", method), zap.Error(err))
+	}
+}
@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestDump(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "cookie-secret"})
+		w.Header().Set("X-Node", "archive-1")
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x2a"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	// Credentials in the userinfo, the query string and a custom header
+	rpcURL := strings.Replace(server.URL, "http://", "http://user:userinfo-secret@", 1)
+
+	var dump bytes.Buffer
+	client := NewClient(rpcURL,
+		WithAPIKeyParam("apikey", "query-secret"),
+		WithHeaders(map[string]string{"Authorization": "Bearer header-secret", "X-Client": "tests"}),
+		WithRequestDump(DumpConfig{Writer: &dump, SampleRate: 1}))
+
+	_, err := client.GetLatestBlockNumber()
+	require.NoError(t, err)
+
+	written := dump.String()
+	assert.Contains(t, written, "method=eth_blockNumber")
+	assert.Contains(t, written, `"method":"eth_blockNumber"`)
+	assert.Contains(t, written, `"result":"0x2a"`)
+	assert.Contains(t, written, "200 OK")
+	assert.Contains(t, written, "X-Client: tests")
+	assert.Contains(t, written, "X-Node: archive-1")
+	assert.Contains(t, written, "apikey=REDACTED")
+	assert.Contains(t, written, "Authorization: REDACTED")
+	assert.Contains(t, written, "Set-Cookie: REDACTED")
+
+	for _, secret := range []string{"userinfo-secret", "query-secret", "header-secret", "cookie-secret"} {
+		assert.NotContains(t, written, secret)
+	}
+
+	// Unsampled requests are not written
+	dump.Reset()
+	client = NewClient(server.URL, WithRequestDump(DumpConfig{Writer: &dump, SampleRate: 0}))
+	_, err = client.GetLatestBlockNumber()
+	require.NoError(t, err)
+	assert.Empty(t, dump.String())
+}
@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// endpoint is an RPC URL together with its last observed health
+type endpoint struct {
+	url string
+	// display is the URL with any API key redacted, for logs and status reports
+	display string
+	// header holds the static headers and credentials sent to this endpoint only
+	header http.Header
+
+	mu        sync.Mutex
+	healthy   bool
+	lastError error
+	checkedAt time.Time
+}
+
+// WithEndpoints adds fallback endpoints, tried in order after the primary URL
+// when it fails with a network error, timeout or 5xx response. Fallbacks may
+// belong to other providers, so the primary endpoint's path, API key and
+// credentials are never sent to them; each URL carries its own.
+func WithEndpoints(fallbackURLs ...string) Option {
+	return func(c *EnhancedClient) {
+		c.fallbackURLs = append(c.fallbackURLs, fallbackURLs...)
+	}
+}
+
+// EndpointStatus describes the last observed health of an RPC endpoint
+type EndpointStatus struct {
+	URL       string
+	Healthy   bool
+	LastError string
+	CheckedAt time.Time
+}
+
+// newEndpoint creates an endpoint that is assumed healthy until proven otherwise
+func newEndpoint(url string) *endpoint {
+	return &endpoint{url: url, display: url, healthy: true}
+}
+
+// record stores the outcome of a request to the endpoint
+func (e *endpoint) record(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.healthy = err == nil
+	e.lastError = err
+	e.checkedAt = time.Now()
+}
+
+// status returns a snapshot of the endpoint's health
+func (e *endpoint) status() EndpointStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	status := EndpointStatus{URL: e.display, Healthy: e.healthy, CheckedAt: e.checkedAt}
+	if e.lastError != nil {
+		status.LastError = e.lastError.Error()
+	}
+	return status
+}
+
+// EndpointStatuses reports the last observed health of every configured endpoint
+func (c *EnhancedClient) EndpointStatuses() []EndpointStatus {
+	statuses := make([]EndpointStatus, len(c.endpoints))
+	for i, ep := range c.endpoints {
+		statuses[i] = ep.status()
+	}
+	return statuses
+}
+
+// shouldFailover reports whether a failed exchange should be tried against the
+// next endpoint: network errors and timeouts (no status) and 5xx responses
+func shouldFailover(status int) bool {
+	return status == 0 || status >= 500
+}
+
+// sendWithFailover sends a payload to the preferred endpoint, moving on to the
+// next one on network errors, timeouts and 5xx responses. The endpoint that
+// answers becomes the preferred one for subsequent calls. Payloads that are
+// not idempotent are only sent to the preferred endpoint.
+func (c *EnhancedClient) sendWithFailover(ctx context.Context, method string, idempotent bool, payload []byte) ([]byte, int, time.Duration, error) {
+	count := len(c.endpoints)
+	start := int(c.preferred.Load())
+
+	var failures []string
+	var lastStatus int
+	var lastRetryAfter time.Duration
+	var lastErr error
+
+	for i := 0; i < count; i++ {
+		index := (start + i) % count
+		ep := c.endpoints[index]
+
+		bodyBytes, status, retryAfter, err := c.send(ctx, ep, method, payload)
+		ep.record(err)
+
+		if err == nil {
+			if index != start {
+				c.preferred.Store(int32(index))
+				logger.Info("Switched preferred RPC endpoint", zap.String("url", ep.display))
+			}
+			logger.Debug("RPC request served by endpoint",
+				zap.String("method", method),
+				zap.String("url", ep.display))
+			return bodyBytes, status, retryAfter, nil
+		}
+
+		// Errors that another endpoint would reproduce are returned as-is
+		if ctx.Err() != nil || !shouldFailover(status) || !idempotent || count == 1 {
+			return nil, status, retryAfter, err
+		}
+
+		logger.Warn("RPC endpoint failed, trying next endpoint",
+			zap.String("method", method),
+			zap.String("url", ep.display),
+			zap.Error(err))
+
+		failures = append(failures, fmt.Sprintf("%s: %v", ep.display, err))
+		lastStatus, lastRetryAfter, lastErr = status, retryAfter, err
+	}
+
+	return nil, lastStatus, lastRetryAfter, errors.NewBlockchainError(
+		fmt.Sprintf("All %d RPC endpoints failed: %s", count, strings.Join(failures, "; ")), lastErr).
+		WithData(map[string]interface{}{"endpoints": failures})
+}
@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"blockchain-client/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailoverToNextEndpoint(t *testing.T) {
+	var primaryCalls, backupCalls int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryCalls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&backupCalls, 1)
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+		assert.NoError(t, err)
+	}))
+	defer backup.Close()
+
+	client := NewEnhancedClientWithEndpoints([]string{primary.URL, backup.URL}, 10*time.Second)
+
+	blockNumber, err := client.GetLatestBlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, "0x10", blockNumber)
+
+	statuses := client.EndpointStatuses()
+	assert.False(t, statuses[0].Healthy)
+	assert.True(t, statuses[1].Healthy)
+
+	// The backup is now preferred, so the primary is not tried again
+	_, err = client.GetLatestBlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&primaryCalls))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&backupCalls))
+}
+
+func TestFailoverAllEndpointsFail(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	// A closed server produces a network error
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+
+	client := NewEnhancedClientWithEndpoints([]string{failing.URL, closed.URL}, 10*time.Second)
+
+	_, err := client.GetLatestBlockNumber()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), failing.URL)
+	assert.Contains(t, err.Error(), closed.URL)
+}
+
+func TestNoFailoverOnClientError(t *testing.T) {
+	var backupCalls int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer primary.Close()
+
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&backupCalls, 1)
+	}))
+	defer backup.Close()
+
+	client := NewEnhancedClientWithEndpoints([]string{primary.URL, backup.URL}, 10*time.Second)
+
+	_, err := client.GetLatestBlockNumber()
+	assert.Error(t, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&backupCalls))
+}
+
+func TestNoFailoverForTransactionSubmission(t *testing.T) {
+	var primaryCalls, backupCalls int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryCalls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer primary.Close()
+
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&backupCalls, 1)
+	}))
+	defer backup.Close()
+
+	client := NewEnhancedClientWithEndpoints([]string{primary.URL, backup.URL}, 10*time.Second,
+		WithRetry(testRetryConfig()))
+
+	// The primary may have broadcast the transaction before failing, so it is
+	// neither resent to the backup nor retried
+	_, err := client.SendRawTransaction(context.Background(), "0x02f870")
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&primaryCalls))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&backupCalls))
+
+	// The same holds when the method is proxied
+	_, err = client.RawCall(context.Background(), models.RawRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_sendRawTransaction",
+		Params:  json.RawMessage(`["0x02f870"]`),
+		ID:      json.RawMessage(`1`),
+	})
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&primaryCalls))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&backupCalls))
+
+	// Batches containing a submission are sent once too
+	_, err = client.BatchCall(context.Background(), []models.RPCRequest{
+		{JSONRPC: "2.0", Method: "eth_blockNumber", ID: 1},
+		{JSONRPC: "2.0", Method: "eth_sendRawTransaction", Params: []interface{}{"0x02f870"}, ID: 2},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&primaryCalls))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&backupCalls))
+}
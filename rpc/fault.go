@@ -0,0 +1,61 @@
+package rpc
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// FaultConfig describes synthetic faults injected into outbound RPC attempts
+// for chaos testing in staging. It must never be enabled in production.
+type FaultConfig struct {
+	// Latency is added before every attempt
+	Latency time.Duration
+	// ErrorRate is the fraction of attempts, from 0 to 1, answered with a
+	// synthetic 503 instead of reaching the node
+	ErrorRate float64
+	// DropRate is the fraction of attempts, from 0 to 1, failed as if the
+	// connection had been lost
+	DropRate float64
+}
+
+// WithFaultInjection injects the configured latency and failures into every
+// RPC attempt, exercising timeouts, retries and failover against a healthy node
+func WithFaultInjection(cfg FaultConfig) Option {
+	return func(c *EnhancedClient) {
+		c.faults = &cfg
+	}
+}
+
+// injectFault applies the configured faults to one attempt, returning the
+// status and error to fail it with, or a nil error to let it proceed
+func (c *EnhancedClient) injectFault(ctx context.Context, method string) (int, error) {
+	if c.faults.Latency > 0 {
+		timer := time.NewTimer(c.faults.Latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, errors.NewTimeoutError("RPC request timed out during injected latency", ctx.Err())
+		case <-timer.C:
+		}
+	}
+
+	// A single draw keeps the two rates independent of each other
+	roll := rand.Float64()
+	switch {
+	case roll < c.faults.DropRate:
+		logger.Debug("Injected dropped RPC request", zap.String("method", method))
+		return 0, errors.NewInternalError("Failed to execute HTTP request: injected connection drop", nil)
+	case roll < c.faults.DropRate+c.faults.ErrorRate:
+		logger.Debug("Injected RPC error", zap.String("method", method))
+		return http.StatusServiceUnavailable, errors.NewBlockchainError("RPC server returned non-200 response: 503 (injected fault)", nil).
+			WithData(map[string]interface{}{"status_code": http.StatusServiceUnavailable})
+	}
+	return 0, nil
+}
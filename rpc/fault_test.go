@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFaultInjectionRates(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second,
+		WithRetry(RetryConfig{}),
+		WithFaultInjection(FaultConfig{ErrorRate: 0.2, DropRate: 0.1}))
+
+	const requests = 2000
+	failures := 0
+	for i := 0; i < requests; i++ {
+		if _, err := client.GetLatestBlockNumber(); err != nil {
+			failures++
+		}
+	}
+
+	// 30% of requests should fail, within a generous tolerance for randomness
+	assert.InDelta(t, 0.3, float64(failures)/requests, 0.05)
+
+	// Failed attempts never reach the node
+	assert.Equal(t, int32(requests-failures), atomic.LoadInt32(&calls))
+}
+
+func TestFaultInjectionLatency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second,
+		WithFaultInjection(FaultConfig{Latency: 30 * time.Millisecond}))
+
+	start := time.Now()
+	_, err := client.GetLatestBlockNumber()
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+
+	// Latency beyond the client timeout surfaces as a timeout
+	client = NewEnhancedClient(server.URL, 10*time.Millisecond,
+		WithRetry(RetryConfig{}),
+		WithFaultInjection(FaultConfig{Latency: time.Second}))
+
+	_, err = client.GetLatestBlockNumber()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+}
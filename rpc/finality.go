@@ -0,0 +1,110 @@
+package rpc
+
+import (
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// DefaultFinalityMargin is the number of blocks a block must trail the chain
+// head by before it is treated as final. Polygon has seen reorgs well beyond
+// Ethereum's two-epoch window, so the default errs on the deep side.
+const DefaultFinalityMargin uint64 = 128
+
+// DefaultMaxHeadJump is how far above the recorded head a new head may be
+// before it needs confirming, roughly an hour of Polygon blocks
+const DefaultMaxHeadJump uint64 = 1800
+
+// WithFinalityMargin sets how many blocks behind the head a block must be
+// before it is considered final and therefore safe to cache
+func WithFinalityMargin(margin uint64) Option {
+	return func(c *EnhancedClient) {
+		c.finalityMargin = margin
+	}
+}
+
+// WithMaxHeadJump sets how many blocks above the recorded head a new head may
+// be before it is treated as implausible. Such a head, like the first head
+// reported, is only accepted once a second report confirms it, so a single
+// bogus head from a misbehaving node cannot mark unfinalized blocks as final.
+// Zero disables the check.
+func WithMaxHeadJump(jump uint64) Option {
+	return func(c *EnhancedClient) {
+		c.maxHeadJump = jump
+	}
+}
+
+// SetHead records the latest known chain head. Finality decisions are based on
+// this value instead of querying the node, so callers that already track the
+// head (such as the latest block handler) should feed it here. Heads lower
+// than the current value are ignored so out-of-order updates cannot rewind it.
+// The first head, and heads more than maxHeadJump above the current value,
+// are held back until a later report lands within maxHeadJump of them. Each new head is also queued
+// for cache warming when it is enabled.
+func (c *EnhancedClient) SetHead(head uint64) {
+	for {
+		current := c.head.Load()
+		if head <= current {
+			return
+		}
+		if !c.plausibleHead(current, head) {
+			return
+		}
+		if c.head.CompareAndSwap(current, head) {
+			logger.Debug("Updated chain head", zap.Uint64("head", head))
+			c.warmer.notify(head)
+			return
+		}
+	}
+}
+
+// plausibleHead reports whether head may replace current. The first head has
+// nothing to be compared against, so like an implausible jump it is
+// remembered as a candidate and only accepted when the next report not
+// within maxHeadJump of current is at or above it and within maxHeadJump of it.
+func (c *EnhancedClient) plausibleHead(current, head uint64) bool {
+	if c.maxHeadJump == 0 || (current != 0 && head-current <= c.maxHeadJump) {
+		return true
+	}
+
+	candidate := c.headCandidate.Swap(head)
+	if candidate != 0 && head >= candidate && head-candidate <= c.maxHeadJump {
+		return true
+	}
+
+	if current == 0 {
+		logger.Debug("Holding back first chain head until it is confirmed", zap.Uint64("head", head))
+		return false
+	}
+	logger.Warn("Ignoring implausible chain head until it is confirmed",
+		zap.Uint64("head", head),
+		zap.Uint64("current_head", current),
+		zap.Uint64("max_head_jump", c.maxHeadJump))
+	return false
+}
+
+// Head returns the latest chain head recorded with SetHead, or 0 if unknown
+func (c *EnhancedClient) Head() uint64 {
+	return c.head.Load()
+}
+
+// IsFinalized reports whether a formatted block number is at least
+// finalityMargin blocks behind the recorded head. Block tags such as "latest"
+// or "pending" are never final, and nothing is final until a head is known.
+func (c *EnhancedClient) IsFinalized(blockNumber string) bool {
+	head := c.head.Load()
+	if head == 0 {
+		return false
+	}
+
+	number, err := hexutil.DecodeUint64(blockNumber)
+	if err != nil {
+		return false
+	}
+
+	if head < c.finalityMargin {
+		return false
+	}
+	return number <= head-c.finalityMargin
+}
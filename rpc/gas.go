@@ -0,0 +1,111 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// methodNotFoundCode is the JSON-RPC error code for an unknown method
+const methodNotFoundCode = -32601
+
+// unsupportedMethodMessages are fragments of the errors nodes return for
+// methods they do not offer without using the standard error code
+var unsupportedMethodMessages = []string{
+	"method not found",
+	"method not supported",
+	"unsupported method",
+	"does not exist/is not available",
+}
+
+// GasPrice returns the node's suggested legacy gas price in wei as a hex quantity
+func (c *EnhancedClient) GasPrice(ctx context.Context) (string, error) {
+	return c.getGasQuantity(ctx, "eth_gasPrice")
+}
+
+// MaxPriorityFeePerGas returns the node's suggested EIP-1559 priority fee in
+// wei as a hex quantity. Nodes for chains without EIP-1559 reject the method,
+// which is reported as an errors.ErrTypeUnsupported error.
+func (c *EnhancedClient) MaxPriorityFeePerGas(ctx context.Context) (string, error) {
+	return c.getGasQuantity(ctx, "eth_maxPriorityFeePerGas")
+}
+
+// FeeHistory returns the base fees, gas usage and priority fees at the given
+// percentiles for blockCount blocks ending at newestBlock, using eth_feeHistory.
+// Nodes for chains without EIP-1559 reject the method, which is reported as an
+// errors.ErrTypeUnsupported error.
+func (c *EnhancedClient) FeeHistory(ctx context.Context, blockCount uint64, newestBlock string, rewardPercentiles []float64) (*models.FeeHistory, error) {
+	newestBlock, err := normalizeBlockTag(newestBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create JSON-RPC request
+	requestBody := models.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_feeHistory",
+		Params:  []interface{}{hexutil.EncodeUint64(blockCount), newestBlock, rewardPercentiles},
+		ID:      1,
+	}
+
+	var response models.FeeHistoryResponse
+	err = c.doRequestCtx(ctx, requestBody, &response)
+	if err != nil {
+		if errors.IsType(err, errors.ErrTypeUnsupported) {
+			return nil, err
+		}
+		logger.Error("Failed to get fee history",
+			zap.Uint64("block_count", blockCount),
+			zap.String("newest_block", newestBlock),
+			zap.Error(err))
+		return nil, errors.NewBlockchainError("Failed to get fee history", err)
+	}
+
+	if response.Result == nil {
+		return nil, errors.NewBlockchainError("Node returned no fee history", nil)
+	}
+	return response.Result, nil
+}
+
+// getGasQuantity calls a parameterless gas pricing method returning a quantity
+func (c *EnhancedClient) getGasQuantity(ctx context.Context, method string) (string, error) {
+	// Create JSON-RPC request
+	requestBody := models.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		ID:      1,
+	}
+
+	var response models.StringResponse
+	err := c.doRequestCtx(ctx, requestBody, &response)
+	if err != nil {
+		if errors.IsType(err, errors.ErrTypeUnsupported) {
+			return "", err
+		}
+		logger.Error("Failed to get gas price", zap.String("method", method), zap.Error(err))
+		return "", errors.NewBlockchainError("Failed to get gas price", err).
+			WithData(map[string]interface{}{"method": method})
+	}
+
+	return response.Result, nil
+}
+
+// isUnsupportedMethod reports whether an RPC error means the node does not offer the method
+func isUnsupportedMethod(rpcErr models.RPCError) bool {
+	if rpcErr.Code == methodNotFoundCode {
+		return true
+	}
+	message := strings.ToLower(rpcErr.Message)
+	for _, fragment := range unsupportedMethodMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
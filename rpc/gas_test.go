@@ -0,0 +1,105 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blockchain-client/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newGasNode serves eth_gasPrice and answers eth_maxPriorityFeePerGas with priorityFee
+func newGasNode(t *testing.T, priorityFee string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string `json:"method"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		response := `{"jsonrpc":"2.0","id":1,"result":"0x3b9aca00"}`
+		if request.Method == "eth_maxPriorityFeePerGas" {
+			response = priorityFee
+		}
+		_, err := w.Write([]byte(response))
+		assert.NoError(t, err)
+	}))
+}
+
+func TestGasPrice(t *testing.T) {
+	server := newGasNode(t, `{"jsonrpc":"2.0","id":1,"result":"0x59682f00"}`)
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	gasPrice, err := client.GasPrice(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "0x3b9aca00", gasPrice)
+
+	priorityFee, err := client.MaxPriorityFeePerGas(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "0x59682f00", priorityFee)
+}
+
+func TestMaxPriorityFeePerGasUnsupported(t *testing.T) {
+	rejections := []string{
+		`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"the method eth_maxPriorityFeePerGas does not exist/is not available"}}`,
+		`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"Method not supported"}}`,
+	}
+	for _, rejection := range rejections {
+		server := newGasNode(t, rejection)
+		client := NewEnhancedClient(server.URL, 10*time.Second)
+
+		_, err := client.MaxPriorityFeePerGas(context.Background())
+		assert.True(t, errors.IsType(err, errors.ErrTypeUnsupported), rejection)
+		server.Close()
+	}
+
+	// Other node errors are not mistaken for an unsupported method
+	server := newGasNode(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"header not found"}}`)
+	defer server.Close()
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	_, err := client.MaxPriorityFeePerGas(context.Background())
+	assert.True(t, errors.IsType(err, errors.ErrorTypeBlockchain))
+}
+
+func TestFeeHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "eth_feeHistory", request.Method)
+		if assert.Len(t, request.Params, 3) {
+			assert.JSONEq(t, `"0x2"`, string(request.Params[0]))
+			assert.JSONEq(t, `"latest"`, string(request.Params[1]))
+			assert.JSONEq(t, `[10, 50]`, string(request.Params[2]))
+		}
+
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{
+			"oldestBlock":"0x10",
+			"baseFeePerGas":["0x3b9aca00","0x3b9aca01","0x3b9aca02"],
+			"gasUsedRatio":[0.5,0.25],
+			"reward":[["0x1","0x2"],["0x3","0x4"]]}}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	history, err := client.FeeHistory(context.Background(), 2, "", []float64{10, 50})
+	assert.NoError(t, err)
+	assert.Equal(t, "0x10", history.OldestBlock)
+	assert.Equal(t, []string{"0x3b9aca00", "0x3b9aca01", "0x3b9aca02"}, history.BaseFeePerGas)
+	assert.Equal(t, []float64{0.5, 0.25}, history.GasUsedRatio)
+	assert.Equal(t, [][]string{{"0x1", "0x2"}, {"0x3", "0x4"}}, history.Reward)
+
+	_, err = client.FeeHistory(context.Background(), 2, "0xnope", nil)
+	assert.True(t, errors.IsType(err, errors.ErrTypeValidation))
+}
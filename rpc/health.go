@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
 	"blockchain-client/pkg/logger"
 
 	"go.uber.org/zap"
@@ -14,6 +16,13 @@ import (
 
 // HealthCheck performs a health check on the RPC endpoint
 func (c *EnhancedClient) HealthCheck(ctx context.Context) (bool, string, error) {
+	status, err := c.HealthStatus(ctx)
+	return status.Healthy, status.Description, err
+}
+
+// HealthStatus performs a health check on the RPC endpoint, returning the network
+// it is connected to. An unreachable endpoint yields an unhealthy status and an error.
+func (c *EnhancedClient) HealthStatus(ctx context.Context) (*models.HealthStatus, error) {
 	logger.Debug("Performing RPC health check")
 	
 	// Create a context with timeout for health check
@@ -24,24 +33,131 @@ func (c *EnhancedClient) HealthCheck(ctx context.Context) (bool, string, error)
 	healthy, details, err := c.checkNetVersion(checkCtx)
 	if err != nil {
 		logger.Warn("RPC health check failed", zap.Error(err))
-		return false, "Failed to connect to RPC endpoint", err
+		return &models.HealthStatus{Description: "Failed to connect to RPC endpoint"}, err
 	}
 	
+	status := &models.HealthStatus{Healthy: healthy}
+	status.NetworkID, _ = details["networkId"].(string)
+	status.ChainName, _ = details["chainName"].(string)
+
 	// Format description
-	var description string
 	if healthy {
-		if chainName, ok := details["chainName"].(string); ok && chainName != "" {
-			description = fmt.Sprintf("Connected to %s (Network ID: %s)", 
-				chainName, details["networkId"])
+		if status.ChainName != "" {
+			status.Description = fmt.Sprintf("Connected to %s (Network ID: %s)", 
+				status.ChainName, status.NetworkID)
 		} else {
-			description = fmt.Sprintf("Connected to RPC endpoint (Network ID: %s)", 
-				details["networkId"])
+			status.Description = fmt.Sprintf("Connected to RPC endpoint (Network ID: %s)", 
+				status.NetworkID)
 		}
 	} else {
-		description = "Unhealthy RPC connection"
+		status.Description = "Unhealthy RPC connection"
+	}
+
+	if healthy {
+		c.addNodeInfo(checkCtx, status)
 	}
 	
-	return healthy, description, nil
+	return status, nil
+}
+
+// addNodeInfo adds the node's client version and peer count to a healthy
+// status. Many providers do not offer them, so failing to get them does not
+// fail the check. A node without peers still answers but cannot follow the
+// chain, so it gets a warning.
+func (c *EnhancedClient) addNodeInfo(ctx context.Context, status *models.HealthStatus) {
+	if version, err := c.ClientVersion(ctx); err == nil {
+		status.ClientVersion = version
+	} else {
+		logger.Debug("Client version not available for health check", zap.Error(err))
+	}
+
+	peers, err := c.PeerCount(ctx)
+	if err != nil {
+		logger.Debug("Peer count not available for health check", zap.Error(err))
+		return
+	}
+	status.PeerCount = &peers
+	if peers == 0 {
+		status.Warning = "Node has no peers and may be out of sync"
+		status.Description = fmt.Sprintf("Warning: %s, but it has no peers", status.Description)
+		logger.Warn("RPC node has no peers", zap.String("network_id", status.NetworkID))
+	}
+}
+
+// PeerCount returns the number of peers the node is connected to
+func (c *EnhancedClient) PeerCount(ctx context.Context) (uint64, error) {
+	requestBody := models.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  "net_peerCount",
+		ID:      1,
+	}
+
+	var response models.StringResponse
+	if err := c.doRequestCtx(ctx, requestBody, &response); err != nil {
+		if errors.IsType(err, errors.ErrTypeUnsupported) {
+			return 0, err
+		}
+		return 0, errors.NewBlockchainError("Failed to get peer count", err)
+	}
+
+	// The count is a hex quantity such as "0x19"
+	peers, err := hexutil.DecodeUint64(response.Result)
+	if err != nil {
+		return 0, errors.NewBlockchainError("Node returned a malformed peer count", err).
+			WithData(map[string]interface{}{"peer_count": response.Result})
+	}
+	return peers, nil
+}
+
+// ClientVersion returns the node's client software and version, such as
+// "Geth/v1.13.5-stable/linux-amd64/go1.21.4"
+func (c *EnhancedClient) ClientVersion(ctx context.Context) (string, error) {
+	requestBody := models.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  "web3_clientVersion",
+		ID:      1,
+	}
+
+	var response models.StringResponse
+	if err := c.doRequestCtx(ctx, requestBody, &response); err != nil {
+		if errors.IsType(err, errors.ErrTypeUnsupported) {
+			return "", err
+		}
+		return "", errors.NewBlockchainError("Failed to get client version", err)
+	}
+	return response.Result, nil
+}
+
+// ChainID returns the EIP-155 chain ID of the connected network as a hex quantity
+func (c *EnhancedClient) ChainID(ctx context.Context) (string, error) {
+	requestBody := models.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_chainId",
+		ID:      1,
+	}
+
+	var response models.StringResponse
+	if err := c.doRequestCtx(ctx, requestBody, &response); err != nil {
+		if errors.IsType(err, errors.ErrTypeUnsupported) {
+			return "", err
+		}
+		logger.Warn("Failed to get chain ID", zap.Error(err))
+		return "", errors.NewBlockchainError("Failed to get chain ID", err)
+	}
+	if response.Result == "" {
+		return "", errors.NewBlockchainError("Node returned an empty chain ID", nil)
+	}
+	return response.Result, nil
+}
+
+// ChainName returns a human-readable name for a hex chain ID as returned by
+// ChainID, or "" when the chain is not known
+func ChainName(chainID string) string {
+	id, err := hexutil.DecodeBig(chainID)
+	if err != nil {
+		return ""
+	}
+	return getChainNameFromNetworkID(id.String())
 }
 
 // checkNetVersion checks the RPC connection by getting the network version
@@ -63,8 +179,8 @@ func (c *EnhancedClient) checkNetVersion(ctx context.Context) (bool, map[string]
 		} `json:"error,omitempty"`
 	}
 	
-	// Send the request with context
-	err := c.doRequestWithContext(ctx, requestBody, &response)
+	// Send the request with context so cancellation aborts the in-flight request
+	err := c.doRequestCtx(ctx, requestBody, &response)
 	if err != nil {
 		return false, nil, err
 	}
@@ -89,27 +205,6 @@ func (c *EnhancedClient) checkNetVersion(ctx context.Context) (bool, map[string]
 	return true, details, nil
 }
 
-// doRequestWithContext performs an RPC request with the provided context
-func (c *EnhancedClient) doRequestWithContext(ctx context.Context, requestBody models.RPCRequest, responseObj interface{}) error {
-	// Implementation similar to doRequest but with context
-	// [Simplified for brevity - full implementation would be similar to doRequest with context support]
-	
-	// For simplicity in this example, we'll just wrap the existing method
-	// In a real implementation, the entire HTTP request process would use the provided context
-	resultChan := make(chan error, 1)
-	
-	go func() {
-		resultChan <- c.doRequest(requestBody, responseObj)
-	}()
-	
-	select {
-	case err := <-resultChan:
-		return err
-	case <-ctx.Done():
-		return ctx.Err()
-	}
-}
-
 // getChainNameFromNetworkID returns a human-readable chain name from network ID
 func getChainNameFromNetworkID(networkID string) string {
 	switch networkID {
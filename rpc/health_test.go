@@ -0,0 +1,156 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"blockchain-client/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		peerCount     string
+		expectedPeers *uint64
+		warning       bool
+	}{
+		{"with peers", `"0x19"`, uint64Ptr(25), false},
+		{"no peers", `"0x0"`, uint64Ptr(0), true},
+		{"malformed peer count", `"25"`, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var request models.RPCRequest
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+				result := `"137"`
+				switch request.Method {
+				case "net_peerCount":
+					result = tt.peerCount
+				case "web3_clientVersion":
+					result = `"bor/v1.2.0/linux-amd64/go1.21.4"`
+				}
+				_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":` + result + `}`))
+				assert.NoError(t, err)
+			}))
+			defer server.Close()
+
+			client := NewEnhancedClient(server.URL, 10*time.Second)
+
+			status, err := client.HealthStatus(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, status.Healthy)
+			assert.Equal(t, "137", status.NetworkID)
+			assert.Equal(t, "Polygon Mainnet", status.ChainName)
+			assert.Equal(t, "bor/v1.2.0/linux-amd64/go1.21.4", status.ClientVersion)
+			assert.Equal(t, tt.expectedPeers, status.PeerCount)
+			if tt.warning {
+				assert.NotEmpty(t, status.Warning)
+				assert.True(t, strings.HasPrefix(status.Description, "Warning: "))
+			} else {
+				assert.Empty(t, status.Warning)
+			}
+		})
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"1"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	healthy, description, err := client.HealthCheck(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, healthy)
+	assert.Equal(t, "Connected to Ethereum Mainnet (Network ID: 1)", description)
+}
+
+func TestPeerCount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1a"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	peers, err := client.PeerCount(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(26), peers)
+}
+
+func uint64Ptr(v uint64) *uint64 {
+	return &v
+}
+
+func TestHealthCheckCancellationAbortsRequest(t *testing.T) {
+	// The node never answers; it only reports when the client hangs up
+	aborted := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Draining the body lets the server notice the client hanging up
+		_, err := io.Copy(io.Discard, r.Body)
+		assert.NoError(t, err)
+
+		<-r.Context().Done()
+		close(aborted)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithRetry(RetryConfig{}))
+	goroutines := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	healthy, _, err := client.HealthCheck(ctx)
+	assert.Error(t, err)
+	assert.False(t, healthy)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// Cancellation must close the connection rather than leave the request running
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatal("in-flight request was not aborted")
+	}
+
+	assert.Eventually(t, func() bool {
+		return runtime.NumGoroutine() <= goroutines
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestChainID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x89"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	chainID, err := client.ChainID(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "0x89", chainID)
+}
+
+func TestChainName(t *testing.T) {
+	assert.Equal(t, "Polygon Mainnet", ChainName("0x89"))
+	assert.Equal(t, "Ethereum Mainnet", ChainName("0x1"))
+	assert.Equal(t, "", ChainName("0x7a69"))
+	assert.Equal(t, "", ChainName("137"))
+}
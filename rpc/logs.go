@@ -0,0 +1,192 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// DefaultMaxLogBlockRange is the widest block span a single GetLogs query may
+// cover. Wide log queries are among the most expensive calls a node serves.
+const DefaultMaxLogBlockRange uint64 = 5000
+
+// topicPattern matches a 0x-prefixed 32-byte hex topic
+var topicPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+
+// tooManyResultsMessages are fragments of the errors nodes and providers return
+// when a log query matches more data than they are willing to send
+var tooManyResultsMessages = []string{
+	"query returned more than",
+	"response size exceeded",
+}
+
+// WithMaxLogBlockRange caps how many blocks a GetLogs query may span, to
+// protect the upstream node. A zero limit disables the cap.
+func WithMaxLogBlockRange(blocks uint64) Option {
+	return func(c *EnhancedClient) {
+		c.maxLogBlockRange = blocks
+	}
+}
+
+// LogFilter selects logs for GetLogs. Empty block bounds mean "latest".
+// Addresses match logs emitted by any of the listed contracts. Topics are
+// matched by position: a nil or empty position matches any topic, and a
+// position with several topics matches any one of them.
+type LogFilter struct {
+	FromBlock string
+	ToBlock   string
+	Addresses []string
+	Topics    [][]string
+}
+
+// MarshalJSON encodes the filter object expected by eth_getLogs, writing a
+// single address or topic as a bare string and wildcard positions as null
+func (f LogFilter) MarshalJSON() ([]byte, error) {
+	var address interface{}
+	switch len(f.Addresses) {
+	case 0:
+	case 1:
+		address = f.Addresses[0]
+	default:
+		address = f.Addresses
+	}
+
+	var topics []interface{}
+	for _, position := range f.Topics {
+		switch len(position) {
+		case 0:
+			topics = append(topics, nil)
+		case 1:
+			topics = append(topics, position[0])
+		default:
+			topics = append(topics, position)
+		}
+	}
+
+	return json.Marshal(struct {
+		FromBlock string        `json:"fromBlock,omitempty"`
+		ToBlock   string        `json:"toBlock,omitempty"`
+		Address   interface{}   `json:"address,omitempty"`
+		Topics    []interface{} `json:"topics,omitempty"`
+	}{f.FromBlock, f.ToBlock, address, topics})
+}
+
+// GetLogs retrieves the logs matching filter with eth_getLogs. Queries the node
+// refuses for matching too many logs fail with an errors.ErrTypeTooManyResults
+// error, telling the caller to retry over a narrower block range.
+func (c *EnhancedClient) GetLogs(ctx context.Context, filter LogFilter) ([]models.Log, error) {
+	filter, err := c.validateLogFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create JSON-RPC request
+	requestBody := models.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_getLogs",
+		Params:  []interface{}{filter},
+		ID:      1,
+	}
+
+	var response models.LogsResponse
+	err = c.doRequestCtx(ctx, requestBody, &response)
+	if err != nil {
+		if errors.IsType(err, errors.ErrTypeTooManyResults) {
+			return nil, err
+		}
+		logger.Error("Failed to get logs",
+			zap.String("from_block", filter.FromBlock),
+			zap.String("to_block", filter.ToBlock),
+			zap.Error(err))
+		return nil, errors.NewBlockchainError("Failed to get logs", err)
+	}
+
+	if response.Result == nil {
+		return []models.Log{}, nil
+	}
+	return response.Result, nil
+}
+
+// validateLogFilter checks the filter's addresses, topics and block range and
+// returns it with its block bounds normalized. Every malformed field is
+// reported, not just the first.
+func (c *EnhancedClient) validateLogFilter(filter LogFilter) (LogFilter, error) {
+	var (
+		fields errors.FieldErrors
+		err    error
+	)
+	filter.FromBlock, err = normalizeBlockTag(filter.FromBlock)
+	fields.AddError("fromBlock", err)
+	filter.ToBlock, err = normalizeBlockTag(filter.ToBlock)
+	fields.AddError("toBlock", err)
+
+	for i, address := range filter.Addresses {
+		fields.AddError(fmt.Sprintf("addresses[%d]", i), validateAddress(address))
+	}
+	for i, position := range filter.Topics {
+		for j, topic := range position {
+			if !topicPattern.MatchString(topic) {
+				fields.AddError(fmt.Sprintf("topics[%d][%d]", i, j),
+					errors.NewValidationError("Topic must be a 0x-prefixed 32-byte hex string", nil).
+						WithData(map[string]interface{}{"topic": topic}))
+			}
+		}
+	}
+	if err := fields.Err(); err != nil {
+		return filter, err
+	}
+
+	// Tags are resolved against the known head so open-ended ranges are capped too
+	from, fromKnown := c.resolveBlockTag(filter.FromBlock)
+	to, toKnown := c.resolveBlockTag(filter.ToBlock)
+
+	numeric := hexQuantityPattern.MatchString(filter.FromBlock) && hexQuantityPattern.MatchString(filter.ToBlock)
+	if numeric && from > to {
+		return filter, errors.NewValidationError("fromBlock must not be after toBlock", nil).
+			WithData(map[string]interface{}{"from_block": filter.FromBlock, "to_block": filter.ToBlock})
+	}
+
+	if c.maxLogBlockRange > 0 && fromKnown && toKnown && to >= from && to-from+1 > c.maxLogBlockRange {
+		return filter, errors.NewValidationError(
+			fmt.Sprintf("Log queries may span at most %d blocks", c.maxLogBlockRange), nil).
+			WithCode(errors.CodeRangeTooLarge).
+			WithData(map[string]interface{}{"from_block": filter.FromBlock, "to_block": filter.ToBlock})
+	}
+	return filter, nil
+}
+
+// resolveBlockTag returns the block number a normalized block tag refers to,
+// using the recorded head for "latest" and "pending". It reports false when
+// the number is unknown.
+func (c *EnhancedClient) resolveBlockTag(blockTag string) (uint64, bool) {
+	switch blockTag {
+	case "earliest":
+		return 0, true
+	case "latest", "pending":
+		head := c.Head()
+		return head, head != 0
+	}
+	number, err := hexutil.DecodeUint64(blockTag)
+	return number, err == nil
+}
+
+// isTooManyResults reports whether an RPC error message means a query matched
+// more data than the node will return
+func isTooManyResults(message string) bool {
+	message = strings.ToLower(message)
+	for _, fragment := range tooManyResultsMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blockchain-client/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	usdt        = "0xc2132d05d31c914a87c6611c10748aeb04b58e8f"
+	usdc        = "0x2791bca1f2de4661ed88a30c99a7a9449aa84174"
+	transferSig = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	holderTopic = "0x000000000000000000000000a7d9ddbe1f17865597fbd27ec712455208b6b76d"
+)
+
+func TestLogFilterMarshalJSON(t *testing.T) {
+	filter := LogFilter{
+		FromBlock: "0x10",
+		ToBlock:   "0x20",
+		Addresses: []string{usdt},
+		Topics:    [][]string{{transferSig}, nil, {holderTopic, transferSig}},
+	}
+	encoded, err := json.Marshal(filter)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"fromBlock": "0x10",
+		"toBlock": "0x20",
+		"address": "`+usdt+`",
+		"topics": ["`+transferSig+`", null, ["`+holderTopic+`", "`+transferSig+`"]]
+	}`, string(encoded))
+
+	encoded, err = json.Marshal(LogFilter{Addresses: []string{usdt, usdc}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"address": ["`+usdt+`", "`+usdc+`"]}`, string(encoded))
+}
+
+func TestGetLogs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "eth_getLogs", request.Method)
+		assert.JSONEq(t, `{"fromBlock":"0x10","toBlock":"latest","address":"`+usdt+`","topics":[null,"`+holderTopic+`"]}`, string(request.Params[0]))
+
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":[{"address":"` + usdt + `","topics":["` + transferSig + `"],"data":"0x01","blockNumber":"0x11","logIndex":"0x0"}]}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	logs, err := client.GetLogs(context.Background(), LogFilter{
+		FromBlock: "0x10",
+		Addresses: []string{usdt},
+		Topics:    [][]string{nil, {holderTopic}},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+	assert.Equal(t, "0x11", logs[0].BlockNumber)
+	assert.Equal(t, []string{transferSig}, logs[0].Topics)
+}
+
+func TestGetLogsTooManyResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"query returned more than 10000 results"}}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	_, err := client.GetLogs(context.Background(), LogFilter{FromBlock: "0x0", ToBlock: "0x100"})
+	assert.True(t, errors.IsType(err, errors.ErrTypeTooManyResults))
+}
+
+func TestGetLogsValidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("RPC must not be called for an invalid filter")
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithMaxLogBlockRange(100))
+	confirmHead(client, 0x1000)
+
+	invalid := map[string]LogFilter{
+		"reversed range":   {FromBlock: "0x20", ToBlock: "0x10"},
+		"range over cap":   {FromBlock: "0x0", ToBlock: "0x64"},
+		"open range":       {FromBlock: "0xf00"},
+		"bad block tag":    {FromBlock: "yesterday"},
+		"bad address":      {Addresses: []string{usdt, "0x1234"}},
+		"short topic":      {Topics: [][]string{{"0x1234"}}},
+		"bad topic in set": {Topics: [][]string{nil, {holderTopic, "topic"}}},
+	}
+	for name, filter := range invalid {
+		_, err := client.GetLogs(context.Background(), filter)
+		assert.True(t, errors.IsType(err, errors.ErrTypeValidation), name)
+	}
+
+	// Every malformed field is reported
+	_, err := client.GetLogs(context.Background(), LogFilter{
+		FromBlock: "yesterday",
+		Addresses: []string{usdt, "0x1234"},
+		Topics:    [][]string{nil, {holderTopic, "topic"}},
+	})
+	appErr, ok := errors.IsAppError(err)
+	assert.True(t, ok, err)
+	fields, _ := appErr.Data[errors.FieldsKey].(map[string]string)
+	assert.Len(t, fields, 3)
+	assert.Contains(t, fields, "fromBlock")
+	assert.Contains(t, fields, "addresses[1]")
+	assert.Contains(t, fields, "topics[1][1]")
+}
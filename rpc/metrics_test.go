@@ -0,0 +1,125 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"blockchain-client/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// histogramSnapshot returns the sample count and sum of a histogram series
+func histogramSnapshot(t *testing.T, vec *prometheus.HistogramVec, labels ...string) (uint64, float64) {
+	var m dto.Metric
+	assert.NoError(t, vec.WithLabelValues(labels...).(prometheus.Histogram).Write(&m))
+	return m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum()
+}
+
+func TestRequestSizeObservedPerRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	countBefore, sumBefore := histogramSnapshot(t, metrics.RPCRequestBytes, "eth_blockNumber", metrics.SourceAPI)
+
+	for i := 0; i < 2; i++ {
+		_, err := client.GetLatestBlockNumber()
+		assert.NoError(t, err)
+	}
+
+	countAfter, sumAfter := histogramSnapshot(t, metrics.RPCRequestBytes, "eth_blockNumber", metrics.SourceAPI)
+	assert.Equal(t, countBefore+2, countAfter)
+
+	// {"jsonrpc":"2.0","method":"eth_blockNumber","id":1} is 51 bytes
+	assert.Equal(t, float64(2*51), sumAfter-sumBefore)
+}
+
+func TestRequestSourceLabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x0"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+	address := "0xc2132d05d31c914a87c6611c10748aeb04b58e8f"
+
+	apiBefore, _ := histogramSnapshot(t, metrics.RPCRequestBytes, "eth_getBalance", metrics.SourceAPI)
+	pollerBefore, _ := histogramSnapshot(t, metrics.RPCRequestBytes, "eth_getBalance", metrics.SourcePoller)
+
+	_, err := client.GetBalance(metrics.WithSource(context.Background(), metrics.SourcePoller), address, "latest")
+	assert.NoError(t, err)
+
+	// Untagged and unknown sources are attributed to the API
+	_, err = client.GetBalance(context.Background(), address, "latest")
+	assert.NoError(t, err)
+	_, err = client.GetBalance(metrics.WithSource(context.Background(), "cron"), address, "latest")
+	assert.NoError(t, err)
+
+	apiAfter, _ := histogramSnapshot(t, metrics.RPCRequestBytes, "eth_getBalance", metrics.SourceAPI)
+	pollerAfter, _ := histogramSnapshot(t, metrics.RPCRequestBytes, "eth_getBalance", metrics.SourcePoller)
+	assert.Equal(t, pollerBefore+1, pollerAfter)
+	assert.Equal(t, apiBefore+2, apiAfter)
+}
+
+func TestResponseSizeObserved(t *testing.T) {
+	const body = `{"jsonrpc":"2.0","id":1,"result":"0x1"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	countBefore, sumBefore := histogramSnapshot(t, metrics.RPCResponseSize, "eth_chainId")
+	totalBefore := testutil.ToFloat64(metrics.RPCResponseBytesTotal.WithLabelValues("eth_chainId"))
+
+	for i := 0; i < 2; i++ {
+		_, err := client.ChainID(context.Background())
+		assert.NoError(t, err)
+	}
+
+	countAfter, sumAfter := histogramSnapshot(t, metrics.RPCResponseSize, "eth_chainId")
+	assert.Equal(t, countBefore+2, countAfter)
+	assert.Equal(t, float64(2*len(body)), sumAfter-sumBefore)
+	assert.Equal(t, float64(2*len(body)), testutil.ToFloat64(metrics.RPCResponseBytesTotal.WithLabelValues("eth_chainId"))-totalBefore)
+}
+
+func TestRPCErrorCodesCounted(t *testing.T) {
+	var code atomic.Value
+	code.Store("-32005")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":` + code.Load().(string) + `,"message":"failed"}}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithRetry(RetryConfig{}))
+	limited := metrics.RPCErrorCodesTotal.WithLabelValues("eth_gasPrice", "-32005")
+	other := metrics.RPCErrorCodesTotal.WithLabelValues("eth_gasPrice", "other")
+	limitedBefore, otherBefore := testutil.ToFloat64(limited), testutil.ToFloat64(other)
+
+	_, err := client.GasPrice(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, limitedBefore+1, testutil.ToFloat64(limited))
+
+	// Codes outside the known set share one label value
+	code.Store("-39999")
+	_, err = client.GasPrice(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, otherBefore+1, testutil.ToFloat64(other))
+	assert.Equal(t, limitedBefore+1, testutil.ToFloat64(limited))
+}
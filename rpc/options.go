@@ -0,0 +1,83 @@
+package rpc
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WithTimeout bounds each HTTP attempt, WebSocket dial and subscription
+// handshake. Non-positive timeouts leave DefaultTimeout in place.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *EnhancedClient) {
+		if timeout > 0 {
+			c.timeout = timeout
+		}
+	}
+}
+
+// DefaultClientName labels the metrics of clients not given a name with WithName
+const DefaultClientName = "primary"
+
+// WithName labels the client's metrics, such as its in-flight requests and
+// circuit breaker state, with name instead of DefaultClientName. Clients of
+// different chains need distinct names. Endpoint URLs are never used as labels
+// since they may carry API keys.
+func WithName(name string) Option {
+	return func(c *EnhancedClient) {
+		if name != "" {
+			c.name = name
+		}
+	}
+}
+
+// WithHTTPClient sends requests with httpClient instead of a client built from
+// the timeout and WithTransport. Attempts are still bounded by the timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *EnhancedClient) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport sends requests through transport, for example to tune
+// connection pooling or proxying. It has no effect together with WithHTTPClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *EnhancedClient) {
+		c.transport = transport
+	}
+}
+
+// sensitiveHeaderFragments mark header names whose values are redacted
+// wherever headers are logged or dumped
+var sensitiveHeaderFragments = []string{"auth", "cookie", "token", "secret", "key"}
+
+// WithHeaders adds headers to requests to the primary endpoint and the
+// WebSocket handshake, such as provider API keys or project identifiers.
+// Fallback endpoints never receive them. Content-Type and Accept-Encoding
+// cannot be overridden. Values of headers whose names suggest credentials are
+// redacted from logs.
+func WithHeaders(headers map[string]string) Option {
+	return func(c *EnhancedClient) {
+		if c.headers == nil {
+			c.headers = make(http.Header, len(headers))
+		}
+		for name, value := range headers {
+			c.headers.Set(name, value)
+		}
+	}
+}
+
+// redactHeaders returns a copy of header with credential values replaced
+func redactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for name := range redacted {
+		lower := strings.ToLower(name)
+		for _, fragment := range sensitiveHeaderFragments {
+			if strings.Contains(lower, fragment) {
+				redacted[name] = []string{redactedValue}
+				break
+			}
+		}
+	}
+	return redacted
+}
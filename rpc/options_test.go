@@ -0,0 +1,144 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"blockchain-client/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestNewClientOptions(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+
+	// Headers are the primary provider's and never reach the fallback
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+		assert.NoError(t, err)
+	}))
+	defer backup.Close()
+
+	var roundTrips int32
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&roundTrips, 1)
+		return http.DefaultTransport.RoundTrip(r)
+	})
+
+	client := NewClient(primary.URL,
+		WithTimeout(time.Second),
+		WithEndpoints(backup.URL),
+		WithTransport(transport),
+		WithHeaders(map[string]string{
+			"Authorization": "Bearer key",
+			"Content-Type":  "text/plain",
+		}))
+	assert.Equal(t, time.Second, client.timeout)
+
+	blockNumber, err := client.GetLatestBlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, "0x10", blockNumber)
+	assert.Len(t, client.EndpointStatuses(), 2)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&roundTrips))
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	client := NewClient("http://localhost", WithTimeout(0))
+	assert.Equal(t, DefaultTimeout, client.timeout)
+	assert.Equal(t, DefaultTimeout, client.httpClient.Timeout)
+	assert.Len(t, client.EndpointStatuses(), 1)
+
+	// An explicit HTTP client is used as given
+	httpClient := &http.Client{}
+	client = NewClient("http://localhost", WithHTTPClient(httpClient), WithTransport(http.DefaultTransport))
+	assert.Same(t, httpClient, client.httpClient)
+	assert.Nil(t, httpClient.Transport)
+
+	// The compatibility constructors are NewClient with options
+	client = NewEnhancedClientWithEndpoints([]string{"http://a", "http://b"}, 3*time.Second)
+	assert.Equal(t, 3*time.Second, client.timeout)
+	assert.Len(t, client.EndpointStatuses(), 2)
+}
+
+func TestWithRateLimit(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRateLimit(10, 1))
+
+	// The burst passes at once and the next call waits about 100ms
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		_, err := client.GetLatestBlockNumber()
+		assert.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// Calls whose context ends before their turn fail without being sent
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := client.GetLatestBlockNumberCtx(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	assert.Nil(t, NewClient(server.URL, WithRateLimit(0, 1)).limiter)
+}
+
+func TestCustomHeaders(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	defer logger.Replace(zap.New(core))()
+
+	handshake := make(chan http.Header, 1)
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handshake <- r.Header.Clone()
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer node.Close()
+
+	client := NewClient(node.URL,
+		WithWebSocketURL(wsURL(node)),
+		WithHeaders(map[string]string{"X-Api-Key": "s3cret", "X-Project-Id": "explorer"}))
+
+	// The WebSocket handshake carries the headers too
+	_, err := client.SubscribeNewHeads(context.Background())
+	assert.Error(t, err)
+	header := <-handshake
+	assert.Equal(t, "s3cret", header.Get("X-Api-Key"))
+	assert.Equal(t, "explorer", header.Get("X-Project-Id"))
+
+	// Credential values are redacted from logs while other headers stay readable
+	entries := logs.FilterMessage("Initializing enhanced RPC client").All()
+	if assert.Len(t, entries, 1) {
+		logged := fmt.Sprint(entries[0].ContextMap()["headers"])
+		assert.Contains(t, logged, "X-Api-Key:[REDACTED]")
+		assert.Contains(t, logged, "X-Project-Id:[explorer]")
+		assert.NotContains(t, logged, "s3cret")
+	}
+}
@@ -0,0 +1,37 @@
+package rpc
+
+import (
+	"context"
+
+	"blockchain-client/pkg/errors"
+
+	"golang.org/x/time/rate"
+)
+
+// WithRateLimit paces outgoing RPC calls, with a batch counting as one call,
+// to at most requestsPerSecond with bursts of up to burst calls, keeping the
+// client within a provider's quota. Calls wait for their turn rather than fail,
+// unless their context ends first. A non-positive rate disables the limit.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(c *EnhancedClient) {
+		if requestsPerSecond <= 0 {
+			c.limiter = nil
+			return
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		c.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+	}
+}
+
+// waitForRateLimit blocks until the rate limit admits another call or ctx is done
+func (c *EnhancedClient) waitForRateLimit(ctx context.Context) error {
+	if c.limiter == nil {
+		return nil
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return errors.NewTimeoutError("RPC request timed out waiting for the rate limit", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/zap"
+)
+
+// RawCall sends req to the node as given and returns the node's JSON-RPC
+// response verbatim. A JSON-RPC error object in the response is part of the
+// answer rather than a failure, so only transport failures, non-200 responses
+// and bodies that are not JSON are returned as errors. The request goes
+// through the client's retries, failover and limits but never the block cache.
+func (c *EnhancedClient) RawCall(ctx context.Context, req models.RawRPCRequest) (response json.RawMessage, err error) {
+	if req.Method == "" {
+		return nil, errors.NewValidationError("method is required", nil)
+	}
+
+	ctx, span := startSpan(ctx, req.Method)
+	defer func() { endSpan(span, err) }()
+
+	requestJSON, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.NewInternalError("Failed to marshal JSON request", err)
+	}
+
+	bodyBytes, err := c.post(ctx, req.Method, idempotent(req.Method), requestJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	// The ID is the caller's and may not be a number, so only the error is decoded
+	var decoded struct {
+		Error *models.RPCError `json:"error"`
+	}
+	if err := json.Unmarshal(bodyBytes, &decoded); err != nil {
+		logger.FromContext(ctx).Error("Failed to unmarshal response",
+			zap.String("method", req.Method),
+			zap.Error(err),
+			zap.String("response", string(bodyBytes)))
+		return nil, errors.NewBlockchainError("Node returned a malformed JSON-RPC response", err)
+	}
+	if decoded.Error != nil {
+		span.SetAttributes(semconv.RPCJsonrpcErrorCode(decoded.Error.Code))
+		metrics.RecordRPCErrorCode(req.Method, decoded.Error.Code)
+	}
+
+	return json.RawMessage(bodyBytes), nil
+}
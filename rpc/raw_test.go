@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRawCall(t *testing.T) {
+	var reply atomic.Value
+	reply.Store(`{"jsonrpc":"2.0","id":"req-7","result":"0x1"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		// Params and the ID reach the node exactly as the caller gave them
+		assert.JSONEq(t, `{"jsonrpc":"2.0","method":"eth_getStorageAt","params":["0xc2132d05d31c914a87c6611c10748aeb04b58e8f",115792089237316195423570985008687907853269984665640564039457584007913129639935,"latest"],"id":"req-7"}`, string(body))
+
+		_, err = w.Write([]byte(reply.Load().(string)))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithRetry(RetryConfig{}))
+	request := models.RawRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_getStorageAt",
+		Params:  json.RawMessage(`["0xc2132d05d31c914a87c6611c10748aeb04b58e8f",115792089237316195423570985008687907853269984665640564039457584007913129639935,"latest"]`),
+		ID:      json.RawMessage(`"req-7"`),
+	}
+
+	response, err := client.RawCall(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":"req-7","result":"0x1"}`, string(response))
+
+	// JSON-RPC errors are part of the answer, though still counted
+	counted := metrics.RPCErrorCodesTotal.WithLabelValues("eth_getStorageAt", "-32602")
+	before := testutil.ToFloat64(counted)
+	reply.Store(`{"jsonrpc":"2.0","id":"req-7","error":{"code":-32602,"message":"invalid argument 1"}}`)
+	response, err = client.RawCall(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":"req-7","error":{"code":-32602,"message":"invalid argument 1"}}`, string(response))
+	assert.Equal(t, before+1, testutil.ToFloat64(counted))
+
+	reply.Store(`<html>Bad gateway</html>`)
+	_, err = client.RawCall(context.Background(), request)
+	assert.True(t, errors.IsType(err, errors.ErrorTypeBlockchain))
+
+	_, err = client.RawCall(context.Background(), models.RawRPCRequest{JSONRPC: "2.0", ID: json.RawMessage(`1`)})
+	assert.True(t, errors.IsType(err, errors.ErrTypeValidation))
+}
@@ -0,0 +1,31 @@
+package rpc
+
+import (
+	"net/http"
+
+	"blockchain-client/pkg/logger"
+)
+
+// DefaultRequestIDHeader is the header that carries the ID of the API request
+// that triggered an RPC call, matching the header the server accepts
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// WithRequestIDHeader sets the header used to forward the request ID found in
+// a call's context to the node, so provider logs can be tied back to the API
+// request that caused them. An empty name stops forwarding the ID.
+func WithRequestIDHeader(name string) Option {
+	return func(c *EnhancedClient) {
+		c.requestIDHeader = name
+	}
+}
+
+// setRequestIDHeader adds the request ID carried by the request's context, if
+// any, to the outgoing request
+func (c *EnhancedClient) setRequestIDHeader(req *http.Request) {
+	if c.requestIDHeader == "" {
+		return
+	}
+	if id := logger.RequestIDFromContext(req.Context()); id != "" {
+		req.Header.Set(c.requestIDHeader, id)
+	}
+}
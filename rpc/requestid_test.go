@@ -0,0 +1,77 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blockchain-client/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestIDForwardedToNode(t *testing.T) {
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	ctx := logger.ContextWithRequestID(context.Background(), "req-123")
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+	_, err := client.GetLatestBlockNumberCtx(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "req-123", headers[0].Get(DefaultRequestIDHeader))
+
+	// Calls made outside an API request carry no ID
+	_, err = client.GetLatestBlockNumberCtx(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, headers[1].Get(DefaultRequestIDHeader))
+
+	custom := NewEnhancedClient(server.URL, 10*time.Second, WithRequestIDHeader("X-Correlation-ID"))
+	_, err = custom.GetLatestBlockNumberCtx(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "req-123", headers[2].Get("X-Correlation-ID"))
+	assert.Empty(t, headers[2].Get(DefaultRequestIDHeader))
+
+	disabled := NewEnhancedClient(server.URL, 10*time.Second, WithRequestIDHeader(""))
+	_, err = disabled.GetLatestBlockNumberCtx(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, headers[3].Get(DefaultRequestIDHeader))
+}
+
+func TestUpstreamLogsCarryContextFields(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	defer logger.Replace(zap.New(core))()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	ctx := logger.ContextWithRequestID(context.Background(), "req-123")
+	ctx = logger.IntoContext(ctx, zap.String("endpoint", "/api/v1/block/latest"))
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+	_, err := client.GetLatestBlockNumberCtx(ctx)
+	assert.NoError(t, err)
+
+	for _, message := range []string{"Sending RPC request", "Received RPC response"} {
+		entries := logs.FilterMessage(message).All()
+		if assert.Len(t, entries, 1, message) {
+			fields := entries[0].ContextMap()
+			assert.Equal(t, "req-123", fields["request_id"], message)
+			assert.Equal(t, "/api/v1/block/latest", fields["endpoint"], message)
+			assert.Equal(t, "eth_blockNumber", fields["method"], message)
+		}
+	}
+}
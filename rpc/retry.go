@@ -0,0 +1,119 @@
+package rpc
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryJitter is the default fraction of each backoff that is randomized
+const DefaultRetryJitter = 0.2
+
+// RetryConfig controls how transient RPC failures are retried. Only network
+// errors, timeouts and HTTP 429/502/503/504 responses are retried, and never
+// for methods that are not idempotent; a zero MaxRetries disables retrying
+// entirely.
+type RetryConfig struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter is the fraction, between 0 and 1, of each backoff that is
+	// randomized so that clients failing at the same time spread out their
+	// retries instead of hitting the provider in lockstep. Zero disables it.
+	Jitter float64
+}
+
+// DefaultRetryConfig returns a retry configuration suited to public RPC providers
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries:     3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2.0,
+		Jitter:         DefaultRetryJitter,
+	}
+}
+
+// WithRetry enables retrying of transient RPC failures
+func WithRetry(cfg RetryConfig) Option {
+	return func(c *EnhancedClient) {
+		c.retry = cfg
+	}
+}
+
+// JitterEnabled reports whether backoffs are randomized
+func (r RetryConfig) JitterEnabled() bool {
+	return r.jitter() > 0
+}
+
+// jitter returns the configured jitter clamped to [0, 1]
+func (r RetryConfig) jitter() float64 {
+	return math.Min(math.Max(r.Jitter, 0), 1)
+}
+
+// backoff returns the jittered delay before the given retry attempt (zero-based)
+func (r RetryConfig) backoff(attempt int) time.Duration {
+	multiplier := r.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(r.InitialBackoff) * math.Pow(multiplier, float64(attempt))
+	if r.MaxBackoff > 0 && delay > float64(r.MaxBackoff) {
+		delay = float64(r.MaxBackoff)
+	}
+
+	// Shave off up to the jitter fraction of the delay at random
+	delay -= delay * r.jitter() * rand.Float64()
+	return time.Duration(delay)
+}
+
+// nonIdempotentMethods are the JSON-RPC methods that change state on the
+// network. A request that timed out or failed mid-flight may still have been
+// accepted by the node, so these are sent once, to a single endpoint.
+var nonIdempotentMethods = map[string]bool{
+	"eth_sendRawTransaction":            true,
+	"eth_sendRawTransactionConditional": true,
+	"eth_sendTransaction":               true,
+	"personal_sendTransaction":          true,
+}
+
+// idempotent reports whether a JSON-RPC method may safely be sent again
+func idempotent(method string) bool {
+	return !nonIdempotentMethods[method]
+}
+
+// isRetryableStatus reports whether an HTTP status indicates a transient upstream failure
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an
+// HTTP date, returning zero when it is absent or malformed
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
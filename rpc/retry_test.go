@@ -0,0 +1,149 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+		Multiplier:     2.0,
+	}
+}
+
+func TestRetryOnTransientStatus(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first two attempts with transient statuses
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+			assert.NoError(t, err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithRetry(testRetryConfig()))
+
+	blockNumber, err := client.GetLatestBlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, "0x10", blockNumber)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithRetry(testRetryConfig()))
+
+	_, err := client.GetLatestBlockNumber()
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestNoRetryForTransactionSubmission(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithRetry(testRetryConfig()))
+
+	_, err := client.SendRawTransaction(context.Background(), "0x02f870")
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRetryGivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithRetry(testRetryConfig()))
+
+	_, err := client.GetLatestBlockNumber()
+	assert.Error(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
+
+func TestRetryAfterBeyondMaxBackoffStopsRetrying(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithRetry(testRetryConfig()))
+
+	_, err := client.GetLatestBlockNumber()
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 2*time.Second, parseRetryAfter("2"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	wait := parseRetryAfter(date)
+	assert.True(t, wait > 50*time.Second && wait <= time.Minute)
+}
+
+func TestBackoffGrowsAndCaps(t *testing.T) {
+	cfg := RetryConfig{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2.0, Jitter: DefaultRetryJitter}
+
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second} {
+		got := cfg.backoff(attempt)
+		assert.LessOrEqual(t, got, want)
+		assert.GreaterOrEqual(t, got, time.Duration(float64(want)*(1-DefaultRetryJitter)))
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	cfg := RetryConfig{InitialBackoff: time.Second, MaxBackoff: time.Second, Multiplier: 2.0, Jitter: 0.5}
+	assert.True(t, cfg.JitterEnabled())
+
+	// Backoffs spread over the jittered range rather than repeating
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		got := cfg.backoff(0)
+		assert.GreaterOrEqual(t, got, 500*time.Millisecond)
+		assert.LessOrEqual(t, got, time.Second)
+		seen[got] = true
+	}
+	assert.Greater(t, len(seen), 1)
+
+	cfg.Jitter = 0
+	assert.False(t, cfg.JitterEnabled())
+	assert.Equal(t, time.Second, cfg.backoff(0))
+
+	// Out-of-range jitter is clamped
+	cfg.Jitter = 3
+	assert.GreaterOrEqual(t, cfg.backoff(0), time.Duration(0))
+}
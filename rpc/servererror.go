@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"strings"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+)
+
+// serverErrorCode is the JSON-RPC code nodes reuse for most implementation
+// defined failures, which are then told apart only by their message
+const serverErrorCode = -32000
+
+// ServerErrorPattern classifies -32000 server errors whose message contains
+// Fragment, compared case-insensitively, as an AppError of type Type
+type ServerErrorPattern struct {
+	Fragment string
+	Type     string
+	// Retryable marks conditions that may clear on their own, such as a
+	// load-balanced node that has not yet seen the requested block. Such
+	// errors are retried according to the client's RetryConfig.
+	Retryable bool
+}
+
+// DefaultServerErrorPatterns are the -32000 messages of common nodes that
+// indicate something more specific than a node failure
+var DefaultServerErrorPatterns = []ServerErrorPattern{
+	{Fragment: "header not found", Type: errors.ErrTypeNotFound, Retryable: true},
+	{Fragment: "unknown block", Type: errors.ErrTypeNotFound, Retryable: true},
+	{Fragment: "missing trie node", Type: errors.ErrTypeNotFound},
+	{Fragment: "insufficient funds", Type: errors.ErrTypeValidation},
+	{Fragment: "nonce too low", Type: errors.ErrTypeValidation},
+	{Fragment: "intrinsic gas too low", Type: errors.ErrTypeValidation},
+	{Fragment: "gas required exceeds allowance", Type: errors.ErrTypeValidation},
+	{Fragment: "max fee per gas less than block base fee", Type: errors.ErrTypeValidation},
+	{Fragment: "already known", Type: errors.ErrTypeValidation},
+	{Fragment: "known transaction", Type: errors.ErrTypeValidation},
+}
+
+// WithServerErrorPatterns replaces the patterns used to classify -32000
+// server errors. Errors matching none of them remain blockchain errors.
+func WithServerErrorPatterns(patterns []ServerErrorPattern) Option {
+	return func(c *EnhancedClient) {
+		c.serverErrorPatterns = patterns
+	}
+}
+
+// classifyServerError returns the first pattern matching a -32000 error
+func (c *EnhancedClient) classifyServerError(rpcErr models.RPCError) (ServerErrorPattern, bool) {
+	if rpcErr.Code != serverErrorCode {
+		return ServerErrorPattern{}, false
+	}
+	message := strings.ToLower(rpcErr.Message)
+	for _, pattern := range c.serverErrorPatterns {
+		if strings.Contains(message, strings.ToLower(pattern.Fragment)) {
+			return pattern, true
+		}
+	}
+	return ServerErrorPattern{}, false
+}
@@ -0,0 +1,110 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyServerErrors(t *testing.T) {
+	client := NewEnhancedClient("http://localhost:8545", time.Second)
+
+	tests := []struct {
+		message   string
+		errType   string
+		retryable bool
+	}{
+		// geth, after the block was requested from a lagging node
+		{"header not found", errors.ErrTypeNotFound, true},
+		// Erigon
+		{"unknown block number", errors.ErrTypeNotFound, true},
+		// State queried at a pruned block on a non-archive node
+		{"missing trie node 5b1e36e2a5c3d8f7c6e1b0b7a3d41b5c2a2f0e6f8d7c9b1a0e3f2d4c5b6a7980 (path )", errors.ErrTypeNotFound, false},
+		{"insufficient funds for gas * price + value: address 0xa7d9ddbe1f17865597fbd27ec712455208b6b76d have 0 want 21000000000000", errors.ErrTypeValidation, false},
+		{"nonce too low: address 0xa7d9ddbe1f17865597fbd27ec712455208b6b76d, tx: 9 state: 21", errors.ErrTypeValidation, false},
+		{"max fee per gas less than block base fee: address 0xa7d9ddbe1f17865597fbd27ec712455208b6b76d, maxFeePerGas: 1000 baseFee: 7", errors.ErrTypeValidation, false},
+		// Unclassified server errors stay node failures
+		{"filter not found", errors.ErrorTypeBlockchain, false},
+	}
+
+	for _, tt := range tests {
+		err := client.newRPCResponseError(models.RPCError{Code: -32000, Message: tt.message})
+		assert.Equal(t, tt.errType, err.Type, tt.message)
+		if tt.errType != errors.ErrorTypeBlockchain {
+			assert.Equal(t, tt.message, err.Message)
+			assert.Equal(t, tt.retryable, err.Data["retryable"], tt.message)
+		}
+	}
+
+	// Reverts keep their own type, and other codes are not classified
+	err := client.newRPCResponseError(models.RPCError{Code: -32000, Message: "execution reverted"})
+	assert.Equal(t, errors.ErrTypeReverted, err.Type)
+	err = client.newRPCResponseError(models.RPCError{Code: -32603, Message: "header not found"})
+	assert.Equal(t, errors.ErrorTypeBlockchain, err.Type)
+}
+
+func TestWithServerErrorPatterns(t *testing.T) {
+	client := NewEnhancedClient("http://localhost:8545", time.Second, WithServerErrorPatterns([]ServerErrorPattern{
+		{Fragment: "Request Limit Reached", Type: errors.ErrTypeTimeout, Retryable: true},
+	}))
+
+	err := client.newRPCResponseError(models.RPCError{Code: -32000, Message: "request limit reached"})
+	assert.Equal(t, errors.ErrTypeTimeout, err.Type)
+	assert.Equal(t, true, err.Data["retryable"])
+
+	// The defaults are replaced
+	err = client.newRPCResponseError(models.RPCError{Code: -32000, Message: "header not found"})
+	assert.Equal(t, errors.ErrorTypeBlockchain, err.Type)
+}
+
+func TestGetBlockByNumberHeaderNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"header not found"}}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	_, err := client.GetBlockByNumberCtx(context.Background(), "0x10")
+	assert.True(t, errors.IsType(err, errors.ErrTypeNotFound))
+}
+
+func TestRetryableServerErrorsAreRetried(t *testing.T) {
+	var calls int32
+	var failure atomic.Value
+	failure.Store("header not found")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Each error is answered once, as by a lagging node behind a load balancer
+		// that catches up by the next attempt
+		reply := `{"jsonrpc":"2.0","id":1,"result":{"number":"0x10","hash":"0xabc","transactions":[]}}`
+		if atomic.AddInt32(&calls, 1) == 1 {
+			reply = `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"` + failure.Load().(string) + `"}}`
+		}
+		_, err := w.Write([]byte(reply))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second, WithRetry(testRetryConfig()))
+
+	block, err := client.GetBlockByNumberCtx(context.Background(), "0x10")
+	assert.NoError(t, err)
+	assert.Equal(t, "0xabc", block.Hash)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// Errors that are not retryable are returned at once
+	atomic.StoreInt32(&calls, 0)
+	failure.Store("missing trie node abc")
+	_, err = client.GetBlockByNumberCtx(context.Background(), "0x10")
+	assert.True(t, errors.IsType(err, errors.ErrTypeNotFound))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
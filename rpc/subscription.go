@@ -0,0 +1,222 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/logger"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// reconnectBackoff controls how quickly a dropped subscription is re-established
+var reconnectBackoff = RetryConfig{
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2.0,
+	Jitter:         DefaultRetryJitter,
+}
+
+// Subscriptions ping the node every pingInterval. A connection that delivers
+// neither a pong nor a notification within pongTimeout is presumed half-open
+// and re-established.
+const (
+	pingInterval = 30 * time.Second
+	pongTimeout  = 60 * time.Second
+)
+
+// WithWebSocketURL sets the ws:// or wss:// endpoint used for subscriptions,
+// which providers usually serve separately from the HTTP RPC URL
+func WithWebSocketURL(wsURL string) Option {
+	return func(c *EnhancedClient) {
+		c.wsURL = wsURL
+		c.wsDisplay = redactWebSocketURL(wsURL)
+	}
+}
+
+// subscriptionMessage is a subscription handshake reply or notification
+type subscriptionMessage struct {
+	ID     int              `json:"id"`
+	Result json.RawMessage  `json:"result"`
+	Error  *models.RPCError `json:"error,omitempty"`
+	Method string           `json:"method"`
+	Params struct {
+		Subscription string          `json:"subscription"`
+		Result       json.RawMessage `json:"result"`
+	} `json:"params"`
+}
+
+// SubscribeNewHeads streams new block headers from the WebSocket endpoint until
+// ctx is cancelled, at which point the channel is closed. Dropped connections
+// are re-established with backoff. Every header also advances the client's head.
+func (c *EnhancedClient) SubscribeNewHeads(ctx context.Context) (<-chan *models.Block, error) {
+	if c.wsURL == "" {
+		return nil, errors.NewValidationError("WebSocket URL is not configured", nil)
+	}
+
+	// Dial once up front so configuration problems surface to the caller
+	conn, err := c.dialNewHeads(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	heads := make(chan *models.Block, 16)
+	go c.streamNewHeads(ctx, conn, heads)
+	return heads, nil
+}
+
+// dialNewHeads connects to the WebSocket endpoint and completes the eth_subscribe handshake
+func (c *EnhancedClient) dialNewHeads(ctx context.Context) (*websocket.Conn, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	// The WebSocket endpoint belongs to the primary endpoint's provider
+	conn, _, err := websocket.DefaultDialer.DialContext(dialCtx, c.wsURL, c.primaryHeader())
+	if err != nil {
+		logger.Warn("Failed to dial WebSocket endpoint", zap.String("url", c.wsDisplay), zap.Error(err))
+		return nil, errors.NewBlockchainError("Failed to connect to WebSocket endpoint", err)
+	}
+
+	request := models.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_subscribe",
+		Params:  []interface{}{"newHeads"},
+		ID:      1,
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	if err := conn.WriteJSON(request); err != nil {
+		conn.Close()
+		return nil, errors.NewBlockchainError("Failed to send subscription request", err)
+	}
+
+	// The first reply carries the subscription ID
+	conn.SetReadDeadline(time.Now().Add(c.timeout))
+	var reply subscriptionMessage
+	if err := conn.ReadJSON(&reply); err != nil {
+		conn.Close()
+		return nil, errors.NewBlockchainError("Failed to read subscription reply", err)
+	}
+	if reply.Error != nil {
+		conn.Close()
+		return nil, c.newRPCResponseError(*reply.Error)
+	}
+
+	var subscriptionID string
+	if err := json.Unmarshal(reply.Result, &subscriptionID); err != nil || subscriptionID == "" {
+		conn.Close()
+		return nil, errors.NewBlockchainError(
+			fmt.Sprintf("Unexpected subscription reply: %s", string(reply.Result)), err)
+	}
+
+	// Notifications arrive at the chain's pace, so reads are only bounded by
+	// the node answering pings; see readNewHeads
+	conn.SetReadDeadline(time.Now().Add(c.wsPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(c.wsPongTimeout))
+	})
+
+	logger.Info("Subscribed to new heads",
+		zap.String("url", c.wsDisplay),
+		zap.String("subscription_id", subscriptionID))
+	return conn, nil
+}
+
+// streamNewHeads forwards header notifications onto heads, reconnecting when the
+// connection drops, and closes heads once ctx is done
+func (c *EnhancedClient) streamNewHeads(ctx context.Context, conn *websocket.Conn, heads chan<- *models.Block) {
+	defer close(heads)
+
+	for {
+		c.readNewHeads(ctx, conn, heads)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Reconnect with backoff until the subscription is re-established
+		for attempt := 0; ; attempt++ {
+			backoff := reconnectBackoff.backoff(attempt)
+			logger.Warn("WebSocket subscription dropped, reconnecting",
+				zap.Int("attempt", attempt+1),
+				zap.Duration("backoff", backoff))
+
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			var err error
+			if conn, err = c.dialNewHeads(ctx); err == nil {
+				break
+			}
+		}
+	}
+}
+
+// readNewHeads reads notifications from conn until it fails or ctx is done,
+// pinging the node meanwhile so that a half-open connection times out
+func (c *EnhancedClient) readNewHeads(ctx context.Context, conn *websocket.Conn, heads chan<- *models.Block) {
+	// Closing the connection unblocks the pending read when ctx is cancelled
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer conn.Close()
+
+		ticker := time.NewTicker(c.wsPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.timeout)); err != nil {
+					logger.Warn("WebSocket ping failed", zap.Error(err))
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		var message subscriptionMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			if ctx.Err() == nil {
+				logger.Warn("WebSocket read failed", zap.Error(err))
+			}
+			return
+		}
+		// Any message shows the connection is alive
+		conn.SetReadDeadline(time.Now().Add(c.wsPongTimeout))
+
+		if message.Method != "eth_subscription" {
+			continue
+		}
+
+		var header models.Block
+		if err := json.Unmarshal(message.Params.Result, &header); err != nil {
+			logger.Warn("Failed to decode new head", zap.Error(err))
+			continue
+		}
+
+		if number, err := hexutil.DecodeUint64(header.Number); err == nil {
+			c.SetHead(number)
+		}
+
+		select {
+		case heads <- &header:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
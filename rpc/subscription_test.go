@@ -0,0 +1,175 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"blockchain-client/models"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newHeadsServer starts a WebSocket node that completes the subscription
+// handshake and sends the given block numbers as new heads on each connection
+func newHeadsServer(t *testing.T, connections *int32, numbers func(conn int32) []string, dropAfter bool) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		n := atomic.AddInt32(connections, 1)
+
+		var request models.RPCRequest
+		assert.NoError(t, conn.ReadJSON(&request))
+		assert.Equal(t, "eth_subscribe", request.Method)
+		assert.Equal(t, []interface{}{"newHeads"}, request.Params)
+
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage,
+			[]byte(`{"jsonrpc":"2.0","id":1,"result":"0xsub"}`)))
+
+		for _, number := range numbers(n) {
+			assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(
+				`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xsub","result":{"number":%q,"hash":"0xhash"}}}`, number))))
+		}
+
+		if dropAfter {
+			return
+		}
+		// Hold the connection open until the client goes away
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+}
+
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestSubscribeNewHeads(t *testing.T) {
+	var connections int32
+	server := newHeadsServer(t, &connections, func(int32) []string { return []string{"0x1", "0x2"} }, false)
+	defer server.Close()
+
+	client := NewEnhancedClient("http://localhost", 5*time.Second, WithWebSocketURL(wsURL(server)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	heads, err := client.SubscribeNewHeads(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "0x1", (<-heads).Number)
+	assert.Equal(t, "0x2", (<-heads).Number)
+	assert.Equal(t, uint64(2), client.Head())
+
+	// Cancelling closes the channel
+	cancel()
+	select {
+	case _, open := <-heads:
+		assert.False(t, open)
+	case <-time.After(2 * time.Second):
+		t.Fatal("heads channel was not closed after cancellation")
+	}
+}
+
+func TestSubscribeNewHeadsReconnects(t *testing.T) {
+	var connections int32
+	server := newHeadsServer(t, &connections, func(n int32) []string {
+		return []string{fmt.Sprintf("0x%x", n)}
+	}, true)
+	defer server.Close()
+
+	client := NewEnhancedClient("http://localhost", 5*time.Second, WithWebSocketURL(wsURL(server)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	heads, err := client.SubscribeNewHeads(ctx)
+	assert.NoError(t, err)
+
+	// Each connection is dropped after one head, so the second head proves a reconnect
+	assert.Equal(t, "0x1", (<-heads).Number)
+	select {
+	case head := <-heads:
+		assert.Equal(t, "0x2", head.Number)
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription did not reconnect")
+	}
+}
+
+func TestSubscribeNewHeadsKeepsAnsweringConnection(t *testing.T) {
+	// The node sends nothing after the first head but answers pings
+	var connections int32
+	server := newHeadsServer(t, &connections, func(int32) []string { return []string{"0x1"} }, false)
+	defer server.Close()
+
+	client := NewEnhancedClient("http://localhost", 5*time.Second, WithWebSocketURL(wsURL(server)))
+	client.wsPingInterval, client.wsPongTimeout = 10*time.Millisecond, 50*time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	heads, err := client.SubscribeNewHeads(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", (<-heads).Number)
+
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&connections))
+}
+
+func TestSubscribeNewHeadsReconnectsHalfOpenConnection(t *testing.T) {
+	// The first connection goes silent without answering pings, as a half-open
+	// connection would; the second delivers the next head
+	var connections int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		n := atomic.AddInt32(&connections, 1)
+
+		var request models.RPCRequest
+		assert.NoError(t, conn.ReadJSON(&request))
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage,
+			[]byte(`{"jsonrpc":"2.0","id":1,"result":"0xsub"}`)))
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(
+			`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xsub","result":{"number":"0x%x","hash":"0xhash"}}}`, n))))
+
+		// Pongs are only sent while reading, so not reading leaves pings unanswered
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient("http://localhost", 5*time.Second, WithWebSocketURL(wsURL(server)))
+	client.wsPingInterval, client.wsPongTimeout = 10*time.Millisecond, 50*time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	heads, err := client.SubscribeNewHeads(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "0x1", (<-heads).Number)
+	select {
+	case head := <-heads:
+		assert.Equal(t, "0x2", head.Number)
+	case <-time.After(5 * time.Second):
+		t.Fatal("half-open subscription was not re-established")
+	}
+}
+
+func TestSubscribeNewHeadsRequiresURL(t *testing.T) {
+	client := NewEnhancedClient("http://localhost", 5*time.Second)
+
+	_, err := client.SubscribeNewHeads(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,51 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies this package's spans. Spans go to the global tracer
+// provider, which does nothing unless the application installs one.
+const tracerName = "blockchain-client/rpc"
+
+// startSpan starts a client span for an upstream JSON-RPC call named after
+// method, as a child of any span carried by ctx
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.RPCSystemKey.String("jsonrpc"),
+			semconv.RPCMethod(method),
+			semconv.RPCJsonrpcVersion("2.0"),
+		))
+}
+
+// endSpan records err, if any, on span and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// recordHTTPStatus notes the HTTP status of an attempt on the span carried by
+// ctx. With retries, the last attempt's status is the one that remains.
+func recordHTTPStatus(ctx context.Context, status int) {
+	if status != 0 {
+		trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPResponseStatusCode(status))
+	}
+}
+
+// injectTraceContext adds the trace context of ctx to outgoing headers, for
+// nodes and proxies that continue traces. The default propagator adds nothing.
+func injectTraceContext(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,79 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blockchain-client/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRPCCallSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		response := `{"jsonrpc":"2.0","id":1,"result":"0x10"}`
+		if request.Method != "eth_blockNumber" {
+			response = `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"the method does not exist"}}`
+		}
+		_, err := w.Write([]byte(response))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	// RPC spans are children of the span carried by the context
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	_, err := client.GetLatestBlockNumberCtx(ctx)
+	require.NoError(t, err)
+	var result string
+	err = client.doRequestCtx(ctx, models.RPCRequest{JSONRPC: "2.0", Method: "eth_foo", ID: 1}, &struct{ Result *string }{&result})
+	require.Error(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	ok, failed := spans[0], spans[1]
+
+	assert.Equal(t, "eth_blockNumber", ok.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), ok.Parent().SpanID())
+	assert.Equal(t, codes.Unset, ok.Status().Code)
+	assert.Contains(t, ok.Attributes(), attribute.String("rpc.method", "eth_blockNumber"))
+	assert.Contains(t, ok.Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+
+	assert.Equal(t, "eth_foo", failed.Name())
+	assert.Equal(t, codes.Error, failed.Status().Code)
+	assert.Contains(t, failed.Attributes(), attribute.Int("rpc.jsonrpc.error_code", -32601))
+	assert.NotEmpty(t, failed.Events(), "the error is recorded as an event")
+}
+
+func TestRPCCallsWithoutTracerProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// No trace context is sent when tracing is not configured
+		assert.Empty(t, r.Header.Get("traceparent"))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	_, err := NewEnhancedClient(server.URL, 10*time.Second).GetLatestBlockNumberCtx(context.Background())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,162 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/trie"
+
+	"go.uber.org/zap"
+)
+
+// rawTransactionPattern matches non-empty 0x-prefixed hex bytes
+var rawTransactionPattern = regexp.MustCompile(`^0x([0-9a-fA-F]{2})+$`)
+
+// sendRejections describe how common nodes reject transactions that cannot be
+// included as sent, by a fragment of their error message
+var sendRejections = []struct {
+	fragment string
+	code     string
+	message  string
+}{
+	{"nonce too low", errors.CodeNonceTooLow, "Transaction nonce is below the sender's next nonce; it was already used"},
+	{"insufficient funds", errors.CodeInsufficientFunds, "Sender balance does not cover the transaction value plus its maximum gas cost"},
+	{"already known", errors.CodeTransactionAlreadyKnown, "Transaction was already submitted and is pending"},
+	{"known transaction", errors.CodeTransactionAlreadyKnown, "Transaction was already submitted and is pending"},
+}
+
+// GetTransactionByHash retrieves a transaction by its hash
+func (c *EnhancedClient) GetTransactionByHash(ctx context.Context, hash string) (*models.Transaction, error) {
+	// Create JSON-RPC request
+	requestBody := models.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_getTransactionByHash",
+		Params:  []interface{}{hash},
+		ID:      1,
+	}
+
+	var response models.TransactionResponse
+	err := c.doRequestCtx(ctx, requestBody, &response)
+	if err != nil {
+		logger.Error("Failed to get transaction by hash",
+			zap.String("tx_hash", hash),
+			zap.Error(err))
+		return nil, errors.NewBlockchainError(fmt.Sprintf("Failed to get transaction %s", hash), err)
+	}
+
+	if response.Result == nil {
+		logger.Warn("Transaction not found", zap.String("tx_hash", hash))
+		errData := make(map[string]interface{})
+		errData["tx_hash"] = hash
+		return nil, errors.NewNotFoundError("Transaction not found", nil).WithCode(errors.CodeTransactionNotFound).WithData(errData)
+	}
+
+	return response.Result, nil
+}
+
+// SendRawTransaction broadcasts a signed transaction, given as 0x-prefixed
+// hex of its encoding, and returns its hash. Rejections for a used nonce,
+// insufficient funds or a transaction already submitted are reported as
+// validation errors with the codes errors.CodeNonceTooLow,
+// errors.CodeInsufficientFunds and errors.CodeTransactionAlreadyKnown, and
+// carry the transaction's hash.
+func (c *EnhancedClient) SendRawTransaction(ctx context.Context, signedTxHex string) (string, error) {
+	if !rawTransactionPattern.MatchString(signedTxHex) {
+		return "", errors.NewValidationError("Signed transaction must be 0x-prefixed hex bytes", nil)
+	}
+
+	// Create JSON-RPC request
+	requestBody := models.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_sendRawTransaction",
+		Params:  []interface{}{signedTxHex},
+		ID:      1,
+	}
+
+	var response models.StringResponse
+	err := c.doRequestCtx(ctx, requestBody, &response)
+	if err != nil {
+		hash := rawTransactionHash(signedTxHex)
+		// Rejections are the node's answer to the transaction, not a failure
+		if appErr, ok := errors.IsAppError(err); ok {
+			if rejection := describeSendRejection(appErr); rejection.Type == errors.ErrTypeValidation {
+				return "", rejection.WithData(map[string]interface{}{"tx_hash": hash})
+			}
+		}
+		logger.Error("Failed to send transaction",
+			zap.String("tx_hash", hash),
+			zap.Error(err))
+		return "", errors.NewBlockchainError(fmt.Sprintf("Failed to send transaction %s", hash), err)
+	}
+
+	return response.Result, nil
+}
+
+// rawTransactionHash returns the hash of a signed transaction, the Keccak-256
+// of its encoding
+func rawTransactionHash(signedTxHex string) string {
+	raw, err := hexutil.DecodeBytes(signedTxHex)
+	if err != nil {
+		return ""
+	}
+	return hexutil.EncodeBytes(trie.Keccak256(raw))
+}
+
+// describeSendRejection gives a node's rejection of a transaction a stable
+// code and a description independent of the node's wording, which stays in
+// the error's data. Nodes disagree on the error codes of rejections, so the
+// node's message is matched whatever the error was classified as. Other errors
+// are returned as is.
+func describeSendRejection(err *errors.AppError) *errors.AppError {
+	message := err.Message
+	if nodeMessage, ok := err.Data["error_message"].(string); ok {
+		message = nodeMessage
+	}
+	message = strings.ToLower(message)
+	for _, rejection := range sendRejections {
+		if strings.Contains(message, rejection.fragment) {
+			return errors.NewValidationError(rejection.message, nil).
+				WithCode(rejection.code).
+				WithData(err.Data)
+		}
+	}
+	return err
+}
+
+// GetTransactionReceipt retrieves the receipt of a mined transaction. Pending
+// transactions have no receipt yet and are reported as not found.
+func (c *EnhancedClient) GetTransactionReceipt(ctx context.Context, hash string) (*models.TransactionReceipt, error) {
+	// Create JSON-RPC request
+	requestBody := models.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_getTransactionReceipt",
+		Params:  []interface{}{hash},
+		ID:      1,
+	}
+
+	var response models.TransactionReceiptResponse
+	err := c.doRequestCtx(ctx, requestBody, &response)
+	if err != nil {
+		logger.Error("Failed to get transaction receipt",
+			zap.String("tx_hash", hash),
+			zap.Error(err))
+		return nil, errors.NewBlockchainError(fmt.Sprintf("Failed to get receipt for transaction %s", hash), err)
+	}
+
+	if response.Result == nil {
+		logger.Warn("Transaction receipt not found", zap.String("tx_hash", hash))
+		errData := make(map[string]interface{})
+		errData["tx_hash"] = hash
+		return nil, errors.NewNotFoundError(
+			"Transaction receipt not found; the transaction may still be pending", nil).
+			WithCode(errors.CodeReceiptNotFound).WithData(errData)
+	}
+
+	return response.Result, nil
+}
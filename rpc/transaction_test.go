@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/trie"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendRawTransaction(t *testing.T) {
+	const raw = "0x02f8700181c5843b9aca00850bdfd63e0082520894a7d9ddbe1f17865597fbd27ec712455208b6b76d880de0b6b3a764000080c0"
+	rawBytes, err := hexutil.DecodeBytes(raw)
+	require.NoError(t, err)
+	hash := hexutil.EncodeBytes(trie.Keccak256(rawBytes))
+
+	var reply string
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "eth_sendRawTransaction", request.Method)
+		assert.Equal(t, []interface{}{raw}, request.Params)
+
+		_, err := w.Write([]byte(reply))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewEnhancedClient(server.URL, 10*time.Second)
+
+	reply = `{"jsonrpc":"2.0","id":1,"result":"` + hash + `"}`
+	result, err := client.SendRawTransaction(context.Background(), raw)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, result)
+
+	// Common rejections get stable codes and keep the node's message
+	// whatever error code the node uses for them
+	rejections := []struct {
+		rpcCode string
+		message string
+		code    string
+	}{
+		{"-32000", "nonce too low: next nonce 10, tx nonce 9", errors.CodeNonceTooLow},
+		{"-32000", "insufficient funds for gas * price + value", errors.CodeInsufficientFunds},
+		{"-32000", "already known", errors.CodeTransactionAlreadyKnown},
+		{"-32003", "Nonce too low", errors.CodeNonceTooLow},
+		{"-32010", "Insufficient funds. The account you tried to send transaction from does not have enough funds.", errors.CodeInsufficientFunds},
+		{"-32603", "known transaction: 0x1234", errors.CodeTransactionAlreadyKnown},
+	}
+	for _, rejection := range rejections {
+		reply = `{"jsonrpc":"2.0","id":1,"error":{"code":` + rejection.rpcCode + `,"message":"` + rejection.message + `"}}`
+		_, err = client.SendRawTransaction(context.Background(), raw)
+
+		appErr, ok := errors.IsAppError(err)
+		require.True(t, ok, rejection.message)
+		assert.Equal(t, errors.ErrTypeValidation, appErr.Type, rejection.message)
+		assert.Equal(t, rejection.code, appErr.Code, rejection.message)
+		assert.Equal(t, rejection.message, appErr.Data["error_message"])
+		assert.Equal(t, hash, appErr.Data["tx_hash"])
+	}
+
+	// Other failures are the node's
+	reply = `{"jsonrpc":"2.0","id":1,"error":{"code":-32603,"message":"internal error"}}`
+	_, err = client.SendRawTransaction(context.Background(), raw)
+	assert.True(t, errors.IsType(err, errors.ErrorTypeBlockchain), err)
+
+	// Malformed input never reaches the node
+	callsBefore := calls
+	for _, invalid := range []string{"", "0x", "0x123", "02f870", "0xzz"} {
+		_, err = client.SendRawTransaction(context.Background(), invalid)
+		assert.True(t, errors.IsType(err, errors.ErrTypeValidation), invalid)
+	}
+	assert.Equal(t, callsBefore, calls)
+}
@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/abi"
+	"blockchain-client/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// TransferTopic is the topic of the ERC-20 and ERC-721 Transfer event
+var TransferTopic = abi.EventTopic("Transfer(address,address,uint256)")
+
+// GetTokenTransfers returns the ERC-20 Transfer events emitted in a block, in
+// log order. Logs sharing the Transfer topic that are not ERC-20 transfers,
+// such as ERC-721 transfers with an indexed token ID, or that do not decode
+// are skipped; their number is returned alongside the transfers.
+func (c *EnhancedClient) GetTokenTransfers(ctx context.Context, blockNumber string) ([]models.TokenTransfer, int, error) {
+	logs, err := c.GetLogs(ctx, LogFilter{
+		FromBlock: blockNumber,
+		ToBlock:   blockNumber,
+		Topics:    [][]string{{TransferTopic}},
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	transfers := make([]models.TokenTransfer, 0, len(logs))
+	skipped := 0
+	for _, log := range logs {
+		transfer, err := decodeTransfer(log)
+		if err != nil {
+			logger.Debug("Skipping undecodable Transfer log",
+				zap.String("transaction_hash", log.TransactionHash),
+				zap.String("log_index", log.LogIndex),
+				zap.Error(err))
+			skipped++
+			continue
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers, skipped, nil
+}
+
+// decodeTransfer decodes an ERC-20 Transfer log, which indexes the sender and
+// recipient and carries the amount as its data
+func decodeTransfer(log models.Log) (models.TokenTransfer, error) {
+	if len(log.Topics) != 3 {
+		return models.TokenTransfer{}, fmt.Errorf("expected 3 topics, got %d", len(log.Topics))
+	}
+	from, err := abi.DecodeAddress(log.Topics[1])
+	if err != nil {
+		return models.TokenTransfer{}, err
+	}
+	to, err := abi.DecodeAddress(log.Topics[2])
+	if err != nil {
+		return models.TokenTransfer{}, err
+	}
+	value, err := abi.DecodeUint256(log.Data)
+	if err != nil {
+		return models.TokenTransfer{}, err
+	}
+
+	return models.TokenTransfer{
+		Token:           strings.ToLower(log.Address),
+		From:            from,
+		To:              to,
+		Value:           value.String(),
+		TransactionHash: log.TransactionHash,
+		LogIndex:        log.LogIndex,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// TransportConfig tunes the connection pool of the HTTP transport used to reach
+// the RPC endpoints
+type TransportConfig struct {
+	// MaxIdleConns caps idle connections kept across all endpoints
+	MaxIdleConns int
+	// MaxIdleConnsPerHost caps idle connections kept per endpoint. The
+	// net/http default of 2 forces a busy client to keep opening connections.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept for reuse
+	IdleConnTimeout time.Duration
+	// HTTP2 negotiates HTTP/2 with endpoints that support it
+	HTTP2 bool
+}
+
+// DefaultTransportConfig returns a pool sized for a high-throughput client
+// talking to a single RPC host
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 100,
+		IdleConnTimeout:     90 * time.Second,
+		HTTP2:               true,
+	}
+}
+
+// WithTransportConfig sends requests through a transport tuned by cfg. Without
+// it or WithTransport, the client uses DefaultTransportConfig.
+func WithTransportConfig(cfg TransportConfig) Option {
+	return func(c *EnhancedClient) {
+		c.transport = newTransport(cfg)
+	}
+}
+
+// newTransport builds an HTTP transport from the net/http defaults, which keep
+// proxy settings and dial timeouts, with cfg's pool settings
+func newTransport(cfg TransportConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = cfg.MaxIdleConns
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+	transport.ForceAttemptHTTP2 = cfg.HTTP2
+	if !cfg.HTTP2 {
+		// A non-nil, empty map is how net/http is told not to negotiate HTTP/2
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return transport
+}
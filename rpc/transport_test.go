@@ -0,0 +1,86 @@
+package rpc
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportConfig(t *testing.T) {
+	client := NewClient("http://localhost")
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	if assert.True(t, ok) {
+		assert.Equal(t, 100, transport.MaxIdleConnsPerHost)
+		assert.True(t, transport.ForceAttemptHTTP2)
+		assert.Nil(t, transport.TLSNextProto)
+	}
+
+	client = NewClient("http://localhost", WithTransportConfig(TransportConfig{
+		MaxIdleConns:        8,
+		MaxIdleConnsPerHost: 4,
+		IdleConnTimeout:     time.Second,
+	}))
+	transport = client.httpClient.Transport.(*http.Transport)
+	assert.Equal(t, 8, transport.MaxIdleConns)
+	assert.Equal(t, 4, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, time.Second, transport.IdleConnTimeout)
+	assert.False(t, transport.ForceAttemptHTTP2)
+	assert.NotNil(t, transport.TLSNextProto, "HTTP/2 is disabled")
+	// Settings not covered by the config keep the net/http defaults
+	assert.NotNil(t, transport.Proxy)
+}
+
+// BenchmarkConcurrentRequests sends bursts of concurrent requests, as a fan-out
+// over many blocks does, with the default pool and with the net/http default
+// of 2 idle connections per host. The small pool closes most connections after
+// each burst, so the next burst pays for new ones; compare ns/op and conns/op.
+func BenchmarkConcurrentRequests(b *testing.B) {
+	const burst = 16
+	benchmarks := []struct {
+		name string
+		cfg  TransportConfig
+	}{
+		{"pooled", DefaultTransportConfig()},
+		{"two-idle-per-host", TransportConfig{MaxIdleConns: 100, MaxIdleConnsPerHost: 2, IdleConnTimeout: 90 * time.Second}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			var connections int64
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+			}))
+			server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					atomic.AddInt64(&connections, 1)
+				}
+			}
+			server.Start()
+			defer server.Close()
+
+			client := NewClient(server.URL, WithTransportConfig(bm.cfg))
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var wg sync.WaitGroup
+				for j := 0; j < burst; j++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						if _, err := client.GetLatestBlockNumber(); err != nil {
+							b.Error(err)
+						}
+					}()
+				}
+				wg.Wait()
+			}
+			b.ReportMetric(float64(atomic.LoadInt64(&connections))/float64(b.N), "conns/op")
+		})
+	}
+}
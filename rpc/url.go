@@ -0,0 +1,147 @@
+package rpc
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// redactedValue replaces API keys in URLs that are logged or reported
+const redactedValue = "REDACTED"
+
+// WithRPCPath appends path to the primary endpoint URL, for providers that
+// serve JSON-RPC below the root of their base URL. Fallback endpoints are
+// used as given.
+func WithRPCPath(path string) Option {
+	return func(c *EnhancedClient) {
+		c.rpcPath = path
+	}
+}
+
+// WithAPIKeyParam adds key to the primary endpoint URL as the query parameter
+// name, for providers that authenticate with a key in the URL. Existing query
+// parameters are kept, and the key is redacted wherever URLs are logged. The
+// key is never sent to fallback endpoints, which belong to other providers and
+// must carry their own credentials in their URLs.
+func WithAPIKeyParam(name, key string) Option {
+	return func(c *EnhancedClient) {
+		c.apiKeyParam = name
+		c.apiKey = key
+	}
+}
+
+// BuildRPCURL joins path onto an absolute base URL and sets the query parameter
+// keyParam to key, escaping both and preserving any existing query string.
+// Empty path or keyParam leave that part of the URL unchanged.
+func BuildRPCURL(base, path, keyParam, key string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", errors.NewValidationError("Invalid RPC URL", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", errors.NewValidationError("RPC URL must be absolute", nil).
+			WithData(map[string]interface{}{"url": base})
+	}
+
+	if path != "" {
+		u = u.JoinPath(path)
+	}
+	if keyParam != "" {
+		query := u.Query()
+		query.Set(keyParam, key)
+		u.RawQuery = query.Encode()
+	}
+	return u.String(), nil
+}
+
+// resolveEndpoint applies the configured path and API key to the primary
+// endpoint's URL and derives the redacted URL that is logged and reported in
+// its place. Keys may also be embedded in the URLs as given, for the primary
+// and fallback endpoints alike, so those are redacted with redactURL.
+func (c *EnhancedClient) resolveEndpoint(ep *endpoint, primary bool) {
+	ep.display = redactURL(ep.url)
+	if !primary || (c.rpcPath == "" && c.apiKeyParam == "" && c.apiKeyPath == "") {
+		return
+	}
+
+	full, err := BuildRPCURL(ep.url, c.endpointPath(c.apiKeyPath), c.apiKeyParam, c.apiKey)
+	if err != nil {
+		// Requests to the unresolved URL will fail and report the endpoint unhealthy
+		logger.Error("Failed to build RPC endpoint URL", zap.String("url", ep.display), zap.Error(err))
+		return
+	}
+	ep.url = full
+	ep.display, _ = BuildRPCURL(ep.display, c.endpointPath(redactedValue), c.apiKeyParam, redactedValue)
+}
+
+// endpointPath returns the path appended to endpoint URLs, ending in key when
+// the API key is passed in the path
+func (c *EnhancedClient) endpointPath(key string) string {
+	if c.apiKeyPath == "" {
+		return c.rpcPath
+	}
+	return path.Join(c.rpcPath, key)
+}
+
+// redactPassword hides the password of credentials embedded in a URL, which
+// the HTTP client sends as basic authentication
+func redactPassword(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	if _, ok := u.User.Password(); !ok {
+		return raw
+	}
+	u.User = url.UserPassword(u.User.Username(), redactedValue)
+	return u.String()
+}
+
+// redactQuery hides the values of every query parameter of a URL
+func redactQuery(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return raw
+	}
+	query := u.Query()
+	for name := range query {
+		query.Set(name, redactedValue)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// redactURL hides every part of an RPC URL that may carry the provider's key:
+// the password, the query values and the last path segment, which providers
+// commonly make the key as in https://mainnet.infura.io/v3/<key>
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redactedValue
+	}
+	if trimmed := strings.TrimSuffix(u.Path, "/"); trimmed != "" {
+		u.Path = path.Join(path.Dir(trimmed), redactedValue)
+		u.RawPath = ""
+	}
+	return redactQuery(redactPassword(u.String()))
+}
+
+// redactWebSocketURL hides every part of a WebSocket URL that may carry the
+// provider's key: the password, the query values and the path, which
+// providers commonly end with the key as in wss://host/ws/v3/<key>
+func redactWebSocketURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redactedValue
+	}
+	if u.Path != "" && u.Path != "/" {
+		u.Path = "/" + redactedValue
+		u.RawPath = ""
+	}
+	return redactQuery(redactPassword(u.String()))
+}
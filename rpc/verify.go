@@ -0,0 +1,141 @@
+package rpc
+
+import (
+	"fmt"
+	"strings"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/rlp"
+	"blockchain-client/pkg/trie"
+)
+
+// VerifyTransactionsRoot rebuilds the transactions trie of a block fetched with
+// full transaction objects and reports whether its root matches the header's
+// transactionsRoot, catching nodes that serve a body inconsistent with the
+// header. On a mismatch it returns false with an error carrying both roots.
+func VerifyTransactionsRoot(block *models.Block) (bool, error) {
+	if block.TransactionHashes != nil {
+		return false, errors.NewValidationError("Block was fetched without full transactions", nil).
+			WithData(map[string]interface{}{"block_number": block.Number})
+	}
+	if len(block.TransactionErrors) > 0 {
+		return false, errors.NewValidationError("Block has transactions that failed to decode", nil).
+			WithData(map[string]interface{}{"block_number": block.Number})
+	}
+
+	encoded := make([][]byte, len(block.Transactions))
+	for i := range block.Transactions {
+		var err error
+		if encoded[i], err = EncodeTransaction(&block.Transactions[i]); err != nil {
+			return false, err
+		}
+	}
+
+	computed := hexutil.EncodeBytes(trie.DeriveRoot(encoded))
+	if !strings.EqualFold(computed, block.TransactionsRoot) {
+		return false, errors.NewBlockchainError("Transactions do not match the block's transactionsRoot", nil).
+			WithCode(errors.CodeTransactionsRootMismatch).
+			WithData(map[string]interface{}{
+				"block_number": block.Number,
+				"expected":     block.TransactionsRoot,
+				"computed":     computed,
+			})
+	}
+	return true, nil
+}
+
+// EncodeTransaction returns the consensus encoding of a transaction: an RLP list
+// for legacy transactions, or the EIP-2718 type byte followed by the RLP payload.
+// Keccak-256 of the encoding is the transaction hash.
+func EncodeTransaction(tx *models.Transaction) ([]byte, error) {
+	e := &txEncoder{tx: tx}
+
+	var encoded []byte
+	switch tx.Type {
+	case "", "0x0":
+		encoded = rlp.EncodeList(
+			e.quantity("nonce", tx.Nonce), e.quantity("gasPrice", tx.GasPrice), e.quantity("gas", tx.Gas),
+			e.recipient(), e.quantity("value", tx.Value), e.data("input", tx.Input),
+			e.quantity("v", tx.V), e.quantity("r", tx.R), e.quantity("s", tx.S))
+	case "0x1":
+		encoded = append([]byte{0x01}, rlp.EncodeList(
+			e.quantity("chainId", tx.ChainID), e.quantity("nonce", tx.Nonce),
+			e.quantity("gasPrice", tx.GasPrice), e.quantity("gas", tx.Gas),
+			e.recipient(), e.quantity("value", tx.Value), e.data("input", tx.Input), e.accessList(),
+			e.quantity("v", tx.V), e.quantity("r", tx.R), e.quantity("s", tx.S))...)
+	case "0x2":
+		encoded = append([]byte{0x02}, rlp.EncodeList(
+			e.quantity("chainId", tx.ChainID), e.quantity("nonce", tx.Nonce),
+			e.quantity("maxPriorityFeePerGas", tx.MaxPriorityFeePerGas),
+			e.quantity("maxFeePerGas", tx.MaxFeePerGas), e.quantity("gas", tx.Gas),
+			e.recipient(), e.quantity("value", tx.Value), e.data("input", tx.Input), e.accessList(),
+			e.quantity("v", tx.V), e.quantity("r", tx.R), e.quantity("s", tx.S))...)
+	default:
+		return nil, errors.NewUnsupportedError(
+			fmt.Sprintf("Transaction type %s cannot be encoded", tx.Type), nil).
+			WithData(map[string]interface{}{"transaction_hash": tx.Hash})
+	}
+
+	if e.err != nil {
+		return nil, errors.NewBlockchainError("Node returned a transaction that cannot be encoded", e.err).
+			WithData(map[string]interface{}{"transaction_hash": tx.Hash})
+	}
+	return encoded, nil
+}
+
+// txEncoder RLP-encodes transaction fields from their hex JSON form,
+// remembering the first field that fails to decode
+type txEncoder struct {
+	tx  *models.Transaction
+	err error
+}
+
+// quantity encodes a hex quantity as a minimal big-endian integer
+func (e *txEncoder) quantity(field, value string) []byte {
+	decoded, err := hexutil.DecodeBigField(field, value)
+	if err != nil {
+		e.fail(err)
+		return nil
+	}
+	return rlp.EncodeBig(decoded)
+}
+
+// data encodes hex data as a byte string
+func (e *txEncoder) data(field, value string) []byte {
+	decoded, err := hexutil.DecodeBytes(value)
+	if err != nil {
+		e.fail(&hexutil.FieldError{Field: field, Err: err})
+		return nil
+	}
+	return rlp.EncodeBytes(decoded)
+}
+
+// recipient encodes the to address, which is empty for contract creations
+func (e *txEncoder) recipient() []byte {
+	if e.tx.To == "" {
+		return rlp.EncodeBytes(nil)
+	}
+	return e.data("to", e.tx.To)
+}
+
+// accessList encodes the EIP-2930 access list as [[address, [keys...]], ...]
+func (e *txEncoder) accessList() []byte {
+	tuples := make([][]byte, len(e.tx.AccessList))
+	for i, tuple := range e.tx.AccessList {
+		keys := make([][]byte, len(tuple.StorageKeys))
+		for j, key := range tuple.StorageKeys {
+			keys[j] = e.data("accessList", key)
+		}
+		tuples[i] = rlp.EncodeList(e.data("accessList", tuple.Address), rlp.EncodeList(keys...))
+	}
+	return rlp.EncodeList(tuples...)
+}
+
+// fail records err unless an earlier field already failed
+func (e *txEncoder) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"testing"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/trie"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mainnetTx46147 is the only transaction of mainnet block 46147, the first
+// value transfer on Ethereum
+func mainnetTx46147() models.Transaction {
+	return models.Transaction{
+		Hash:     "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060",
+		Nonce:    "0x0",
+		GasPrice: "0x2d79883d2000",
+		Gas:      "0x5208",
+		To:       "0x5df9b87991262f6ba471f09758cde1c0fc1de734",
+		Value:    "0x7a69",
+		Input:    "0x",
+		Type:     "0x0",
+		V:        "0x1c",
+		R:        "0x88ff6cf0fefd94db46111149ae4bfc179e9b94721fffd821d38d16464b3f71d0",
+		S:        "0x45e0aff800961cfce805daef7016b9b675c137a6a41a548f7b60a3484c06a33a",
+	}
+}
+
+func TestEncodeTransactionMatchesHash(t *testing.T) {
+	tx := mainnetTx46147()
+	encoded, err := EncodeTransaction(&tx)
+	require.NoError(t, err)
+	assert.Equal(t, tx.Hash, hexutil.EncodeBytes(trie.Keccak256(encoded)))
+
+	blob := models.Transaction{Hash: "0x01", Type: "0x3"}
+	_, err = EncodeTransaction(&blob)
+	assert.True(t, errors.IsType(err, errors.ErrTypeUnsupported))
+
+	broken := mainnetTx46147()
+	broken.Input = "0xabc"
+	_, err = EncodeTransaction(&broken)
+	assert.True(t, errors.IsType(err, errors.ErrorTypeBlockchain))
+}
+
+func TestVerifyTransactionsRoot(t *testing.T) {
+	// Mainnet block 46146 is empty
+	empty := &models.Block{
+		Number:           "0xb442",
+		TransactionsRoot: "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+		Transactions:     []models.Transaction{},
+	}
+	ok, err := VerifyTransactionsRoot(empty)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	tx := mainnetTx46147()
+	block := &models.Block{
+		Number:           "0xb443",
+		TransactionsRoot: "0x4513310fcb9f6f616972a3b948dc5d547f280849a87ebb5af0191f98b87be598",
+		Transactions:     []models.Transaction{tx},
+	}
+	ok, err = VerifyTransactionsRoot(block)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// A body that differs from the header is reported with both roots
+	block.Transactions[0].Value = "0x7a6a"
+	ok, err = VerifyTransactionsRoot(block)
+	assert.False(t, ok)
+	appErr, isAppErr := errors.IsAppError(err)
+	require.True(t, isAppErr)
+	assert.Equal(t, errors.CodeTransactionsRootMismatch, appErr.ErrorCode())
+	assert.Equal(t, block.TransactionsRoot, appErr.Data["expected"])
+	assert.NotEqual(t, block.TransactionsRoot, appErr.Data["computed"])
+
+	// Hash-only blocks cannot be verified
+	ok, err = VerifyTransactionsRoot(&models.Block{TransactionHashes: []string{tx.Hash}})
+	assert.False(t, ok)
+	assert.True(t, errors.IsType(err, errors.ErrTypeValidation))
+}
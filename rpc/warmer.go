@@ -0,0 +1,220 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+
+	"go.uber.org/zap"
+)
+
+// MaxCacheWarmDepth is the largest depth WithCacheWarming accepts. Larger
+// depths are capped to it.
+const MaxCacheWarmDepth = 128
+
+// warmBatchSize is the most blocks fetched in one batch request while warming,
+// so a deep window does not become a single batch providers reject
+const warmBatchSize = 16
+
+// WithCacheWarming keeps the blocks near the chain head cached: on every new
+// head recorded with SetHead, the window of the head block and the depth
+// blocks before it is warmed, so requests for recent blocks are answered from
+// memory. Blocks already warmed are kept and only the rest of the window is
+// fetched, in batches of at most warmBatchSize blocks. A kept block is
+// refetched when it is not the parent of the block after it, so blocks
+// replaced by a reorg are not served for longer than one head. Warming runs in
+// RunCacheWarmer and goes through the client's rate and concurrency limits.
+// A negative depth disables warming, and depths above MaxCacheWarmDepth are
+// capped.
+func WithCacheWarming(depth int) Option {
+	return func(c *EnhancedClient) {
+		if depth < 0 {
+			c.warmer = nil
+			return
+		}
+		if depth > MaxCacheWarmDepth {
+			logger.Warn("Capping cache warming depth",
+				zap.Int("depth", depth),
+				zap.Int("max_depth", MaxCacheWarmDepth))
+			depth = MaxCacheWarmDepth
+		}
+		c.warmer = &cacheWarmer{
+			depth: uint64(depth),
+			heads: make(chan uint64, 1),
+		}
+	}
+}
+
+// cacheWarmer holds the blocks of the window ending at the latest warmed head
+type cacheWarmer struct {
+	depth uint64
+	// heads carries the newest head not yet warmed. Older pending heads are
+	// replaced, since only the latest window is worth fetching.
+	heads chan uint64
+
+	mu     sync.RWMutex
+	blocks map[string]*models.Block
+}
+
+// notify queues head for warming without blocking. It is safe to call on a
+// nil warmer.
+func (w *cacheWarmer) notify(head uint64) {
+	if w == nil {
+		return
+	}
+	for {
+		select {
+		case w.heads <- head:
+			return
+		default:
+		}
+		// Drop the stale pending head to make room
+		select {
+		case <-w.heads:
+		default:
+		}
+	}
+}
+
+// get returns the warmed block for a formatted block number. It is safe to
+// call on a nil warmer.
+func (w *cacheWarmer) get(number string) (*models.Block, bool) {
+	if w == nil {
+		return nil, false
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	block, ok := w.blocks[number]
+	return block, ok
+}
+
+// snapshot returns the warmed blocks. The map is replaced rather than modified
+// once warmed, so it may be read without holding the lock.
+func (w *cacheWarmer) snapshot() map[string]*models.Block {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.blocks
+}
+
+// replace swaps in the blocks of a new window
+func (w *cacheWarmer) replace(blocks map[string]*models.Block) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.blocks = blocks
+}
+
+// RunCacheWarmer warms the block window for each new head until ctx is done.
+// It returns immediately when warming is not enabled with WithCacheWarming.
+func (c *EnhancedClient) RunCacheWarmer(ctx context.Context) {
+	if c.warmer == nil {
+		return
+	}
+	ctx = metrics.WithSource(ctx, metrics.SourceWarmer)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case head := <-c.warmer.heads:
+			c.warm(ctx, head)
+		}
+	}
+}
+
+// warm makes the window of blocks ending at head the warmed set, fetching the
+// blocks that are not warmed yet and those a reorg replaced
+func (c *EnhancedClient) warm(ctx context.Context, head uint64) {
+	from := uint64(0)
+	if head > c.warmer.depth {
+		from = head - c.warmer.depth
+	}
+
+	// Blocks of the previous window that are still in this one are kept.
+	// Counting rather than comparing n with head keeps the loop from wrapping
+	// around when head is the largest block number.
+	previous := c.warmer.snapshot()
+	warmed := make(map[string]*models.Block, head-from+1)
+	kept := make(map[string]bool)
+	var queries []blockQuery
+	for i := uint64(0); i <= head-from; i++ {
+		number := hexutil.EncodeUint64(from + i)
+		if block, ok := previous[number]; ok {
+			warmed[number] = block
+			kept[number] = true
+			continue
+		}
+		queries = append(queries, blockQuery{number: number, includeTransactions: true})
+	}
+	errs := c.fetchWarmBlocks(ctx, queries, warmed)
+
+	if stale := staleWarmBlocks(warmed, kept, from, head); len(stale) > 0 {
+		for _, query := range stale {
+			delete(warmed, query.number)
+		}
+		errs = append(errs, c.fetchWarmBlocks(ctx, stale, warmed)...)
+	}
+	c.warmer.replace(warmed)
+
+	if len(errs) > 0 {
+		logger.Warn("Failed to warm some recent blocks",
+			zap.Uint64("head", head),
+			zap.Int("failed", len(errs)),
+			zap.Error(errs[0]))
+		return
+	}
+	logger.Debug("Warmed recent blocks",
+		zap.Uint64("from", from),
+		zap.Uint64("head", head),
+		zap.Int("fetched", len(queries)))
+}
+
+// fetchWarmBlocks fetches the blocks of queries in batches of warmBatchSize and
+// adds them to warmed, returning the errors of those that could not be fetched
+func (c *EnhancedClient) fetchWarmBlocks(ctx context.Context, queries []blockQuery, warmed map[string]*models.Block) []error {
+	var errs []error
+	for start := 0; start < len(queries); start += warmBatchSize {
+		batch := queries[start:min(start+warmBatchSize, len(queries))]
+		blocks, batchErrs := c.getBlocks(ctx, batch)
+		for i, block := range blocks {
+			if batchErrs[i] != nil {
+				errs = append(errs, batchErrs[i])
+				continue
+			}
+			// Blocks with undecodable transactions may decode cleanly when refetched
+			if len(block.TransactionErrors) == 0 {
+				warmed[batch[i].number] = block
+			}
+		}
+	}
+	return errs
+}
+
+// staleWarmBlocks returns queries for the kept blocks a reorg may have
+// replaced. A reorg replaces every block after the fork point, so once a kept
+// block is not the parent of the block after it, or that block is missing and
+// cannot vouch for it, it and every kept block before it are stale.
+func staleWarmBlocks(warmed map[string]*models.Block, kept map[string]bool, from, head uint64) []blockQuery {
+	for n := head; n > from; n-- {
+		parent := hexutil.EncodeUint64(n - 1)
+		if !kept[parent] {
+			continue
+		}
+		child, ok := warmed[hexutil.EncodeUint64(n)]
+		if ok && child.ParentHash == warmed[parent].Hash {
+			continue
+		}
+
+		var stale []blockQuery
+		for m := from; m < n; m++ {
+			if number := hexutil.EncodeUint64(m); kept[number] {
+				stale = append(stale, blockQuery{number: number, includeTransactions: true})
+			}
+		}
+		return stale
+	}
+	return nil
+}
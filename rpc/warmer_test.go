@@ -0,0 +1,173 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// warmerNode is a mock node serving batches of blocks whose hashes chain
+// through their parent hashes. Blocks from forkAt on get hashes of their own,
+// as if a reorg replaced them.
+type warmerNode struct {
+	mu      sync.Mutex
+	fetched []string
+	batches []int
+	forkAt  uint64
+}
+
+func (n *warmerNode) hash(number uint64) string {
+	if n.forkAt != 0 && number >= n.forkAt {
+		return fmt.Sprintf("0x%xf0", number)
+	}
+	return fmt.Sprintf("0x%x", number)
+}
+
+func (n *warmerNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var requests []struct {
+		ID     int           `json:"id"`
+		Params []interface{} `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requests); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.batches = append(n.batches, len(requests))
+	responses := make([]string, len(requests))
+	for i, request := range requests {
+		number := request.Params[0].(string)
+		n.fetched = append(n.fetched, number)
+		value, _ := strconv.ParseUint(strings.TrimPrefix(number, "0x"), 16, 64)
+		responses[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"number":"%s","hash":"%s","parentHash":"%s","transactions":[]}}`,
+			request.ID, number, n.hash(value), n.hash(value-1))
+	}
+	_, _ = w.Write([]byte("[" + strings.Join(responses, ",") + "]"))
+}
+
+// fetchedBlocks returns the block numbers fetched so far and resets them
+func (n *warmerNode) fetchedBlocks() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	fetched := n.fetched
+	n.fetched = nil
+	return fetched
+}
+
+func TestCacheWarmer(t *testing.T) {
+	mock := &warmerNode{}
+	node := httptest.NewServer(mock)
+	defer node.Close()
+
+	client := NewEnhancedClient(node.URL, 10*time.Second, WithCacheWarming(2))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.RunCacheWarmer(ctx)
+
+	warmed := func(number string) func() bool {
+		return func() bool {
+			_, ok := client.warmer.get(number)
+			return ok
+		}
+	}
+
+	// A new head warms it and the two blocks before it in one batch
+	confirmHead(client, 0x10)
+	require.Eventually(t, warmed("0x10"), time.Second, time.Millisecond)
+	assert.Equal(t, []string{"0xe", "0xf", "0x10"}, mock.fetchedBlocks())
+
+	// Warmed blocks are served without reaching the node
+	block, err := client.GetBlockByNumber("0xf")
+	require.NoError(t, err)
+	assert.Equal(t, "0xf", block.Hash)
+	assert.Empty(t, mock.fetchedBlocks())
+
+	// The next head moves the window, fetching only the new block and
+	// dropping blocks that fell out of the window
+	client.SetHead(0x11)
+	require.Eventually(t, warmed("0x11"), time.Second, time.Millisecond)
+	_, ok := client.warmer.get("0xe")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"0x11"}, mock.fetchedBlocks())
+
+	// A reorg replacing block 0x11 is detected from the new head's parent hash,
+	// and the kept blocks up to it are refetched
+	mock.mu.Lock()
+	mock.forkAt = 0x11
+	mock.mu.Unlock()
+	client.SetHead(0x12)
+	require.Eventually(t, func() bool {
+		block, ok := client.warmer.get("0x11")
+		return ok && block.Hash == "0x11f0"
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"0x12", "0x10", "0x11"}, mock.fetchedBlocks())
+}
+
+func TestCacheWarmerBatchesDeepWindows(t *testing.T) {
+	mock := &warmerNode{}
+	node := httptest.NewServer(mock)
+	defer node.Close()
+
+	client := NewEnhancedClient(node.URL, 10*time.Second, WithCacheWarming(40))
+	client.warm(context.Background(), 0x100)
+
+	assert.Len(t, mock.fetchedBlocks(), 41)
+	assert.Equal(t, []int{warmBatchSize, warmBatchSize, 41 - 2*warmBatchSize}, mock.batches)
+}
+
+func TestCacheWarmingDepthIsCapped(t *testing.T) {
+	client := NewEnhancedClient("http://localhost", time.Second, WithCacheWarming(MaxCacheWarmDepth*10))
+	assert.Equal(t, uint64(MaxCacheWarmDepth), client.warmer.depth)
+}
+
+func TestCacheWarmerAtLargestBlockNumber(t *testing.T) {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requests []struct {
+			ID     int           `json:"id"`
+			Params []interface{} `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&requests))
+
+		responses := make([]string, len(requests))
+		for i, request := range requests {
+			responses[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"number":"%s","transactions":[]}}`,
+				request.ID, request.Params[0])
+		}
+		_, err := w.Write([]byte("[" + strings.Join(responses, ",") + "]"))
+		assert.NoError(t, err)
+	}))
+	defer node.Close()
+
+	client := NewEnhancedClient(node.URL, 10*time.Second, WithCacheWarming(2))
+
+	done := make(chan struct{})
+	go func() {
+		client.warm(context.Background(), math.MaxUint64)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("warming a window ending at the largest block number did not finish")
+	}
+
+	for _, number := range []string{"0xfffffffffffffffd", "0xfffffffffffffffe", "0xffffffffffffffff"} {
+		_, ok := client.warmer.get(number)
+		assert.True(t, ok, number)
+	}
+	_, ok := client.warmer.get("0x0")
+	assert.False(t, ok)
+}
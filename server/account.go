@@ -0,0 +1,291 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+	"blockchain-client/rpc"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxBalanceAddresses is the most addresses a single bulk balance
+// request may list
+const DefaultMaxBalanceAddresses = 100
+
+// balancesBatchMethod labels the RPC metrics of bulk balance lookups, which
+// send every eth_getBalance call in one batch
+const balancesBatchMethod = "eth_getBalance_batch"
+
+// getBalancesRequest is the body of a bulk balance request
+type getBalancesRequest struct {
+	Addresses []string `json:"addresses"`
+	// Block is the block tag the balances are read at, "latest" when empty
+	Block string `json:"block"`
+}
+
+// addressBalance is one address's balance in a bulk balance response
+type addressBalance struct {
+	Balance        string `json:"balance"`
+	BalanceDecimal string `json:"balanceDecimal"`
+}
+
+// getBalances handles requests for the balances of several addresses, fetched
+// with a single batch request. Addresses that are malformed or that the node
+// fails to look up are reported under errors rather than failing the request.
+func (s *EnhancedServer) getBalances(c *gin.Context) {
+	var request getBalancesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.NewValidationError("Request body must be a JSON object with a list of addresses", err))
+		return
+	}
+	if len(request.Addresses) == 0 {
+		c.Error(errors.NewValidationError("addresses is required", nil))
+		return
+	}
+	if request.Block == "" {
+		request.Block = "latest"
+	}
+
+	// Each address is looked up once however often it is listed
+	addresses := make([]string, 0, len(request.Addresses))
+	seen := make(map[string]bool, len(request.Addresses))
+	for _, address := range request.Addresses {
+		if !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+	if len(addresses) > s.maxBalanceAddresses {
+		c.Error(errors.NewValidationError(
+			fmt.Sprintf("At most %d addresses may be requested at once", s.maxBalanceAddresses), nil).
+			WithCode(errors.CodeTooManyAddresses).
+			WithData(map[string]interface{}{"addresses": len(addresses)}))
+		return
+	}
+
+	logger.Debug("Balances requested",
+		zap.Int("addresses", len(addresses)),
+		zap.String("block", request.Block))
+
+	client, _ := s.clientFor(c)
+
+	// Start metrics timer
+	start := time.Now()
+
+	balances, err := client.BatchGetBalances(c.Request.Context(), addresses, request.Block)
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	entryErrors := make([]error, len(addresses))
+	if err != nil {
+		batchErr, partial := err.(*rpc.BatchError)
+		if !partial {
+			// An invalid block tag fails the request before any RPC call is made
+			if errors.IsType(err, errors.ErrTypeValidation) {
+				logger.Warn("Invalid balances request", zap.String("block", request.Block), zap.Error(err))
+				c.Error(err)
+				return
+			}
+
+			metrics.RPCRequestsTotal.WithLabelValues(balancesBatchMethod, "error").Inc()
+			logger.Error("Failed to get balances", zap.Error(err))
+			c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get balances"))
+			return
+		}
+		entryErrors = batchErr.Errors
+	}
+
+	// Malformed addresses are rejected before the batch is sent, so only
+	// other failures count against the node
+	sent, failed := 0, 0
+	var firstFailure error
+	for _, err := range entryErrors {
+		if errors.IsType(err, errors.ErrTypeValidation) {
+			continue
+		}
+		sent++
+		if err != nil {
+			failed++
+			if firstFailure == nil {
+				firstFailure = err
+			}
+		}
+	}
+	if sent > 0 {
+		if failed == sent {
+			metrics.RPCRequestsTotal.WithLabelValues(balancesBatchMethod, "error").Inc()
+			logger.Error("Failed to get balances", zap.Int("addresses", sent), zap.Error(firstFailure))
+			c.Error(errors.Wrap(firstFailure, errors.ErrorTypeBlockchain, "Failed to get balances"))
+			return
+		}
+
+		// Record successful RPC metrics
+		metrics.RPCRequestsTotal.WithLabelValues(balancesBatchMethod, "success").Inc()
+		metrics.RPCRequestDuration.WithLabelValues(balancesBatchMethod).Observe(duration)
+	}
+
+	results := make(map[string]addressBalance, len(addresses))
+	failures := make(map[string]gin.H)
+	decodeErrors := make(map[string]string)
+	for i, address := range addresses {
+		if entryErrors[i] != nil {
+			failures[address] = balanceError(entryErrors[i])
+			continue
+		}
+		// Render the wei amount in decimal alongside the raw hex quantity
+		decimal, err := s.decodeWei(address, balances[i], decodeErrors)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		results[address] = addressBalance{Balance: balances[i], BalanceDecimal: decimal}
+	}
+	if failed > 0 {
+		logger.Warn("Some balances could not be fetched",
+			zap.Int("addresses", sent),
+			zap.Int("failed", failed),
+			zap.Error(firstFailure))
+	}
+
+	response := gin.H{
+		"block":    request.Block,
+		"balances": results,
+	}
+	if len(failures) > 0 {
+		response["errors"] = failures
+	}
+	if len(decodeErrors) > 0 {
+		response["decodeErrors"] = decodeErrors
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// balanceError describes why one address of a bulk balance request has no
+// balance, in the shape of the error envelope. As in the envelope, only
+// client errors show their message.
+func balanceError(err error) gin.H {
+	appErr, ok := errors.Cause(err)
+	if !ok {
+		return gin.H{"type": errors.ErrorTypeBlockchain, "code": "BLOCKCHAIN_ERROR", "message": "Failed to get balance"}
+	}
+	message := "Failed to get balance"
+	if errors.HTTPStatus(appErr) < http.StatusInternalServerError {
+		message = appErr.Message
+	}
+	return gin.H{"type": appErr.Type, "code": appErr.ErrorCode(), "message": message}
+}
+
+// getBalance handles requests for the balance of an address
+func (s *EnhancedServer) getBalance(c *gin.Context) {
+	address := c.Param("address")
+	blockTag := c.DefaultQuery("block", "latest")
+
+	logger.Debug("Balance requested",
+		zap.String("address", address),
+		zap.String("block", blockTag))
+
+	client, _ := s.clientFor(c)
+
+	// Start metrics timer
+	start := time.Now()
+
+	balance, err := client.GetBalance(c.Request.Context(), address, blockTag)
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		// Invalid input is rejected before any RPC call is made
+		if errors.IsType(err, errors.ErrTypeValidation) {
+			logger.Warn("Invalid balance request",
+				zap.String("address", address),
+				zap.String("block", blockTag),
+				zap.Error(err))
+			c.Error(err)
+			return
+		}
+
+		metrics.RPCRequestsTotal.WithLabelValues("eth_getBalance", "error").Inc()
+		logger.Error("Failed to get balance", zap.String("address", address), zap.Error(err))
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get balance").
+			WithData(map[string]interface{}{"address": address}))
+		return
+	}
+
+	// Record successful RPC metrics
+	metrics.RPCRequestsTotal.WithLabelValues("eth_getBalance", "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues("eth_getBalance").Observe(duration)
+
+	// Render the wei amount in decimal alongside the raw hex quantity
+	decodeErrors := make(map[string]string)
+	decimal, err := s.decodeWei("balance", balance, decodeErrors)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := gin.H{
+		"address":        address,
+		"block":          blockTag,
+		"balance":        balance,
+		"balanceDecimal": decimal,
+	}
+	if len(decodeErrors) > 0 {
+		response["decodeErrors"] = decodeErrors
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// getCode handles requests for the bytecode deployed at an address, reporting
+// whether the address is a contract rather than an externally owned account
+func (s *EnhancedServer) getCode(c *gin.Context) {
+	address := c.Param("address")
+	blockTag := c.DefaultQuery("block", "latest")
+
+	logger.Debug("Code requested",
+		zap.String("address", address),
+		zap.String("block", blockTag))
+
+	client, _ := s.clientFor(c)
+
+	// Start metrics timer
+	start := time.Now()
+
+	code, err := client.GetCode(c.Request.Context(), address, blockTag)
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		// Invalid input is rejected before any RPC call is made
+		if errors.IsType(err, errors.ErrTypeValidation) {
+			logger.Warn("Invalid code request",
+				zap.String("address", address),
+				zap.String("block", blockTag),
+				zap.Error(err))
+			c.Error(err)
+			return
+		}
+
+		metrics.RPCRequestsTotal.WithLabelValues("eth_getCode", "error").Inc()
+		logger.Error("Failed to get code", zap.String("address", address), zap.Error(err))
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get code").
+			WithData(map[string]interface{}{"address": address}))
+		return
+	}
+
+	// Record successful RPC metrics
+	metrics.RPCRequestsTotal.WithLabelValues("eth_getCode", "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues("eth_getCode").Observe(duration)
+
+	c.JSON(http.StatusOK, gin.H{
+		"address":    address,
+		"block":      blockTag,
+		"code":       code,
+		"isContract": code != "" && code != "0x",
+	})
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"net/http"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// getAdminStats handles requests for a JSON summary of the server's own metrics
+func (s *EnhancedServer) getAdminStats(c *gin.Context) {
+	snapshot, err := metrics.TakeSnapshot(prometheus.DefaultGatherer)
+	if err != nil {
+		c.Error(errors.NewInternalError("Failed to gather metrics", err))
+		return
+	}
+	c.JSON(http.StatusOK, snapshot)
+}
@@ -0,0 +1,288 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxBlockRange is the most blocks a single block range request may return
+const DefaultMaxBlockRange = 100
+
+// headerBlockMethod labels the RPC metrics of header-only block lookups
+const headerBlockMethod = "eth_getBlockByNumber_header"
+
+// batchBlocksMethod labels the RPC metrics of a block range batch, which is
+// recorded as one observation however many blocks it fetched
+const batchBlocksMethod = "eth_getBlockByNumber_batch"
+
+// getBlockRange handles requests for the blocks from ?from= to ?to= inclusive,
+// fetched in a single batch request and returned in ascending order. Unlike
+// single block requests, blocks come with transaction hashes only unless
+// ?full=true is given, since a range of full blocks can be very large.
+func (s *EnhancedServer) getBlockRange(c *gin.Context) {
+	from, err := parseBlockNumberParam("from", c.Query("from"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	to, err := parseBlockNumberParam("to", c.Query("to"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rangeData := map[string]interface{}{"from": c.Query("from"), "to": c.Query("to")}
+	if from > to {
+		c.Error(errors.NewValidationError("from must not be after to", nil).WithData(rangeData))
+		return
+	}
+	if to-from >= uint64(s.maxBlockRange) {
+		c.Error(errors.NewValidationError(
+			fmt.Sprintf("Block ranges may span at most %d blocks", s.maxBlockRange), nil).
+			WithCode(errors.CodeRangeTooLarge).WithData(rangeData))
+		return
+	}
+
+	full, err := parseFullParamOr(c, false)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	timeFormat, err := parseTimeFormat(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Counting rather than comparing n with to keeps the loop from wrapping
+	// around when to is the largest block number
+	numbers := make([]string, 0, to-from+1)
+	for i := uint64(0); i <= to-from; i++ {
+		numbers = append(numbers, hexutil.EncodeUint64(from+i))
+	}
+
+	client, _ := s.clientFor(c)
+
+	// Start metrics timer
+	start := time.Now()
+
+	blocks, err := client.BatchGetBlocksByNumber(c.Request.Context(), numbers, full)
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		metrics.RPCRequestsTotal.WithLabelValues(batchBlocksMethod, "error").Inc()
+
+		// Surface the first failed block, so a range past the head is a 404
+		err = firstBatchError(err)
+		if errors.IsType(err, errors.ErrorTypeNotFound) {
+			logger.Warn("Block in range not found",
+				zap.String("from", numbers[0]),
+				zap.String("to", numbers[len(numbers)-1]))
+			c.Error(err)
+			return
+		}
+
+		logger.Error("Failed to get block range",
+			zap.String("from", numbers[0]),
+			zap.String("to", numbers[len(numbers)-1]),
+			zap.Error(err))
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get block range").WithData(rangeData))
+		return
+	}
+
+	metrics.RPCRequestsTotal.WithLabelValues(batchBlocksMethod, "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues(batchBlocksMethod).Observe(duration)
+
+	for i, block := range blocks {
+		blocks[i] = formatBlockTimestamp(block, timeFormat)
+	}
+
+	logger.Debug("Retrieved block range",
+		zap.String("from", numbers[0]),
+		zap.String("to", numbers[len(numbers)-1]))
+	c.JSON(http.StatusOK, blocks)
+}
+
+// blockSender is a sender address and how many of a block's transactions it sent
+type blockSender struct {
+	Address          string `json:"address"`
+	TransactionCount int    `json:"transactionCount"`
+}
+
+// getBlockSenders handles requests for the distinct senders of a block's
+// transactions, in order of first appearance, with their transaction counts
+func (s *EnhancedServer) getBlockSenders(c *gin.Context) {
+	blockNumberParam := c.Param("number")
+
+	formattedBlockNumber, err := validateAndFormatBlockNumber(blockNumberParam)
+	if err != nil {
+		c.Error(errors.Wrap(err, errors.ErrorTypeValidation, "Invalid block number format").
+			WithCode(errors.CodeInvalidBlockNumber))
+		return
+	}
+
+	client, _ := s.clientFor(c)
+
+	// Start metrics timer
+	start := time.Now()
+
+	block, err := client.GetBlockByNumberCtx(c.Request.Context(), formattedBlockNumber)
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		metrics.RPCRequestsTotal.WithLabelValues("eth_getBlockByNumber", "error").Inc()
+		if errors.IsType(err, errors.ErrorTypeNotFound) {
+			c.Error(err)
+			return
+		}
+		logger.Error("Failed to get block for senders",
+			zap.String("block_number", formattedBlockNumber),
+			zap.Error(err))
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get block data").
+			WithData(map[string]interface{}{"block_number": formattedBlockNumber}))
+		return
+	}
+	metrics.RPCRequestsTotal.WithLabelValues("eth_getBlockByNumber", "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues("eth_getBlockByNumber").Observe(duration)
+
+	senders := blockSenders(block.Transactions)
+	c.JSON(http.StatusOK, gin.H{
+		"blockNumber":      block.Number,
+		"transactionCount": len(block.Transactions),
+		"senders":          senders,
+	})
+}
+
+// blockSenders deduplicates the senders of transactions, compared
+// case-insensitively and reported in lowercase, keeping first-seen order
+func blockSenders(transactions []models.Transaction) []blockSender {
+	senders := make([]blockSender, 0)
+	positions := make(map[string]int)
+	for _, tx := range transactions {
+		address := strings.ToLower(tx.From)
+		if i, ok := positions[address]; ok {
+			senders[i].TransactionCount++
+			continue
+		}
+		positions[address] = len(senders)
+		senders = append(senders, blockSender{Address: address, TransactionCount: 1})
+	}
+	return senders
+}
+
+// firstBatchError returns the first entry error of a partially failed batch,
+// or err itself when it is not a batch error
+func firstBatchError(err error) error {
+	if batchErr, ok := err.(interface{ Unwrap() []error }); ok {
+		if errs := batchErr.Unwrap(); len(errs) > 0 {
+			return errs[0]
+		}
+	}
+	return err
+}
+
+// lookupBlock handles block requests identified by either ?number= or ?hash=,
+// dispatching number lookups to getBlockByNumber
+func (s *EnhancedServer) lookupBlock(c *gin.Context) {
+	number, hasNumber := c.GetQuery("number")
+	hash, hasHash := c.GetQuery("hash")
+	if hasNumber == hasHash {
+		c.Error(errors.NewValidationError("Exactly one of number or hash must be given", nil))
+		return
+	}
+
+	if hasNumber {
+		c.Params = append(c.Params, gin.Param{Key: "number", Value: number})
+		s.getBlockByNumber(c)
+		return
+	}
+	c.Params = append(c.Params, gin.Param{Key: "hash", Value: hash})
+	s.getBlockByHash(c)
+}
+
+// parseFullParam parses the optional ?full= query parameter of block requests,
+// which defaults to true
+func parseFullParam(c *gin.Context) (bool, error) {
+	return parseFullParamOr(c, true)
+}
+
+// parseFullParamOr parses the optional ?full= query parameter, defaulting to
+// def when it is absent
+func parseFullParamOr(c *gin.Context, def bool) (bool, error) {
+	param := c.Query("full")
+	if param == "" {
+		return def, nil
+	}
+	full, err := strconv.ParseBool(param)
+	if err != nil {
+		return false, errors.NewValidationError("Invalid full parameter, expected true or false", err)
+	}
+	return full, nil
+}
+
+// getBlockByHash handles requests for a block by hash. Only the header and
+// transaction hashes are fetched when ?full=false is given.
+func (s *EnhancedServer) getBlockByHash(c *gin.Context) {
+	hash := c.Param("hash")
+	if err := validateHash(hash); err != nil {
+		c.Error(err)
+		return
+	}
+
+	full, err := parseFullParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	timeFormat, err := parseTimeFormat(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	client, _ := s.clientFor(c)
+
+	// Start metrics timer
+	start := time.Now()
+
+	block, err := client.GetBlockByHash(c.Request.Context(), hash, full)
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		metrics.RPCRequestsTotal.WithLabelValues("eth_getBlockByHash", "error").Inc()
+		if errors.IsType(err, errors.ErrorTypeNotFound) {
+			c.Error(err)
+			return
+		}
+		logger.Error("Failed to get block by hash",
+			zap.String("block_hash", hash),
+			zap.Error(err))
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get block data").
+			WithData(map[string]interface{}{"block_hash": hash}))
+		return
+	}
+	metrics.RPCRequestsTotal.WithLabelValues("eth_getBlockByHash", "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues("eth_getBlockByHash").Observe(duration)
+
+	if notModified(c, blockETag(block.Number, block.Hash, full, timeFormat, nil)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, formatBlockTimestamp(block, timeFormat))
+}
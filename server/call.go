@@ -0,0 +1,134 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// call handles eth_call requests whose call object is posted as JSON
+func (s *EnhancedServer) call(c *gin.Context) {
+	var msg models.CallMsg
+	if err := c.ShouldBindJSON(&msg); err != nil {
+		c.Error(errors.NewValidationError("Request body must be a JSON call object", err))
+		return
+	}
+	if err := validateCallMsg(&msg, false); err != nil {
+		c.Error(err)
+		return
+	}
+
+	blockTag := c.DefaultQuery("block", "latest")
+
+	logger.Debug("Call requested",
+		zap.String("to", msg.To),
+		zap.String("block", blockTag))
+
+	client, _ := s.clientFor(c)
+
+	// Start metrics timer
+	start := time.Now()
+
+	result, err := client.Call(c.Request.Context(), msg, blockTag)
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		// Invalid input is rejected before any RPC call is made
+		if errors.IsType(err, errors.ErrTypeValidation) {
+			logger.Warn("Invalid call request",
+				zap.String("to", msg.To),
+				zap.String("block", blockTag),
+				zap.Error(err))
+			c.Error(err)
+			return
+		}
+
+		// A revert is a successful round trip with an unfavourable answer
+		if errors.IsType(err, errors.ErrTypeReverted) {
+			metrics.RPCRequestsTotal.WithLabelValues("eth_call", "success").Inc()
+			metrics.RPCRequestDuration.WithLabelValues("eth_call").Observe(duration)
+			c.Error(err)
+			return
+		}
+
+		metrics.RPCRequestsTotal.WithLabelValues("eth_call", "error").Inc()
+		logger.Error("Failed to execute call", zap.String("to", msg.To), zap.Error(err))
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to execute call").
+			WithData(map[string]interface{}{"to": msg.To}))
+		return
+	}
+
+	// Record successful RPC metrics
+	metrics.RPCRequestsTotal.WithLabelValues("eth_call", "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues("eth_call").Observe(duration)
+
+	c.JSON(http.StatusOK, gin.H{
+		"to":     msg.To,
+		"block":  blockTag,
+		"result": result,
+	})
+}
+
+// callMsgFromQuery builds an eth_call request from query parameters so
+// idempotent contract reads can be served over cacheable GET routes. The to
+// and data parameters are required; from, value, gas and gasPrice are optional.
+func callMsgFromQuery(c *gin.Context) (*models.CallMsg, error) {
+	call := &models.CallMsg{
+		To:       c.Query("to"),
+		Data:     c.Query("data"),
+		From:     c.Query("from"),
+		Value:    c.Query("value"),
+		Gas:      c.Query("gas"),
+		GasPrice: c.Query("gasPrice"),
+	}
+
+	if err := validateCallMsg(call, true); err != nil {
+		return nil, err
+	}
+
+	return call, nil
+}
+
+// validateCallMsg checks that a call object has a well-formed to address, and
+// data when dataRequired, and that any optional fields present are
+// well-formed hex. Every invalid field is reported, not just the first.
+func validateCallMsg(call *models.CallMsg, dataRequired bool) error {
+	var fields errors.FieldErrors
+
+	if call.To == "" {
+		fields.Add("to", "to is required")
+	} else {
+		fields.AddError("to", validateAddressParam("to", call.To))
+	}
+
+	if call.Data != "" {
+		fields.AddError("data", validateDataParam("data", call.Data))
+	} else if dataRequired {
+		fields.Add("data", "data is required")
+	}
+	if call.From != "" {
+		fields.AddError("from", validateAddressParam("from", call.From))
+	}
+
+	quantities := []struct{ name, value string }{
+		{"value", call.Value},
+		{"gas", call.Gas},
+		{"gasPrice", call.GasPrice},
+	}
+	for _, quantity := range quantities {
+		if quantity.value == "" {
+			continue
+		}
+		fields.AddError(quantity.name, validateQuantityParam(quantity.name, quantity.value))
+	}
+
+	return fields.Err()
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// queryContext returns a gin context for a GET request with the given query string
+func queryContext(query string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodGet, "/call?"+query, nil)
+	return c
+}
+
+func TestCallMsgFromQuery(t *testing.T) {
+	const token = "0xc2132d05d31c914a87c6611c10748aeb04b58e8f"
+	const holder = "0xa7d9ddbe1f17865597fbd27ec712455208b6b76d"
+	const balanceOf = "0x70a08231000000000000000000000000a7d9ddbe1f17865597fbd27ec712455208b6b76d"
+
+	call, err := callMsgFromQuery(queryContext("to=" + token + "&data=" + balanceOf))
+	assert.NoError(t, err)
+	assert.Equal(t, &models.CallMsg{To: token, Data: balanceOf}, call)
+
+	call, err = callMsgFromQuery(queryContext("to=" + token + "&data=" + balanceOf + "&from=" + holder + "&value=0x0&gas=0x5208"))
+	assert.NoError(t, err)
+	assert.Equal(t, &models.CallMsg{From: holder, To: token, Gas: "0x5208", Value: "0x0", Data: balanceOf}, call)
+
+	invalid := map[string]string{
+		"missing to":      "data=" + balanceOf,
+		"missing data":    "to=" + token,
+		"short to":        "to=0x1234&data=" + balanceOf,
+		"odd length data": "to=" + token + "&data=0x70a",
+		"unprefixed data": "to=" + token + "&data=70a08231",
+		"invalid from":    "to=" + token + "&data=" + balanceOf + "&from=holder",
+		"non-hex value":   "to=" + token + "&data=" + balanceOf + "&value=100",
+		"empty gas":       "to=" + token + "&data=" + balanceOf + "&gas=0x",
+	}
+	for name, query := range invalid {
+		_, err := callMsgFromQuery(queryContext(query))
+		assert.True(t, errors.IsType(err, errors.ErrTypeValidation), name)
+	}
+}
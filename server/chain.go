@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// getChainID handles requests for the chain ID the node currently reports
+func (s *EnhancedServer) getChainID(c *gin.Context) {
+	client, _ := s.clientFor(c)
+
+	// Start metrics timer
+	start := time.Now()
+
+	chainID, err := client.ChainID(c.Request.Context())
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		metrics.RPCRequestsTotal.WithLabelValues("eth_chainId", "error").Inc()
+		logger.Error("Failed to get chain ID", zap.Error(err))
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get chain ID"))
+		return
+	}
+	metrics.RPCRequestsTotal.WithLabelValues("eth_chainId", "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues("eth_chainId").Observe(duration)
+
+	id, err := hexutil.DecodeBig(chainID)
+	if err != nil {
+		c.Error(errors.NewBlockchainError("Node returned an invalid chain ID", err).
+			WithData(map[string]interface{}{"chain_id": chainID}))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chainId":        chainID,
+		"chainIdDecimal": id.String(),
+	})
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// coalescedResponse is a serialized response shared by identical requests
+type coalescedResponse struct {
+	body []byte
+	// hash is the hash of the block in body, for its entity tag
+	hash string
+}
+
+// coalescedCall is an in-flight computation shared by identical requests
+type coalescedCall struct {
+	done     chan struct{}
+	response coalescedResponse
+	err      error
+	// panicked holds the value fn panicked with, if it did
+	panicked interface{}
+}
+
+// responseGroup lets concurrent identical requests share one downstream fetch
+// and one serialized response. Unlike a cache it holds nothing once the first
+// request completes, so later requests always fetch afresh.
+type responseGroup struct {
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+	// coalesced counts requests that joined an in-flight call
+	coalesced prometheus.Counter
+	// timeout bounds each shared call; zero leaves it unbounded
+	timeout time.Duration
+}
+
+// do runs fn for key unless an identical call is already in flight, and waits
+// for the result. Every request, including the one that started the call,
+// stops waiting with a timeout error once its own ctx is done. Since other
+// requests may still depend on it, the call itself runs in the background on a
+// context that keeps ctx's values but not its cancellation, bounded by the
+// group's own timeout instead.
+func (g *responseGroup) do(ctx context.Context, key string, fn func(context.Context) (coalescedResponse, error)) (coalescedResponse, error) {
+	g.mu.Lock()
+	call, joined := g.calls[key]
+	if joined {
+		g.mu.Unlock()
+		if g.coalesced != nil {
+			g.coalesced.Inc()
+		}
+	} else {
+		if g.calls == nil {
+			g.calls = make(map[string]*coalescedCall)
+		}
+		call = &coalescedCall{done: make(chan struct{})}
+		g.calls[key] = call
+		g.mu.Unlock()
+		go g.run(ctx, key, call, fn)
+	}
+
+	select {
+	case <-call.done:
+		// A panic goes on to the recovery of the request that started the call
+		if !joined && call.panicked != nil {
+			panic(call.panicked)
+		}
+		return call.response, call.err
+	case <-ctx.Done():
+		return coalescedResponse{}, errors.NewTimeoutError("Shared request cancelled", ctx.Err())
+	}
+}
+
+// run calls fn for the call of key and then releases everyone waiting on it.
+// Waiters are released even if fn panics, with an error rather than an empty
+// response.
+func (g *responseGroup) run(ctx context.Context, key string, call *coalescedCall, fn func(context.Context) (coalescedResponse, error)) {
+	defer func() {
+		if p := recover(); p != nil {
+			logger.Error("Shared request panicked", zap.String("key", key), zap.Any("error", p))
+			call.panicked = p
+			call.err = errors.NewInternalError("Shared request failed", nil)
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+
+	ctx = context.WithoutCancel(ctx)
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+
+	call.response, call.err = fn(ctx)
+}
+
+// normalizeBlockKey returns a canonical spelling of a formatted block number so
+// that, for example, 0x0a and 0xA coalesce. Tags are returned unchanged.
+func normalizeBlockKey(blockNumber string) string {
+	if number, err := hexutil.DecodeUint64(blockNumber); err == nil {
+		return hexutil.EncodeUint64(number)
+	}
+	return blockNumber
+}
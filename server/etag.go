@@ -0,0 +1,77 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"blockchain-client/pkg/hexutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// blockETag returns the entity tag of a block looked up by blockNumber: its
+// hash, which changes if a reorg replaces the block, followed by the variant
+// of its representation, since header-only, rfc3339 and paged responses
+// differ from the full block. Blocks looked up by tag, such as "latest", get
+// none, since the tag moves on to other blocks.
+func blockETag(blockNumber, hash string, full bool, timeFormat string, page *transactionPage) string {
+	if hash == "" {
+		return ""
+	}
+	if _, err := hexutil.DecodeUint64(blockNumber); err != nil {
+		return ""
+	}
+	tag := strings.ToLower(hash)
+	if !full {
+		tag += "-header"
+	}
+	if timeFormat != "" {
+		tag += "-" + timeFormat
+	}
+	if page != nil {
+		tag += fmt.Sprintf("-tx%d.%d", page.offset, page.limit)
+	}
+	return `"` + tag + `"`
+}
+
+// transactionETag returns the entity tag of a transaction or its receipt: the
+// transaction hash together with the hash of the block including it, since a
+// reorg can move the transaction into another block. Pending transactions get
+// none, since they change once mined.
+func transactionETag(hash, blockHash string) string {
+	if hash == "" || blockHash == "" {
+		return ""
+	}
+	return `"` + strings.ToLower(hash) + "-" + strings.ToLower(blockHash) + `"`
+}
+
+// notModified sets the ETag header when etag is not empty and reports whether
+// the request's If-None-Match lists it, in which case an empty 304 has been
+// written and the caller must not write a body
+func notModified(c *gin.Context, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 requires for If-None-Match
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// feeHistoryBlocks is how many recent blocks fee tiers are computed from
+const feeHistoryBlocks = 20
+
+// feeTiers names the fee recommendation tiers, each computed from the given
+// percentile of the priority fees paid in recent blocks
+var feeTiers = []struct {
+	name       string
+	percentile float64
+}{
+	{"slow", 10},
+	{"standard", 50},
+	{"fast", 90},
+}
+
+// Fee sources report what the tiers' priority fees were computed from
+const (
+	feeSourceFeeHistory  = "feeHistory"
+	feeSourcePriorityFee = "maxPriorityFeePerGas"
+	feeSourceGasPrice    = "gasPrice"
+)
+
+// feeTier is one fee recommendation. On chains without EIP-1559 both fees are
+// the legacy gas price.
+type feeTier struct {
+	MaxFeePerGas                string `json:"maxFeePerGas"`
+	MaxFeePerGasDecimal         string `json:"maxFeePerGasDecimal"`
+	MaxPriorityFeePerGas        string `json:"maxPriorityFeePerGas"`
+	MaxPriorityFeePerGasDecimal string `json:"maxPriorityFeePerGasDecimal"`
+}
+
+// newFeeTier creates a tier from fees in wei
+func newFeeTier(maxFee, priorityFee *big.Int) feeTier {
+	return feeTier{
+		MaxFeePerGas:                hexutil.EncodeBig(maxFee),
+		MaxFeePerGasDecimal:         maxFee.String(),
+		MaxPriorityFeePerGas:        hexutil.EncodeBig(priorityFee),
+		MaxPriorityFeePerGasDecimal: priorityFee.String(),
+	}
+}
+
+// getFees handles requests for slow, standard and fast fee recommendations.
+// Priority fees come from percentiles of recent blocks' fee history, falling
+// back to the node's suggested priority fee. Each tier's maxFeePerGas leaves
+// room for the base fee to double. Chains without EIP-1559 get the legacy gas
+// price in every tier.
+func (s *EnhancedServer) getFees(c *gin.Context) {
+	client, _ := s.clientFor(c)
+	ctx := c.Request.Context()
+
+	gasPriceHex, err := client.GasPrice(ctx)
+	if err != nil {
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get gas price"))
+		return
+	}
+	gasPrice, err := decodeFee("gasPrice", gasPriceHex)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	header, err := client.GetBlockHeaderByNumber(ctx, "latest")
+	if err != nil {
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get latest block"))
+		return
+	}
+
+	response := gin.H{
+		"blockNumber":     header.Number,
+		"gasPrice":        gasPriceHex,
+		"gasPriceDecimal": gasPrice.String(),
+	}
+
+	// Blocks before the London fork have no base fee
+	if header.BaseFeePerGas == "" {
+		response["eip1559"] = false
+		response["source"] = feeSourceGasPrice
+		for _, tier := range feeTiers {
+			response[tier.name] = newFeeTier(gasPrice, gasPrice)
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	baseFee, err := decodeFee("baseFeePerGas", header.BaseFeePerGas)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response["eip1559"] = true
+	response["baseFeePerGas"] = header.BaseFeePerGas
+	response["baseFeePerGasDecimal"] = baseFee.String()
+
+	// Some EIP-1559 chains do not offer the suggested priority fee
+	var suggested *big.Int
+	suggestedHex, err := client.MaxPriorityFeePerGas(ctx)
+	switch {
+	case errors.IsType(err, errors.ErrTypeUnsupported):
+		logger.Debug("Node does not support priority fees", zap.Error(err))
+	case err != nil:
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get priority fee"))
+		return
+	default:
+		if suggested, err = decodeFee("maxPriorityFeePerGas", suggestedHex); err != nil {
+			c.Error(err)
+			return
+		}
+		response["maxPriorityFeePerGas"] = suggestedHex
+		response["maxPriorityFeePerGasDecimal"] = suggested.String()
+	}
+
+	priorityFees, ok := feeHistoryPriorityFees(ctx, client)
+	source := feeSourceFeeHistory
+	if !ok {
+		fallback := suggested
+		source = feeSourcePriorityFee
+		if fallback == nil {
+			// The gas price covers the base fee plus a typical priority fee
+			fallback = new(big.Int).Sub(gasPrice, baseFee)
+			if fallback.Sign() < 0 {
+				fallback.SetInt64(0)
+			}
+			source = feeSourceGasPrice
+		}
+		priorityFees = make([]*big.Int, len(feeTiers))
+		for i := range priorityFees {
+			priorityFees[i] = fallback
+		}
+	}
+	response["source"] = source
+
+	headroom := new(big.Int).Mul(baseFee, big.NewInt(2))
+	for i, tier := range feeTiers {
+		maxFee := new(big.Int).Add(headroom, priorityFees[i])
+		response[tier.name] = newFeeTier(maxFee, priorityFees[i])
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// feeHistoryPriorityFees returns the priority fee for each tier, the median
+// across recent non-empty blocks of the fee paid at the tier's percentile. It
+// reports false when the node has no usable fee history.
+func feeHistoryPriorityFees(ctx context.Context, client EnhancedBlockchainClient) ([]*big.Int, bool) {
+	percentiles := make([]float64, len(feeTiers))
+	for i, tier := range feeTiers {
+		percentiles[i] = tier.percentile
+	}
+
+	history, err := client.FeeHistory(ctx, feeHistoryBlocks, "latest", percentiles)
+	if err != nil {
+		if !errors.IsType(err, errors.ErrTypeUnsupported) {
+			logger.Warn("Failed to get fee history, falling back to the suggested priority fee", zap.Error(err))
+		}
+		return nil, false
+	}
+
+	fees := make([]*big.Int, len(feeTiers))
+	for i := range feeTiers {
+		var samples []*big.Int
+		for block, rewards := range history.Reward {
+			// Empty blocks report zero rewards that say nothing about the going rate
+			if block < len(history.GasUsedRatio) && history.GasUsedRatio[block] == 0 {
+				continue
+			}
+			if i >= len(rewards) {
+				continue
+			}
+			reward, err := hexutil.DecodeBig(rewards[i])
+			if err != nil {
+				logger.Warn("Skipping malformed fee history reward", zap.Error(err))
+				continue
+			}
+			samples = append(samples, reward)
+		}
+		if len(samples) == 0 {
+			return nil, false
+		}
+
+		sort.Slice(samples, func(a, b int) bool { return samples[a].Cmp(samples[b]) < 0 })
+		fees[i] = samples[len(samples)/2]
+	}
+
+	// Medians taken per percentile can cross, so keep faster tiers at least as high
+	for i := 1; i < len(fees); i++ {
+		if fees[i].Cmp(fees[i-1]) < 0 {
+			fees[i] = fees[i-1]
+		}
+	}
+	return fees, true
+}
+
+// decodeFee decodes a fee the recommendations are computed from. Unlike
+// decodeWei it always fails on malformed values, since no tier can be computed
+// without them.
+func decodeFee(field, value string) (*big.Int, error) {
+	fee, err := hexutil.DecodeBigField(field, value)
+	if err != nil {
+		return nil, errors.NewBlockchainError(fmt.Sprintf("Node returned a malformed %s", field), err).
+			WithData(map[string]interface{}{"field": field})
+	}
+	return fee, nil
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// getGasPrice handles requests for the current legacy gas price and EIP-1559
+// priority fee. The priority fee is omitted for nodes that do not support it.
+func (s *EnhancedServer) getGasPrice(c *gin.Context) {
+	client, _ := s.clientFor(c)
+	ctx := c.Request.Context()
+
+	// Start metrics timer
+	start := time.Now()
+
+	gasPrice, err := client.GasPrice(ctx)
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		metrics.RPCRequestsTotal.WithLabelValues("eth_gasPrice", "error").Inc()
+		logger.Error("Failed to get gas price", zap.Error(err))
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get gas price"))
+		return
+	}
+	metrics.RPCRequestsTotal.WithLabelValues("eth_gasPrice", "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues("eth_gasPrice").Observe(duration)
+
+	decodeErrors := make(map[string]string)
+	gasPriceDecimal, err := s.decodeWei("gasPrice", gasPrice, decodeErrors)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response := gin.H{
+		"gasPrice":        gasPrice,
+		"gasPriceDecimal": gasPriceDecimal,
+	}
+
+	start = time.Now()
+	priorityFee, err := client.MaxPriorityFeePerGas(ctx)
+	duration = time.Since(start).Seconds()
+	switch {
+	case errors.IsType(err, errors.ErrTypeUnsupported):
+		// Chains without EIP-1559 only have a legacy gas price
+		metrics.RPCRequestsTotal.WithLabelValues("eth_maxPriorityFeePerGas", "unsupported").Inc()
+		logger.Debug("Node does not support priority fees", zap.Error(err))
+	case err != nil:
+		metrics.RPCRequestsTotal.WithLabelValues("eth_maxPriorityFeePerGas", "error").Inc()
+		logger.Error("Failed to get priority fee", zap.Error(err))
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get priority fee"))
+		return
+	default:
+		metrics.RPCRequestsTotal.WithLabelValues("eth_maxPriorityFeePerGas", "success").Inc()
+		metrics.RPCRequestDuration.WithLabelValues("eth_maxPriorityFeePerGas").Observe(duration)
+
+		priorityFeeDecimal, err := s.decodeWei("maxPriorityFeePerGas", priorityFee, decodeErrors)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		response["maxPriorityFeePerGas"] = priorityFee
+		response["maxPriorityFeePerGasDecimal"] = priorityFeeDecimal
+	}
+
+	if len(decodeErrors) > 0 {
+		response["decodeErrors"] = decodeErrors
+	}
+	c.JSON(http.StatusOK, response)
+}
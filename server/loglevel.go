@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net/http"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// logLevelRequest is the request body for changing the log level
+type logLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// getLogLevel handles requests for the current log level
+func (s *EnhancedServer) getLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"level": logger.Level()})
+}
+
+// setLogLevel handles requests to change the log level at runtime
+func (s *EnhancedServer) setLogLevel(c *gin.Context) {
+	var req logLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewValidationError("Request body must be a JSON object with a level", err))
+		return
+	}
+
+	previous := logger.Level()
+	if err := logger.SetLevel(req.Level); err != nil {
+		c.Error(errors.NewValidationError("Log level must be one of debug, info, warn or error", err).
+			WithData(map[string]interface{}{"level": req.Level}))
+		return
+	}
+
+	// Logged at warn so the change is recorded whatever the new level is
+	logger.Warn("Log level changed",
+		zap.String("previous", previous),
+		zap.String("level", req.Level),
+		zap.String("client_ip", c.ClientIP()))
+
+	c.JSON(http.StatusOK, gin.H{"level": logger.Level()})
+}
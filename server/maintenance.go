@@ -0,0 +1,86 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DefaultMaintenanceRetryAfter is the Retry-After hint sent by API routes in
+// maintenance mode
+const DefaultMaintenanceRetryAfter = 60 * time.Second
+
+// maintenanceMode is the switch that takes the API routes out of service
+type maintenanceMode struct {
+	enabled    atomic.Bool
+	retryAfter time.Duration
+}
+
+// maintenanceRequest is the request body for switching maintenance mode
+type maintenanceRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// SetMaintenance switches maintenance mode on or off. While it is on, API
+// routes answer 503 without calling the node, and /health, /ready and
+// /metrics keep working. source says what made the change and is logged
+// with every transition.
+func (s *EnhancedServer) SetMaintenance(enabled bool, source string) {
+	if s.maintenance.enabled.Swap(enabled) == enabled {
+		return
+	}
+	if enabled {
+		logger.Warn("Maintenance mode enabled, API requests will be rejected", zap.String("source", source))
+	} else {
+		logger.Warn("Maintenance mode disabled, API requests will be served", zap.String("source", source))
+	}
+}
+
+// InMaintenance reports whether maintenance mode is on
+func (s *EnhancedServer) InMaintenance() bool {
+	return s.maintenance.enabled.Load()
+}
+
+// maintenanceGuard returns a middleware that rejects requests with a 503 and a
+// Retry-After while maintenance mode is on
+func (s *EnhancedServer) maintenanceGuard() gin.HandlerFunc {
+	retryAfter := int(s.maintenance.retryAfter / time.Second)
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	return func(c *gin.Context) {
+		if !s.InMaintenance() {
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.Error(errors.NewUnavailableError("The API is down for maintenance", nil))
+		c.Abort()
+	}
+}
+
+// getMaintenance handles requests for the maintenance mode state
+func (s *EnhancedServer) getMaintenance(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"enabled": s.InMaintenance()})
+}
+
+// setMaintenance handles requests to switch maintenance mode on or off
+func (s *EnhancedServer) setMaintenance(c *gin.Context) {
+	var req maintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewValidationError("Request body must be a JSON object with an enabled flag", err))
+		return
+	}
+
+	s.SetMaintenance(*req.Enabled, "admin")
+	c.JSON(http.StatusOK, gin.H{"enabled": s.InMaintenance()})
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"strconv"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxTransactionPage is the most transactions a page of a block's
+// transactions may list
+const DefaultMaxTransactionPage = 1000
+
+// transactionPage selects a page of a block's transactions
+type transactionPage struct {
+	offset int
+	limit  int
+}
+
+// parseTransactionPage parses the optional ?tx_offset= and ?tx_limit= query
+// parameters. It returns nil when neither is given, so the block keeps all its
+// transactions. A missing or larger limit is capped at maxLimit.
+func parseTransactionPage(c *gin.Context, maxLimit int) (*transactionPage, error) {
+	offsetParam, hasOffset := c.GetQuery("tx_offset")
+	limitParam, hasLimit := c.GetQuery("tx_limit")
+	if !hasOffset && !hasLimit {
+		return nil, nil
+	}
+
+	page := &transactionPage{limit: maxLimit}
+	if hasOffset {
+		offset, err := strconv.Atoi(offsetParam)
+		if err != nil || offset < 0 {
+			return nil, errors.NewValidationError("tx_offset must be a non-negative integer", err).
+				WithData(map[string]interface{}{"tx_offset": offsetParam})
+		}
+		page.offset = offset
+	}
+	if hasLimit {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 0 {
+			return nil, errors.NewValidationError("tx_limit must be a non-negative integer", err).
+				WithData(map[string]interface{}{"tx_limit": limitParam})
+		}
+		if limit < page.limit {
+			page.limit = limit
+		}
+	}
+	return page, nil
+}
+
+// paginateTransactions returns the block listing only the page of its
+// transactions, full objects or hashes, along with the page's description.
+// The block is copied since it may be shared with the client's cache.
+// TransactionErrors are kept in full. A nil page returns the block as is.
+func paginateTransactions(block *models.Block, page *transactionPage) *models.Block {
+	if page == nil {
+		return block
+	}
+
+	paginated := *block
+	if block.TransactionHashes != nil {
+		paginated.TransactionHashes = pageOf(block.TransactionHashes, page)
+		paginated.Pagination = &models.TransactionPagination{Total: len(block.TransactionHashes)}
+	} else {
+		paginated.Transactions = pageOf(block.Transactions, page)
+		paginated.Pagination = &models.TransactionPagination{Total: len(block.Transactions)}
+	}
+	paginated.Pagination.Offset = page.offset
+	paginated.Pagination.Limit = page.limit
+	return &paginated
+}
+
+// pageOf returns the items of page, an empty slice when page starts past the end
+func pageOf[T any](items []T, page *transactionPage) []T {
+	if page.offset >= len(items) {
+		return []T{}
+	}
+	end := len(items)
+	if page.limit < end-page.offset {
+		end = page.offset + page.limit
+	}
+	return items[page.offset:end]
+}
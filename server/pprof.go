@@ -0,0 +1,32 @@
+package server
+
+import (
+	"net/http/pprof"
+
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerPprofRoutes serves the net/http/pprof profiles under /debug/pprof,
+// behind the admin token. Without a token every request is rejected, so
+// profiling is never exposed unauthenticated.
+func (s *EnhancedServer) registerPprofRoutes() {
+	if s.adminToken == "" {
+		logger.Warn("Profiling is enabled but no admin token is configured, so /debug/pprof rejects every request")
+	}
+
+	debug := s.router.Group("/debug/pprof", middleware.AdminAuth(s.adminToken))
+	debug.GET("/", gin.WrapF(pprof.Index))
+	debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	debug.GET("/profile", gin.WrapF(pprof.Profile))
+	debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+	debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+	debug.GET("/trace", gin.WrapF(pprof.Trace))
+
+	// Named profiles such as heap, goroutine, allocs, block, mutex and threadcreate
+	debug.GET("/:profile", func(c *gin.Context) {
+		pprof.Handler(c.Param("profile")).ServeHTTP(c.Writer, c.Request)
+	})
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DefaultRPCProxyMethods returns the methods the RPC proxy forwards by
+// default. All of them only read chain state; eth_getLogs is left out since a
+// single wide query can be expensive for the node.
+func DefaultRPCProxyMethods() []string {
+	return []string{
+		"eth_blockNumber",
+		"eth_chainId",
+		"eth_gasPrice",
+		"eth_maxPriorityFeePerGas",
+		"eth_feeHistory",
+		"eth_getBalance",
+		"eth_getCode",
+		"eth_getStorageAt",
+		"eth_getTransactionCount",
+		"eth_getBlockByNumber",
+		"eth_getBlockByHash",
+		"eth_getBlockTransactionCountByNumber",
+		"eth_getBlockTransactionCountByHash",
+		"eth_getTransactionByHash",
+		"eth_getTransactionReceipt",
+		"eth_call",
+		"eth_estimateGas",
+		"net_version",
+		"web3_clientVersion",
+	}
+}
+
+// proxyRPC handles JSON-RPC requests forwarded to the node. The method must be
+// on the proxy's allow-list; the node's response, including any JSON-RPC
+// error object, is returned verbatim.
+func (s *EnhancedServer) proxyRPC(c *gin.Context) {
+	var request models.RawRPCRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.NewValidationError("Request body must be a JSON-RPC request object", err))
+		return
+	}
+	if err := validateRawRPCRequest(&request); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if !s.proxyMethods[request.Method] {
+		logger.Warn("Rejected JSON-RPC method not on the proxy allow-list",
+			zap.String("rpc_method", request.Method),
+			zap.String("client_ip", c.ClientIP()))
+		c.Error(errors.NewPermissionError(fmt.Sprintf("Method %s is not allowed", request.Method), nil).
+			WithData(map[string]interface{}{"method": request.Method}))
+		return
+	}
+
+	client, _ := s.clientFor(c)
+	ctx := metrics.WithSource(c.Request.Context(), metrics.SourceProxy)
+
+	// Start metrics timer
+	start := time.Now()
+
+	response, err := client.RawCall(ctx, request)
+
+	// Record RPC metrics. Only allowed methods get here, which bounds the labels.
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		metrics.RPCRequestsTotal.WithLabelValues(request.Method, "error").Inc()
+		logger.Error("Failed to proxy JSON-RPC request", zap.String("rpc_method", request.Method), zap.Error(err))
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to proxy JSON-RPC request").
+			WithData(map[string]interface{}{"method": request.Method}))
+		return
+	}
+
+	metrics.RPCRequestsTotal.WithLabelValues(request.Method, "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues(request.Method).Observe(duration)
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", response)
+}
+
+// validateRawRPCRequest checks that a proxied request is a JSON-RPC 2.0 call
+// expecting a response: params, when given, are an array or object, and the
+// ID is a string or number
+func validateRawRPCRequest(request *models.RawRPCRequest) error {
+	var fields errors.FieldErrors
+
+	if request.JSONRPC != "2.0" {
+		fields.Add("jsonrpc", `jsonrpc must be "2.0"`)
+	}
+	if request.Method == "" {
+		fields.Add("method", "method is required")
+	}
+
+	params := bytes.TrimSpace(request.Params)
+	switch {
+	case bytes.Equal(params, []byte("null")):
+		// Omitted from the forwarded request, as it would be by the client
+		request.Params = nil
+	case len(params) > 0 && params[0] != '[' && params[0] != '{':
+		fields.Add("params", "params must be an array or object")
+	}
+
+	// Requests without an ID are notifications, which the node does not answer;
+	// the body was already decoded, so a present ID is valid JSON.
+	var id interface{}
+	if len(request.ID) > 0 {
+		_ = json.Unmarshal(request.ID, &id)
+	}
+	switch id.(type) {
+	case string, float64:
+	case nil:
+		fields.Add("id", "id is required")
+	default:
+		fields.Add("id", "id must be a string or number")
+	}
+
+	return fields.Err()
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// warmupRetryInterval is how long warmup waits between failed attempts
+var warmupRetryInterval = 2 * time.Second
+
+// readyRetryAfter is the Retry-After hint, in seconds, sent while warming up
+const readyRetryAfter = 5
+
+// readyCheckTimeout bounds the RPC health check made by a readiness probe
+const readyCheckTimeout = 2 * time.Second
+
+// readyCacheTTL is how long a successful health check answers readiness
+// probes before the node is checked again
+const readyCacheTTL = 2 * time.Second
+
+// readinessCache remembers the last successful health check
+type readinessCache struct {
+	mu        sync.Mutex
+	status    *models.HealthStatus
+	checkedAt time.Time
+}
+
+// get returns the cached status if it is still fresh
+func (r *readinessCache) get() (*models.HealthStatus, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.status == nil || time.Since(r.checkedAt) > readyCacheTTL {
+		return nil, false
+	}
+	return r.status, true
+}
+
+// set records a successful health check
+func (r *readinessCache) set(status *models.HealthStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+	r.checkedAt = time.Now()
+}
+
+// Warmup primes the client by fetching the latest block number and the chain
+// ID, retrying until both succeed or ctx is done, and then marks the server
+// ready. Start runs it in the background so /ready reports 503 until the client
+// can serve traffic. The chain ID seen here is what later probes must match.
+func (s *EnhancedServer) Warmup(ctx context.Context) error {
+	for attempt := 1; ; attempt++ {
+		blockNumber, err := s.prime(ctx)
+		if err == nil {
+			s.started.Store(true)
+			logger.Info("Warmup complete, server is ready",
+				zap.String("block_number", blockNumber),
+				zap.String("chain_id", s.chainID),
+				zap.Int("attempts", attempt))
+			return nil
+		}
+
+		logger.Warn("Warmup attempt failed",
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", warmupRetryInterval),
+			zap.Error(err))
+
+		timer := time.NewTimer(warmupRetryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// prime fetches the latest block number and records the startup chain ID
+func (s *EnhancedServer) prime(ctx context.Context) (string, error) {
+	blockNumber, err := s.client.GetLatestBlockNumberCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	if height, err := hexutil.DecodeUint64(blockNumber); err == nil {
+		metrics.UpdateBlockchainHeight(float64(height))
+		s.client.SetHead(height)
+	}
+
+	chainID, err := s.client.ChainID(ctx)
+	if errors.IsType(err, errors.ErrTypeUnsupported) {
+		// Without a chain ID there is nothing to compare probes against
+		logger.Warn("Node does not report a chain ID; chain changes will not be detected")
+		return blockNumber, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	s.chainID = chainID
+	return blockNumber, nil
+}
+
+// ready handles readiness probes. It reports 503 until warmup has completed
+// and afterwards whenever the RPC endpoint fails its health check or reports
+// a different chain ID than it did during warmup.
+func (s *EnhancedServer) ready(c *gin.Context) {
+	if !s.started.Load() {
+		c.Header("Retry-After", strconv.Itoa(readyRetryAfter))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "starting"})
+		return
+	}
+
+	status, cached := s.readiness.get()
+	if !cached {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readyCheckTimeout)
+		defer cancel()
+
+		var err error
+		status, err = s.client.HealthStatus(ctx)
+		if err != nil || !status.Healthy {
+			detail := status.Description
+			if err != nil {
+				detail = err.Error()
+			}
+			logger.Warn("Readiness check failed", zap.String("detail", detail))
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "unavailable",
+				"error":  status.Description,
+				"detail": detail,
+			})
+			return
+		}
+		// A provider behind a load balancer can silently start serving another chain
+		if s.chainID != "" {
+			chainID, err := s.client.ChainID(ctx)
+			if err != nil {
+				logger.Warn("Readiness check failed", zap.Error(err))
+				c.JSON(http.StatusServiceUnavailable, gin.H{
+					"status": "unavailable",
+					"error":  "Failed to get chain ID",
+					"detail": err.Error(),
+				})
+				return
+			}
+			if !strings.EqualFold(chainID, s.chainID) {
+				logger.Error("Connected chain changed since startup",
+					zap.String("expected_chain_id", s.chainID),
+					zap.String("chain_id", chainID))
+				c.JSON(http.StatusServiceUnavailable, gin.H{
+					"status": "unavailable",
+					"error":  "Connected to a different chain than at startup",
+					"detail": fmt.Sprintf("chain ID %s, expected %s", chainID, s.chainID),
+				})
+				return
+			}
+		}
+		s.readiness.set(status)
+	}
+
+	response := gin.H{
+		"status":    "ready",
+		"chain":     status.ChainName,
+		"networkId": status.NetworkID,
+	}
+	if status.PeerCount != nil {
+		response["peerCount"] = *status.PeerCount
+	}
+	if status.ClientVersion != "" {
+		response["clientVersion"] = status.ClientVersion
+	}
+	if status.Warning != "" {
+		response["warning"] = status.Warning
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"blockchain-client/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// chainClientKey is the gin context key holding the client resolved for a chain route
+const chainClientKey = "chain_client"
+
+// chainNameKey is the gin context key holding the chain name of a chain route
+const chainNameKey = "chain_name"
+
+// ClientRegistry maps chain names to the blockchain clients serving them
+type ClientRegistry struct {
+	mu      sync.RWMutex
+	clients map[string]EnhancedBlockchainClient
+}
+
+// NewClientRegistry creates an empty client registry
+func NewClientRegistry() *ClientRegistry {
+	return &ClientRegistry{
+		clients: make(map[string]EnhancedBlockchainClient),
+	}
+}
+
+// Register adds or replaces the client for a chain. Chain names are case-insensitive.
+func (r *ClientRegistry) Register(chain string, client EnhancedBlockchainClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[strings.ToLower(chain)] = client
+}
+
+// Get returns the client registered for a chain
+func (r *ClientRegistry) Get(chain string) (EnhancedBlockchainClient, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	client, ok := r.clients[strings.ToLower(chain)]
+	return client, ok
+}
+
+// Chains returns the sorted names of all registered chains
+func (r *ClientRegistry) Chains() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	chains := make([]string, 0, len(r.clients))
+	for chain := range r.clients {
+		chains = append(chains, chain)
+	}
+	sort.Strings(chains)
+	return chains
+}
+
+// resolveChain returns a middleware that looks up the client for the :chain
+// route parameter, aborting with a not found error for unknown chains
+func (r *ClientRegistry) resolveChain() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chain := c.Param("chain")
+
+		client, ok := r.Get(chain)
+		if !ok {
+			c.Error(errors.NewNotFoundError("Unknown chain", nil).WithCode(errors.CodeUnknownChain).
+				WithData(map[string]interface{}{"chain": chain, "available_chains": r.Chains()}))
+			c.Abort()
+			return
+		}
+
+		c.Set(chainClientKey, client)
+		c.Set(chainNameKey, strings.ToLower(chain))
+		c.Next()
+	}
+}
+
+// clientFor returns the client serving a request along with its chain name.
+// Requests outside the chain routes use the server's default client and an empty chain name.
+func (s *EnhancedServer) clientFor(c *gin.Context) (EnhancedBlockchainClient, string) {
+	if client, ok := c.Get(chainClientKey); ok {
+		return client.(EnhancedBlockchainClient), c.GetString(chainNameKey)
+	}
+	return s.client, ""
+}
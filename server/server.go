@@ -1,13 +1,18 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"blockchain-client/models"
 	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
 	"blockchain-client/pkg/logger"
 	"blockchain-client/pkg/metrics"
 	"blockchain-client/pkg/middleware"
@@ -20,34 +25,224 @@ import (
 type BlockchainClient interface {
 	GetLatestBlockNumber() (string, error)
 	GetBlockByNumber(blockNumber string) (*models.Block, error)
+	GetLatestBlockNumberCtx(ctx context.Context) (string, error)
+	GetBlockByNumberCtx(ctx context.Context, blockNumber string) (*models.Block, error)
 }
 
 // EnhancedBlockchainClient interface for blockchain operations with metrics support
 type EnhancedBlockchainClient interface {
 	BlockchainClient
-	// Additional methods can be added as needed
+	GetLatestBlockFull(ctx context.Context, includeTransactions bool) (*models.Block, error)
+	GetBlockHeaderByNumber(ctx context.Context, blockNumber string) (*models.Block, error)
+	GetBlockByHash(ctx context.Context, hash string, includeTransactions bool) (*models.Block, error)
+	GetTransactionByHash(ctx context.Context, hash string) (*models.Transaction, error)
+	GetTransactionReceipt(ctx context.Context, hash string) (*models.TransactionReceipt, error)
+	SendRawTransaction(ctx context.Context, signedTxHex string) (string, error)
+	GetBalance(ctx context.Context, address, blockTag string) (string, error)
+	BatchGetBalances(ctx context.Context, addresses []string, blockTag string) ([]string, error)
+	GetCode(ctx context.Context, address, blockTag string) (string, error)
+	Call(ctx context.Context, msg models.CallMsg, blockTag string) (string, error)
+	RawCall(ctx context.Context, req models.RawRPCRequest) (json.RawMessage, error)
+	BatchGetBlocksByNumber(ctx context.Context, blockNumbers []string, includeTransactions bool) ([]*models.Block, error)
+	GasPrice(ctx context.Context) (string, error)
+	MaxPriorityFeePerGas(ctx context.Context) (string, error)
+	FeeHistory(ctx context.Context, blockCount uint64, newestBlock string, rewardPercentiles []float64) (*models.FeeHistory, error)
+	GetTokenTransfers(ctx context.Context, blockNumber string) ([]models.TokenTransfer, int, error)
+	// SetHead records the latest observed chain head for finality decisions
+	SetHead(head uint64)
+	HealthStatus(ctx context.Context) (*models.HealthStatus, error)
+	ChainID(ctx context.Context) (string, error)
 }
 
 // EnhancedServer represents the HTTP server with enhanced features
 type EnhancedServer struct {
 	router  *gin.Engine
 	client  EnhancedBlockchainClient
+	chains  *ClientRegistry
 	address string
+
+	// started is set once warmup has primed the client
+	started atomic.Bool
+
+	// readiness caches the last successful readiness health check
+	readiness readinessCache
+
+	// chainID is the chain ID seen during warmup. It is written before started
+	// is set and only read afterwards, so it needs no further synchronization.
+	chainID string
+
+	// gasStats caches gas statistics until the chain head moves
+	gasStats gasStatsCache
+	// blockRequests coalesces concurrent identical block-by-number requests
+	blockRequests responseGroup
+
+	// strictDecoding fails requests on malformed wei amounts from the node
+	strictDecoding bool
+
+	// maxBlockRange caps how many blocks a block range request may return
+	maxBlockRange int
+
+	// maxTransactionPage caps how many transactions a page of a block's
+	// transactions may list
+	maxTransactionPage int
+
+	// maxBalanceAddresses caps how many addresses a bulk balance request may list
+	maxBalanceAddresses int
+	// maxBodySize caps the size in bytes of POST request bodies
+	maxBodySize int64
+	// proxyMethods is the allow-list of methods forwarded by the RPC proxy
+	proxyMethods map[string]bool
+
+	// statsMaxMissingRatio bounds the fraction of blocks statistics may be computed without
+	statsMaxMissingRatio float64
+
+	// pprof serves the runtime profiles under /debug/pprof
+	pprof bool
+
+	// adminToken authorizes requests to the /admin routes
+	adminToken string
+
+	// maintenance takes the API routes out of service when enabled
+	maintenance maintenanceMode
+}
+
+// Config defines configuration for the enhanced server
+type Config struct {
+	Port string
+	// SlowRequestThreshold is the handler latency above which requests are logged at Warn
+	SlowRequestThreshold time.Duration
+	// VerboseRoutes lists route templates whose requests are logged in detail at Debug
+	VerboseRoutes []string
+	// RequestTimeout bounds request handling; zero disables it
+	RequestTimeout time.Duration
+	// Chains optionally serves additional chains under /api/v1/chains/:chain
+	Chains *ClientRegistry
+	// ErrorMessages overrides client-facing error messages by AppError type
+	ErrorMessages map[string]string
+	// RateLimiterStore holds rate limit counters; nil counts per instance in memory
+	RateLimiterStore middleware.RateLimiterStore
+	// RateLimitKeyFunc selects the rate limit bucket per request; nil limits per client IP
+	RateLimitKeyFunc func(*gin.Context) string
+	// TrustedProxies lists the IPs and CIDR ranges of the proxies whose
+	// X-Forwarded-For and X-Real-IP headers are believed when determining the
+	// client IP used for logging and rate limiting. Headers from any other
+	// peer are ignored. Nil trusts no proxy.
+	TrustedProxies []string
+	// StrictValueDecoding fails requests whose wei amounts the node returned as
+	// malformed hex, instead of reporting the affected fields in decodeErrors
+	StrictValueDecoding bool
+	// MaxBlockRange caps how many blocks a single block range request may return
+	MaxBlockRange int
+	// MaxTransactionPage caps the tx_limit of block requests paginating their
+	// transactions
+	MaxTransactionPage int
+	// MaxBalanceAddresses caps how many addresses a single bulk balance
+	// request may list
+	MaxBalanceAddresses int
+	// MaxBodySize caps the size in bytes of POST request bodies; zero or less
+	// disables the limit
+	MaxBodySize int64
+	// RPCProxyMethods lists the JSON-RPC methods POST /api/v1/rpc forwards to
+	// the node. Others are rejected, so methods that sign with the node's
+	// accounts or change its state are never reachable through the proxy.
+	RPCProxyMethods []string
+	// StatsMaxMissingRatio is the largest fraction of blocks, from 0 to 1, that
+	// statistics may be computed without when some blocks fail to fetch
+	StatsMaxMissingRatio float64
+	// AdminToken is the bearer token required by the /admin routes, which are
+	// not served when it is empty
+	AdminToken string
+	// EnablePprof serves the net/http/pprof profiles under /debug/pprof,
+	// behind the admin token. Profiling is off by default.
+	EnablePprof bool
+	// Maintenance starts the server in maintenance mode, rejecting API requests
+	Maintenance bool
+	// MaintenanceRetryAfter is the Retry-After hint sent in maintenance mode
+	MaintenanceRetryAfter time.Duration
+}
+
+// DefaultConfig returns a default server configuration
+func DefaultConfig() Config {
+	return Config{
+		Port:                 "8080",
+		SlowRequestThreshold: middleware.DefaultLoggerConfig().SlowThreshold,
+		RequestTimeout:       30 * time.Second,
+		TrustedProxies:       DefaultTrustedProxies(),
+		MaxBlockRange:        DefaultMaxBlockRange,
+		MaxTransactionPage:   DefaultMaxTransactionPage,
+		MaxBalanceAddresses:  DefaultMaxBalanceAddresses,
+		MaxBodySize:          middleware.DefaultMaxBodySize,
+		RPCProxyMethods:      DefaultRPCProxyMethods(),
+		StatsMaxMissingRatio: DefaultStatsMaxMissingRatio,
+
+		MaintenanceRetryAfter: DefaultMaintenanceRetryAfter,
+	}
+}
+
+// DefaultTrustedProxies returns the proxies trusted by default, loopback only,
+// for deployments behind a reverse proxy on the same host
+func DefaultTrustedProxies() []string {
+	return []string{"127.0.0.1", "::1"}
 }
 
 // NewEnhanced creates and configures a new enhanced server
 func NewEnhanced(client EnhancedBlockchainClient, port string) *EnhancedServer {
+	config := DefaultConfig()
+	config.Port = port
+	return NewEnhancedWithConfig(client, config)
+}
+
+// NewEnhancedWithConfig creates and configures a new enhanced server from a configuration
+func NewEnhancedWithConfig(client EnhancedBlockchainClient, config Config) *EnhancedServer {
 	// Configure router
 	router := gin.New()
+
+	// Normalize trailing and duplicate slashes so "/health/" and
+	// "/api/v1/block/latest/" resolve to their registered routes. GET requests
+	// are redirected with a 301, other methods with a 307 to preserve the body.
+	router.RedirectTrailingSlash = true
+	router.RemoveExtraSlash = true
+
+	// gin trusts forwarding headers from every peer by default, which would let
+	// clients spoof their IP and escape their rate limit bucket
+	if err := router.SetTrustedProxies(config.TrustedProxies); err != nil {
+		logger.Error("Invalid trusted proxies, trusting none", zap.Strings("trusted_proxies", config.TrustedProxies), zap.Error(err))
+		_ = router.SetTrustedProxies(nil)
+	}
+
+	// Answer requests with an unsupported method on a known path with 405 and
+	// an Allow header listing the supported methods, instead of a 404. The
+	// global middleware below also runs for these, so the error handler renders
+	// the usual error body with the request ID.
+	router.HandleMethodNotAllowed = true
+	router.NoMethod(func(c *gin.Context) {
+		c.Error(errors.NewValidationError("Method not allowed", nil).
+			WithCode(errors.CodeMethodNotAllowed).
+			WithData(map[string]interface{}{"method": c.Request.Method}))
+	})
+
+	// Browsers request a favicon on every visit. Answer it before any middleware
+	// is attached so it stays out of request logs, metrics and rate limits.
+	router.GET("/favicon.ico", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
 	
 	// Use our custom middleware
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Tracing())
 	router.Use(middleware.Recovery())
-	router.Use(middleware.Logger())
-	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
+		SlowThreshold: config.SlowRequestThreshold,
+		VerboseRoutes: config.VerboseRoutes,
+	}))
+	router.Use(middleware.ErrorHandlerWithConfig(middleware.ErrorHandlerConfig{
+		PublicMessages: config.ErrorMessages,
+	}))
 	router.Use(metrics.MetricsMiddleware())
+	router.Use(middleware.Timeout(config.RequestTimeout))
 
 	// Configure rate limiters
-	middleware.ConfigureRateLimiters(router)
+	middleware.ConfigureRateLimiters(router, config.RateLimiterStore, config.RateLimitKeyFunc)
 	
 	// Register metrics endpoint
 	metrics.RegisterMetricsEndpoint(router)
@@ -55,7 +250,31 @@ func NewEnhanced(client EnhancedBlockchainClient, port string) *EnhancedServer {
 	server := &EnhancedServer{
 		router:  router,
 		client:  client,
-		address: fmt.Sprintf(":%s", port),
+		chains:  config.Chains,
+		address: fmt.Sprintf(":%s", config.Port),
+
+		strictDecoding: config.StrictValueDecoding,
+		maxBlockRange:  config.MaxBlockRange,
+		adminToken:     config.AdminToken,
+		pprof:          config.EnablePprof,
+
+		statsMaxMissingRatio: config.StatsMaxMissingRatio,
+		maxTransactionPage:   config.MaxTransactionPage,
+		maxBalanceAddresses:  config.MaxBalanceAddresses,
+		maxBodySize:          config.MaxBodySize,
+		proxyMethods:         make(map[string]bool, len(config.RPCProxyMethods)),
+
+		blockRequests: responseGroup{
+			coalesced: metrics.CoalescedRequestsTotal.WithLabelValues("/api/v1/block/:number"),
+			timeout:   config.RequestTimeout,
+		},
+	}
+	for _, method := range config.RPCProxyMethods {
+		server.proxyMethods[method] = true
+	}
+	server.maintenance.retryAfter = config.MaintenanceRetryAfter
+	if config.Maintenance {
+		server.SetMaintenance(true, "config")
 	}
 
 	// Set up routes
@@ -67,6 +286,7 @@ func NewEnhanced(client EnhancedBlockchainClient, port string) *EnhancedServer {
 // Start starts the HTTP server
 func (s *EnhancedServer) Start() error {
 	logger.Info("Enhanced server starting", zap.String("address", s.address))
+	go s.Warmup(context.Background())
 	return s.router.Run(s.address)
 }
 
@@ -77,23 +297,111 @@ func (s *EnhancedServer) setupRoutes() {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
-	// API routes
-	api := s.router.Group("/api/v1")
-	{
-		// Get latest block number
-		api.GET("/block/latest", s.getLatestBlockNumber)
-		
-		// Get block by number
-		api.GET("/block/:number", s.getBlockByNumber)
+	// Readiness check, failing until warmup completes or while the RPC is unreachable
+	s.router.GET("/ready", s.ready)
+
+	// Operational routes, only served when an admin token is configured
+	if s.adminToken != "" {
+		admin := s.router.Group("/admin", middleware.AdminAuth(s.adminToken))
+		admin.GET("/stats", s.getAdminStats)
+		admin.GET("/loglevel", s.getLogLevel)
+		admin.PUT("/loglevel", s.setLogLevel)
+		admin.GET("/maintenance", s.getMaintenance)
+		admin.PUT("/maintenance", s.setMaintenance)
 	}
+
+	// Runtime profiles, only served when enabled
+	if s.pprof {
+		s.registerPprofRoutes()
+	}
+
+	// API routes, rejected while in maintenance mode
+	api := s.router.Group("/api/v1", s.maintenanceGuard())
+	s.registerChainRoutes(api)
+
+	// Per-chain API routes resolve their client from the :chain parameter
+	if s.chains != nil {
+		chain := api.Group("/chains/:chain", s.chains.resolveChain())
+		s.registerChainRoutes(chain)
+	}
+}
+
+// registerChainRoutes registers the blockchain API routes on a route group
+func (s *EnhancedServer) registerChainRoutes(api *gin.RouterGroup) {
+	limitBody := middleware.MaxBodySize(s.maxBodySize)
+
+	// Get the chain ID reported by the node
+	api.GET("/chain", s.getChainID)
+
+	// Get latest block number
+	api.GET("/block/latest", s.getLatestBlockNumber)
+
+	// Get the full latest block in a single RPC call
+	api.GET("/block/latest/full", s.getLatestBlockFull)
+	
+	// Get a block by either its number or its hash
+	api.GET("/block/lookup", s.lookupBlock)
+
+	// Get block by hash
+	api.GET("/block/hash/:hash", s.getBlockByHash)
+
+	// Get block by number
+	api.GET("/block/:number", s.getBlockByNumber)
+
+	// Get the distinct transaction senders of a block
+	api.GET("/block/:number/senders", s.getBlockSenders)
+
+	// Get the ERC-20 token transfers of a block
+	api.GET("/block/:number/transfers", s.getBlockTransfers)
+
+	// Get a range of blocks in a single batch request
+	api.GET("/blocks", s.getBlockRange)
+
+	// Broadcast a signed transaction
+	api.POST("/tx", limitBody, s.sendRawTransaction)
+
+	// Get transaction by hash
+	api.GET("/tx/:hash", s.getTransactionByHash)
+
+	// Get transaction receipt by hash
+	api.GET("/tx/:hash/receipt", s.getTransactionReceipt)
+
+	// Get the gas price a transaction paid, or may pay while pending
+	api.GET("/tx/:hash/gasprice", s.getTransactionGasPrice)
+
+	// Get address balance
+	api.GET("/address/:address/balance", s.getBalance)
+
+	// Get the bytecode deployed at an address
+	api.GET("/address/:address/code", s.getCode)
+
+	// Get the balances of several addresses in a single batch request
+	api.POST("/balances", limitBody, s.getBalances)
+
+	// Execute a read-only contract call
+	api.POST("/call", limitBody, s.call)
+
+	// Forward an allowed JSON-RPC method to the node as is
+	api.POST("/rpc", limitBody, s.proxyRPC)
+
+	// Get current gas pricing
+	api.GET("/gas", s.getGasPrice)
+
+	// Get slow, standard and fast fee recommendations
+	api.GET("/fees", s.getFees)
+
+	// Get gasUsed percentiles over recent blocks
+	api.GET("/stats/gas", s.getGasStats)
 }
 
 // getLatestBlockNumber handles requests for the latest block number
 func (s *EnhancedServer) getLatestBlockNumber(c *gin.Context) {
+	client, chain := s.clientFor(c)
+
 	// Start metrics timer
 	start := time.Now()
 	
-	blockNumber, err := s.client.GetLatestBlockNumber()
+	blockNumber, err := client.GetLatestBlockNumberCtx(c.Request.Context())
 	
 	// Record RPC metrics
 	duration := time.Since(start).Seconds()
@@ -108,12 +416,15 @@ func (s *EnhancedServer) getLatestBlockNumber(c *gin.Context) {
 	metrics.RPCRequestsTotal.WithLabelValues("eth_blockNumber", "success").Inc()
 	metrics.RPCRequestDuration.WithLabelValues("eth_blockNumber").Observe(duration)
 	
-	// Update blockchain height metric - convert hex string to float64
-	// Remove "0x" prefix and parse as hexadecimal
-	if len(blockNumber) > 2 && blockNumber[:2] == "0x" {
-		if blockVal, err := strconv.ParseUint(blockNumber[2:], 16, 64); err == nil {
+	// Update blockchain height metric and the client's view of the head
+	if blockVal, err := hexutil.DecodeUint64(blockNumber); err == nil {
+		// The height gauge tracks the default chain only
+		if chain == "" {
 			metrics.UpdateBlockchainHeight(float64(blockVal))
 		}
+		client.SetHead(blockVal)
+	} else {
+		logger.Warn("Unparseable latest block number", zap.String("block_number", blockNumber), zap.Error(err))
 	}
 	
 	logger.Debug("Retrieved latest block number", zap.String("block_number", blockNumber))
@@ -122,9 +433,69 @@ func (s *EnhancedServer) getLatestBlockNumber(c *gin.Context) {
 	})
 }
 
-// getBlockByNumber handles requests for a specific block by number
+// getLatestBlockFull handles requests for the full latest block. Transactions
+// are included unless ?transactions=false is given.
+func (s *EnhancedServer) getLatestBlockFull(c *gin.Context) {
+	includeTransactions := true
+	if param := c.Query("transactions"); param != "" {
+		parsed, err := strconv.ParseBool(param)
+		if err != nil {
+			c.Error(errors.NewValidationError("Invalid transactions parameter, expected true or false", err))
+			return
+		}
+		includeTransactions = parsed
+	}
+
+	timeFormat, err := parseTimeFormat(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	client, chain := s.clientFor(c)
+
+	// Start metrics timer
+	start := time.Now()
+
+	block, err := client.GetLatestBlockFull(c.Request.Context(), includeTransactions)
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		metrics.RPCRequestsTotal.WithLabelValues("eth_getBlockByNumber", "error").Inc()
+		logger.Error("Failed to get latest block", zap.Error(err))
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get latest block"))
+		return
+	}
+
+	// Record successful RPC metrics
+	metrics.RPCRequestsTotal.WithLabelValues("eth_getBlockByNumber", "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues("eth_getBlockByNumber").Observe(duration)
+
+	// The height gauge tracks the default chain only
+	if chain == "" {
+		if blockVal, err := hexutil.DecodeUint64(block.Number); err == nil {
+			metrics.UpdateBlockchainHeight(float64(blockVal))
+		}
+	}
+
+	logger.Debug("Retrieved latest block",
+		zap.String("block_number", block.Number),
+		zap.String("block_hash", block.Hash))
+	c.JSON(http.StatusOK, formatBlockTimestamp(block, timeFormat))
+}
+
+// getBlockByNumber handles requests for a specific block by number. Only the
+// header and transaction hashes are fetched when ?full=false is given, and only
+// a page of the transactions is listed when ?tx_offset= or ?tx_limit= is given.
 func (s *EnhancedServer) getBlockByNumber(c *gin.Context) {
 	blockNumberParam := c.Param("number")
+
+	full, err := parseFullParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 	
 	// Log the incoming request
 	logger.Debug("Block details requested", zap.String("block_number", blockNumberParam))
@@ -135,73 +506,135 @@ func (s *EnhancedServer) getBlockByNumber(c *gin.Context) {
 		logger.Warn("Invalid block number format", 
 			zap.String("input", blockNumberParam), 
 			zap.Error(err))
-		c.Error(errors.Wrap(err, errors.ErrorTypeValidation, "Invalid block number format"))
+		c.Error(errors.Wrap(err, errors.ErrorTypeValidation, "Invalid block number format").
+			WithCode(errors.CodeInvalidBlockNumber))
+		return
+	}
+
+	timeFormat, err := parseTimeFormat(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	page, err := parseTransactionPage(c, s.maxTransactionPage)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 	
+	client, chain := s.clientFor(c)
+
+	// Concurrent identical requests share one fetch and one serialized response,
+	// including its outcome if the fetch fails or times out. The fetch outlives
+	// the request that started it if that request goes away.
+	key := fmt.Sprintf("%s|%s|%t|%s", chain, normalizeBlockKey(formattedBlockNumber), full, timeFormat)
+	if page != nil {
+		key += fmt.Sprintf("|%d|%d", page.offset, page.limit)
+	}
+	response, err := s.blockRequests.do(c.Request.Context(), key, func(ctx context.Context) (coalescedResponse, error) {
+		return s.fetchBlockByNumber(ctx, client, formattedBlockNumber, full, timeFormat, page)
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Clients that already hold this block are told so instead of sent it again
+	if notModified(c, blockETag(formattedBlockNumber, response.hash, full, timeFormat, page)) {
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", response.body)
+}
+
+// fetchBlockByNumber fetches a block, recording RPC metrics, and returns its
+// JSON response body, listing only page of its transactions when not nil,
+// together with its hash
+func (s *EnhancedServer) fetchBlockByNumber(ctx context.Context, client EnhancedBlockchainClient, formattedBlockNumber string, full bool, timeFormat string, page *transactionPage) (coalescedResponse, error) {
+	// Header-only lookups are labelled separately since their cost differs
+	method := "eth_getBlockByNumber"
+	if !full {
+		method = headerBlockMethod
+	}
+
+	// The block number tags this lookup's logs, including the RPC client's
+	ctx = logger.IntoContext(ctx, zap.String("block_number", formattedBlockNumber))
+	log := logger.FromContext(ctx)
+
 	// Start metrics timer
 	start := time.Now()
 	
 	// Get block details
-	block, err := s.client.GetBlockByNumber(formattedBlockNumber)
+	var block *models.Block
+	var err error
+	if full {
+		block, err = client.GetBlockByNumberCtx(ctx, formattedBlockNumber)
+	} else {
+		block, err = client.GetBlockHeaderByNumber(ctx, formattedBlockNumber)
+	}
 	
 	// Record RPC metrics
 	duration := time.Since(start).Seconds()
 	if err != nil {
-		metrics.RPCRequestsTotal.WithLabelValues("eth_getBlockByNumber", "error").Inc()
+		metrics.RPCRequestsTotal.WithLabelValues(method, "error").Inc()
 		
 		if errors.IsType(err, errors.ErrorTypeNotFound) {
-			logger.Warn("Block not found", 
-				zap.String("block_number", formattedBlockNumber))
-			c.Error(err)
-		} else {
-			logger.Error("Failed to get block details", 
-				zap.String("block_number", formattedBlockNumber), 
-				zap.Error(err))
-			
-			// Create a data map for the error
-			errData := map[string]interface{}{
-				"block_number": formattedBlockNumber,
-			}
-			
-			c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, 
-				"Failed to get block data").WithData(errData))
+			log.Warn("Block not found")
+			return coalescedResponse{}, err
 		}
-		return
+
+		log.Error("Failed to get block details", zap.Error(err))
+		
+		// Create a data map for the error
+		errData := map[string]interface{}{
+			"block_number": formattedBlockNumber,
+		}
+		
+		return coalescedResponse{}, errors.Wrap(err, errors.ErrorTypeBlockchain, 
+			"Failed to get block data").WithData(errData)
 	}
 	
 	// Record successful RPC metrics
-	metrics.RPCRequestsTotal.WithLabelValues("eth_getBlockByNumber", "success").Inc()
-	metrics.RPCRequestDuration.WithLabelValues("eth_getBlockByNumber").Observe(duration)
+	metrics.RPCRequestsTotal.WithLabelValues(method, "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues(method).Observe(duration)
 	
-	logger.Debug("Successfully retrieved block",
-		zap.String("block_number", block.Number),
-		zap.String("block_hash", block.Hash))
+	log.Debug("Successfully retrieved block", zap.String("block_hash", block.Hash))
 	
-	c.JSON(http.StatusOK, block)
+	body, err := json.Marshal(paginateTransactions(formatBlockTimestamp(block, timeFormat), page))
+	if err != nil {
+		return coalescedResponse{}, errors.NewInternalError("Failed to encode block", err)
+	}
+	return coalescedResponse{body: body, hash: block.Hash}, nil
 }
 
-// validateAndFormatBlockNumber validates and formats block number string
+// validateAndFormatBlockNumber validates a block number given as a tag
+// ("latest", "earliest" or "pending"), a 0x-prefixed hex number or a decimal
+// number, and formats it for the RPC: tags and hex pass through unchanged and
+// decimals are converted to hex
 func validateAndFormatBlockNumber(blockNumber string) (string, error) {
-	// Handle special case for "latest"
-	if blockNumber == "latest" {
-		return "latest", nil
-	}
-
-	// Handle numeric inputs
-	if len(blockNumber) > 0 {
-		// Add "0x" prefix if not already there and not empty
-		if blockNumber[0] != '0' || (len(blockNumber) > 1 && blockNumber[1] != 'x') {
-			// Strip "0x" if it exists
-			if len(blockNumber) > 2 && blockNumber[0] == '0' && blockNumber[1] == 'x' {
-				blockNumber = blockNumber[2:]
-			}
-			
-			// For numeric inputs without 0x prefix, validate and add the prefix
-			blockNumber = "0x" + blockNumber
+	switch blockNumber {
+	case "latest", "earliest", "pending":
+		return blockNumber, nil
+	}
+
+	if strings.HasPrefix(blockNumber, "0x") {
+		if _, err := hexutil.DecodeUint64(blockNumber); err != nil {
+			return "", invalidBlockNumber(blockNumber, err)
 		}
 		return blockNumber, nil
 	}
 
-	return "", errors.New(errors.ErrorTypeValidation, "Invalid block number format")
+	number, err := strconv.ParseUint(blockNumber, 10, 64)
+	if err != nil {
+		return "", invalidBlockNumber(blockNumber, err)
+	}
+	return hexutil.EncodeUint64(number), nil
+}
+
+// invalidBlockNumber reports a block number validateAndFormatBlockNumber rejected
+func invalidBlockNumber(blockNumber string, cause error) error {
+	return errors.NewValidationError("Block number must be latest, earliest, pending, a decimal or a 0x-prefixed hex number", cause).
+		WithCode(errors.CodeInvalidBlockNumber).
+		WithData(map[string]interface{}{"blockNumber": blockNumber})
 }
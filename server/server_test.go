@@ -0,0 +1,1888 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+	"blockchain-client/pkg/middleware"
+	"blockchain-client/rpc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newTestServer creates a server backed by a real RPC client talking to a mock node
+func newTestServer(t *testing.T, node http.HandlerFunc) *EnhancedServer {
+	gin.SetMode(gin.TestMode)
+
+	rpcServer := httptest.NewServer(node)
+	t.Cleanup(rpcServer.Close)
+
+	client := rpc.NewEnhancedClient(rpcServer.URL, time.Second)
+	return NewEnhanced(client, "8080")
+}
+
+// serve performs a request against the server's router
+func serve(srv *EnhancedServer, method, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTrailingSlashRedirects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := NewEnhanced(nil, "8080")
+
+	tests := []struct {
+		method   string
+		path     string
+		status   int
+		location string
+	}{
+		{http.MethodGet, "/health/", http.StatusMovedPermanently, "/health"},
+		{http.MethodGet, "/api/v1/block/latest/", http.StatusMovedPermanently, "/api/v1/block/latest"},
+		{http.MethodGet, "/api/v1/block/0x10/", http.StatusMovedPermanently, "/api/v1/block/0x10"},
+		{http.MethodGet, "/metrics/", http.StatusMovedPermanently, "/metrics"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			srv.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.location, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestCanonicalPathsAreNotRedirected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := NewEnhanced(nil, "8080")
+
+	for _, path := range []string{"/health", "/metrics"} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, path)
+	}
+}
+
+func TestGetTransactionByHashEndpoint(t *testing.T) {
+	hash := "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"hash":"` + hash + `","from":"0xa7d9ddbe1f17865597fbd27ec712455208b6b76d","value":"0xde0b6b3a7640000","gasPrice":"0x3b9aca00"}}`))
+		assert.NoError(t, err)
+	})
+
+	w := serve(srv, http.MethodGet, "/api/v1/tx/"+hash)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), hash)
+	assert.Contains(t, w.Body.String(), `"valueDecimal":"1000000000000000000"`)
+	assert.Contains(t, w.Body.String(), `"gasPriceDecimal":"1000000000"`)
+	assert.NotContains(t, w.Body.String(), "decodeErrors")
+}
+
+func TestGetTransactionByHashEndpointMalformedValues(t *testing.T) {
+	hash := "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"
+	node := func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"hash":"` + hash + `","value":"1000","gasPrice":"0xnot-a-number"}}`))
+		assert.NoError(t, err)
+	}
+
+	srv := newTestServer(t, node)
+	w := serve(srv, http.MethodGet, "/api/v1/tx/"+hash)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, hash, response["hash"])
+	assert.NotContains(t, response, "valueDecimal")
+	assert.NotContains(t, response, "gasPriceDecimal")
+	assert.Equal(t, map[string]interface{}{
+		"value":    `invalid value: hex string without 0x prefix: "1000"`,
+		"gasPrice": `invalid gasPrice: invalid hex string: "0xnot-a-number"`,
+	}, response["decodeErrors"])
+
+	// Strict decoding fails the request instead
+	rpcServer := httptest.NewServer(http.HandlerFunc(node))
+	defer rpcServer.Close()
+	config := DefaultConfig()
+	config.StrictValueDecoding = true
+	strict := NewEnhancedWithConfig(rpc.NewEnhancedClient(rpcServer.URL, time.Second), config)
+
+	w = serve(strict, http.MethodGet, "/api/v1/tx/"+hash)
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+}
+
+func TestGetTransactionByHashEndpointRejectsInvalidHash(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("RPC must not be called for an invalid hash")
+	})
+
+	for _, hash := range []string{"0x1234", "88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b", "0xZZdf016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"} {
+		w := serve(srv, http.MethodGet, "/api/v1/tx/"+hash)
+		assert.Equal(t, http.StatusBadRequest, w.Code, hash)
+	}
+}
+
+func TestGetTransactionReceiptEndpointPending(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`))
+		assert.NoError(t, err)
+	})
+
+	w := serve(srv, http.MethodGet, "/api/v1/tx/0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b/receipt")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "pending")
+}
+
+func TestTransactionETag(t *testing.T) {
+	const hash = "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"
+	const blockHash = "0x5C504ED432CB51138BCF09AA5E8A410DD4A1E204EF84BFED1BE16DFBA1B22060"
+	blockHashField := `"` + blockHash + `"`
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		result := `{"hash":"` + hash + `","blockHash":` + blockHashField + `,"value":"0x0","gasPrice":"0x1"}`
+		if request.Method == "eth_getTransactionReceipt" {
+			result = `{"transactionHash":"` + hash + `","blockHash":` + blockHashField + `,"blockNumber":"0x10","status":"0x1"}`
+		}
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":` + result + `}`))
+		assert.NoError(t, err)
+	})
+
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		return w
+	}
+
+	etag := `"` + hash + "-" + strings.ToLower(blockHash) + `"`
+	for _, path := range []string{"/api/v1/tx/" + hash, "/api/v1/tx/" + hash + "/receipt"} {
+		w := get(path, "")
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, etag, w.Header().Get("ETag"), path)
+
+		// A client holding the confirmed transaction gets an empty 304
+		w = get(path, etag)
+		assert.Equal(t, http.StatusNotModified, w.Code, path)
+		assert.Empty(t, w.Body.String(), path)
+
+		w = get(path, `"other"`)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+	}
+
+	// Pending transactions have no block and are never validated
+	blockHashField = "null"
+	w := get("/api/v1/tx/"+hash, "*")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestJSONResponsesDeclareCharset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := NewEnhanced(nil, "8080")
+
+	// Success, validation error and method-not-allowed responses all render JSON
+	for _, tt := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/health"},
+		{http.MethodGet, "/api/v1/tx/0x123"},
+		{http.MethodPost, "/health"},
+	} {
+		w := serve(srv, tt.method, tt.path)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"), tt.method+" "+tt.path)
+	}
+}
+
+func TestFaviconIsNotLogged(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := NewEnhanced(nil, "8080")
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	defer logger.Replace(zap.New(core))()
+
+	w := serve(srv, http.MethodGet, "/favicon.ico")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, 0, logs.FilterMessage("HTTP Request").Len())
+
+	// Regular routes are still logged
+	serve(srv, http.MethodGet, "/health")
+	assert.Equal(t, 1, logs.FilterMessage("HTTP Request").Len())
+}
+
+func TestTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := DefaultConfig()
+	config.TrustedProxies = []string{"10.0.0.0/8"}
+	srv := NewEnhancedWithConfig(nil, config)
+	srv.router.GET("/ip", func(c *gin.Context) {
+		c.String(http.StatusOK, c.ClientIP())
+	})
+
+	clientIP := func(remoteAddr, forwardedFor string) string {
+		req, _ := http.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	// Forwarding headers are honoured from a trusted proxy only
+	assert.Equal(t, "198.51.100.4", clientIP("10.1.2.3:4000", "198.51.100.4"))
+	assert.Equal(t, "203.0.113.9", clientIP("203.0.113.9:4000", "198.51.100.4"))
+
+	// A client cannot escape its rate limit bucket by spoofing the header
+	codes := map[int]int{}
+	for i := 0; i <= middleware.DefaultRateLimiterConfig().Limit; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = "203.0.113.10:4000"
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i))
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		codes[w.Code]++
+	}
+	assert.Equal(t, map[int]int{http.StatusOK: 100, http.StatusTooManyRequests: 1}, codes)
+}
+
+func TestChainRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Each mock node reports a different latest block
+	newNode := func(blockNumber string) *rpc.EnhancedClient {
+		node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"` + blockNumber + `"}`))
+			assert.NoError(t, err)
+		}))
+		t.Cleanup(node.Close)
+		return rpc.NewEnhancedClient(node.URL, time.Second)
+	}
+
+	chains := NewClientRegistry()
+	chains.Register("polygon", newNode("0x100"))
+	chains.Register("ethereum", newNode("0x200"))
+
+	config := DefaultConfig()
+	config.Chains = chains
+	srv := NewEnhancedWithConfig(newNode("0x1"), config)
+
+	w := serve(srv, http.MethodGet, "/api/v1/chains/polygon/block/latest")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "0x100")
+
+	w = serve(srv, http.MethodGet, "/api/v1/chains/Ethereum/block/latest")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "0x200")
+
+	// The default chain is still served at the top level
+	w = serve(srv, http.MethodGet, "/api/v1/block/latest")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "0x1")
+
+	w = serve(srv, http.MethodGet, "/api/v1/chains/solana/block/latest")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetLatestBlockFullEndpoint(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x134e82a","hash":"0x1234"}}`))
+		assert.NoError(t, err)
+	})
+
+	w := serve(srv, http.MethodGet, "/api/v1/block/latest/full")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"number":"0x134e82a"`)
+	assert.Equal(t, float64(0x134e82a), testutil.ToFloat64(metrics.BlockchainHeight))
+
+	w = serve(srv, http.MethodGet, "/api/v1/block/latest/full?transactions=maybe")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetChainIDEndpoint(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "eth_chainId", request.Method)
+
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x89"}`))
+		assert.NoError(t, err)
+	})
+
+	w := serve(srv, http.MethodGet, "/api/v1/chain")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"chainId":"0x89","chainIdDecimal":"137"}`, w.Body.String())
+}
+
+func TestGetBlockByNumberHeaderOnly(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, false, request.Params[1])
+
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x10","transactions":["0xabc"]}}`))
+		assert.NoError(t, err)
+	})
+
+	headers := testutil.ToFloat64(metrics.RPCRequestsTotal.WithLabelValues(headerBlockMethod, "success"))
+
+	w := serve(srv, http.MethodGet, "/api/v1/block/0x10?full=false")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"transactions":["0xabc"]`)
+	assert.Equal(t, headers+1, testutil.ToFloat64(metrics.RPCRequestsTotal.WithLabelValues(headerBlockMethod, "success")))
+
+	w = serve(srv, http.MethodGet, "/api/v1/block/0x10?full=maybe")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetBlockByNumberNodeTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	release := make(chan struct{})
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(node.Close)
+	t.Cleanup(func() { close(release) })
+	srv := NewEnhanced(rpc.NewEnhancedClient(node.URL, 20*time.Millisecond), "8080")
+
+	// The handler wraps the client's timeout as a blockchain error, which must
+	// not turn it into a 502
+	w := serve(srv, http.MethodGet, "/api/v1/block/0x10")
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+
+	var body struct {
+		Error struct {
+			Type string `json:"type"`
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, errors.ErrTypeTimeout, body.Error.Type)
+	assert.Equal(t, "TIMEOUT_ERROR", body.Error.Code)
+}
+
+func TestGetBlockByNumberCircuitOpen(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls atomic.Int32
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(node.Close)
+	client := rpc.NewEnhancedClient(node.URL, time.Second,
+		rpc.WithCircuitBreaker(rpc.BreakerConfig{FailureThreshold: 1, Cooldown: time.Minute}))
+	srv := NewEnhanced(client, "8080")
+
+	// The failure that opens the breaker is a node failure
+	w := serve(srv, http.MethodGet, "/api/v1/block/0x10")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+
+	// Later requests fail fast, distinguishable from a node failure
+	w = serve(srv, http.MethodGet, "/api/v1/block/0x11")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, int32(1), calls.Load())
+
+	var body struct {
+		Error struct {
+			Type string `json:"type"`
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, errors.ErrTypeUnavailable, body.Error.Type)
+	assert.Equal(t, errors.CodeCircuitOpen, body.Error.Code)
+}
+
+func TestValidateAndFormatBlockNumber(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		valid    bool
+	}{
+		{"latest tag", "latest", "latest", true},
+		{"earliest tag", "earliest", "earliest", true},
+		{"pending tag", "pending", "pending", true},
+		{"decimal", "255", "0xff", true},
+		{"decimal zero", "0", "0x0", true},
+		{"large decimal", "19000000", "0x121eac0", true},
+		{"hex", "0xabc", "0xabc", true},
+		{"hex zero", "0x0", "0x0", true},
+		{"empty", "", "", false},
+		{"bare prefix", "0x", "", false},
+		{"non-hex digits", "0xZZZ", "", false},
+		{"negative", "-1", "", false},
+		{"signed", "+1", "", false},
+		{"hex without prefix", "abc", "", false},
+		{"uppercase prefix", "0XABC", "", false},
+		{"unknown tag", "Latest", "", false},
+		{"overflow", "18446744073709551616", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatted, err := validateAndFormatBlockNumber(tt.input)
+			if !tt.valid {
+				assert.True(t, errors.IsType(err, errors.ErrorTypeValidation), err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, formatted)
+		})
+	}
+
+	// Malformed numbers never reach the node
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("RPC must not be called for an invalid block number")
+	})
+	w := serve(srv, http.MethodGet, "/api/v1/block/0xZZZ")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_BLOCK_NUMBER")
+}
+
+func TestGetBlockByNumberConvertsDecimal(t *testing.T) {
+	var requested []interface{}
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requested = request.Params
+
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x121eac0","transactions":[]}}`))
+		assert.NoError(t, err)
+	})
+
+	// A decimal number must not be sent as the hex block 0x19000000
+	w := serve(srv, http.MethodGet, "/api/v1/block/19000000")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0x121eac0", requested[0])
+}
+
+func TestGetBlockByNumberPaginatesTransactions(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		transactions := `[{"hash":"0x01"},{"hash":"0x02"},{"hash":"0x03"},{"hash":"0x04"},{"hash":"0x05"}]`
+		if request.Params[1] == false {
+			transactions = `["0x01","0x02","0x03","0x04","0x05"]`
+		}
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x10","transactions":` + transactions + `}}`))
+		assert.NoError(t, err)
+	})
+	srv.maxTransactionPage = 3
+
+	page := func(path string) (hashes []string, pagination map[string]interface{}) {
+		w := serve(srv, http.MethodGet, path)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+
+		var response struct {
+			Transactions []json.RawMessage     `json:"transactions"`
+			Pagination   map[string]interface{} `json:"pagination"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		for _, tx := range response.Transactions {
+			var hash string
+			if json.Unmarshal(tx, &hash) != nil {
+				var full models.Transaction
+				assert.NoError(t, json.Unmarshal(tx, &full))
+				hash = full.Hash
+			}
+			hashes = append(hashes, hash)
+		}
+		return hashes, response.Pagination
+	}
+
+	// Without pagination parameters the whole block is returned as before
+	hashes, pagination := page("/api/v1/block/0x10")
+	assert.Len(t, hashes, 5)
+	assert.Nil(t, pagination)
+
+	hashes, pagination = page("/api/v1/block/0x10?tx_offset=1&tx_limit=2")
+	assert.Equal(t, []string{"0x02", "0x03"}, hashes)
+	assert.Equal(t, map[string]interface{}{"total": 5.0, "offset": 1.0, "limit": 2.0}, pagination)
+
+	// Hash-only blocks are paginated too, and limits are capped
+	hashes, pagination = page("/api/v1/block/0x10?full=false&tx_limit=10")
+	assert.Equal(t, []string{"0x01", "0x02", "0x03"}, hashes)
+	assert.Equal(t, map[string]interface{}{"total": 5.0, "offset": 0.0, "limit": 3.0}, pagination)
+
+	hashes, _ = page("/api/v1/block/0x10?tx_offset=4")
+	assert.Equal(t, []string{"0x05"}, hashes)
+
+	// Pages past the end are empty rather than an error
+	hashes, pagination = page("/api/v1/block/0x10?tx_offset=9")
+	assert.Empty(t, hashes)
+	assert.Equal(t, 5.0, pagination["total"])
+
+	for _, query := range []string{"tx_offset=-1", "tx_limit=-1", "tx_offset=abc", "tx_limit=1.5"} {
+		w := serve(srv, http.MethodGet, "/api/v1/block/0x10?"+query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestGetBlockByNumberETag(t *testing.T) {
+	const hash = "0x5C504ED432CB51138BCF09AA5E8A410DD4A1E204EF84BFED1BE16DFBA1B22060"
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x10","hash":"` + hash + `","transactions":[]}}`))
+		assert.NoError(t, err)
+	})
+
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		return w
+	}
+
+	etag := `"` + strings.ToLower(hash) + `"`
+	w := get("/api/v1/block/0x10", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+
+	// A client holding the block gets an empty 304
+	for _, ifNoneMatch := range []string{etag, `"other", W/` + etag, "*"} {
+		w = get("/api/v1/block/0x10", ifNoneMatch)
+		assert.Equal(t, http.StatusNotModified, w.Code, ifNoneMatch)
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+		assert.Empty(t, w.Body.String())
+	}
+
+	w = get("/api/v1/block/0x10", `"other"`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Body.String())
+
+	// Each representation of the block has its own tag, so a client holding
+	// one is not told another is unchanged
+	etags := map[string]string{"": etag}
+	for _, query := range []string{"?full=false", "?timeFormat=rfc3339", "?tx_offset=0&tx_limit=1", "?tx_offset=1&tx_limit=1"} {
+		w = get("/api/v1/block/0x10"+query, etag)
+		assert.Equal(t, http.StatusOK, w.Code, query)
+		variant := w.Header().Get("ETag")
+		assert.NotEmpty(t, variant, query)
+		assert.NotContains(t, etags, variant, query)
+		etags[variant] = query
+
+		w = get("/api/v1/block/0x10"+query, variant)
+		assert.Equal(t, http.StatusNotModified, w.Code, query)
+	}
+
+	// Tags move on to other blocks, so they are never validated
+	assert.Empty(t, blockETag("latest", hash, true, "", nil))
+	assert.Empty(t, blockETag("pending", hash, true, "", nil))
+}
+
+func TestGetBlockByNumberCoalescesConcurrentRequests(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x10","hash":"0xabc","transactions":[]}}`))
+		assert.NoError(t, err)
+	})
+
+	coalesced := metrics.CoalescedRequestsTotal.WithLabelValues("/api/v1/block/:number")
+	before := testutil.ToFloat64(coalesced)
+
+	// Differently spelled numbers name the same block
+	paths := []string{"/api/v1/block/0x10", "/api/v1/block/0x10", "/api/v1/block/0x010", "/api/v1/block/0x10", "/api/v1/block/0x10"}
+	responses := make([]*httptest.ResponseRecorder, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			responses[i] = serve(srv, http.MethodGet, path)
+		}(i, path)
+	}
+
+	// Release the node once every follower is waiting on the first request
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(coalesced) == before+float64(len(paths)-1)
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, w := range responses {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.JSONEq(t, responses[0].Body.String(), w.Body.String())
+	}
+	assert.Contains(t, responses[0].Body.String(), `"hash":"0xabc"`)
+
+	// Header-only requests are a different response and are not coalesced with full ones
+	w := serve(srv, http.MethodGet, "/api/v1/block/0x10?full=false")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestCoalescedFetchOutlivesFirstRequest(t *testing.T) {
+	release := make(chan struct{})
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x20","hash":"0xdef","transactions":[]}}`))
+		assert.NoError(t, err)
+	})
+
+	coalesced := metrics.CoalescedRequestsTotal.WithLabelValues("/api/v1/block/:number")
+	before := testutil.ToFloat64(coalesced)
+
+	// The first request starts the fetch and then goes away
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan struct{})
+	go func() {
+		defer close(first)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/block/0x20", nil)
+		srv.router.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+	assert.Eventually(t, func() bool {
+		srv.blockRequests.mu.Lock()
+		defer srv.blockRequests.mu.Unlock()
+		return len(srv.blockRequests.calls) == 1
+	}, time.Second, time.Millisecond)
+
+	second := make(chan *httptest.ResponseRecorder)
+	go func() {
+		second <- serve(srv, http.MethodGet, "/api/v1/block/0x20")
+	}()
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(coalesced) == before+1
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	close(release)
+
+	// The request that joined still gets the block
+	w := <-second
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"hash":"0xdef"`)
+	<-first
+}
+
+func TestResponseGroupPanicFailsWaiters(t *testing.T) {
+	coalesced := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_coalesced_total"})
+	group := responseGroup{coalesced: coalesced}
+
+	release := make(chan struct{})
+	go func() {
+		defer func() {
+			// The panic reaches the request that ran the call
+			assert.NotNil(t, recover())
+		}()
+		_, _ = group.do(context.Background(), "key", func(context.Context) (coalescedResponse, error) {
+			<-release
+			panic("boom")
+		})
+	}()
+	assert.Eventually(t, func() bool {
+		group.mu.Lock()
+		defer group.mu.Unlock()
+		return len(group.calls) == 1
+	}, time.Second, time.Millisecond)
+
+	waiter := make(chan error)
+	go func() {
+		_, err := group.do(context.Background(), "key", func(context.Context) (coalescedResponse, error) {
+			return coalescedResponse{body: []byte("{}")}, nil
+		})
+		waiter <- err
+	}()
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(coalesced) == 1
+	}, time.Second, time.Millisecond)
+	close(release)
+
+	// Waiters get an error rather than an empty response
+	err := <-waiter
+	assert.True(t, errors.IsType(err, errors.ErrTypeInternal), err)
+}
+
+func TestResponseGroupWaiterHonorsContext(t *testing.T) {
+	coalesced := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_coalesced_total"})
+	group := responseGroup{coalesced: coalesced}
+
+	release := make(chan struct{})
+	defer close(release)
+	go func() {
+		_, _ = group.do(context.Background(), "key", func(context.Context) (coalescedResponse, error) {
+			<-release
+			return coalescedResponse{body: []byte("{}")}, nil
+		})
+	}()
+	assert.Eventually(t, func() bool {
+		group.mu.Lock()
+		defer group.mu.Unlock()
+		return len(group.calls) == 1
+	}, time.Second, time.Millisecond)
+
+	// A waiter whose client went away stops waiting while the call runs on
+	ctx, cancel := context.WithCancel(context.Background())
+	waiter := make(chan error)
+	go func() {
+		_, err := group.do(ctx, "key", func(context.Context) (coalescedResponse, error) {
+			return coalescedResponse{body: []byte("{}")}, nil
+		})
+		waiter <- err
+	}()
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(coalesced) == 1
+	}, time.Second, time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-waiter:
+		assert.True(t, errors.IsType(err, errors.ErrTypeTimeout), err)
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("waiter did not return when its context was canceled")
+	}
+}
+
+func TestResponseGroupLeaderHonorsContext(t *testing.T) {
+	coalesced := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_coalesced_total"})
+	group := responseGroup{coalesced: coalesced}
+
+	// The request that starts the call goes away before the call completes
+	release := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error)
+	go func() {
+		_, err := group.do(ctx, "key", func(callCtx context.Context) (coalescedResponse, error) {
+			<-release
+			// The call is not cancelled along with the leader
+			assert.NoError(t, callCtx.Err())
+			return coalescedResponse{body: []byte("{}")}, nil
+		})
+		leader <- err
+	}()
+	assert.Eventually(t, func() bool {
+		group.mu.Lock()
+		defer group.mu.Unlock()
+		return len(group.calls) == 1
+	}, time.Second, time.Millisecond)
+
+	waiter := make(chan coalescedResponse)
+	go func() {
+		response, err := group.do(context.Background(), "key", func(context.Context) (coalescedResponse, error) {
+			t.Error("a second call must not start while one is in flight")
+			return coalescedResponse{}, nil
+		})
+		assert.NoError(t, err)
+		waiter <- response
+	}()
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(coalesced) == 1
+	}, time.Second, time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-leader:
+		assert.True(t, errors.IsType(err, errors.ErrTypeTimeout), err)
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("leader did not return when its context was canceled")
+	}
+
+	// The call carries on for the request that joined it
+	close(release)
+	assert.Equal(t, []byte("{}"), (<-waiter).body)
+}
+
+func TestLookupBlockEndpoint(t *testing.T) {
+	const hash = "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"
+	var methods []string
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		methods = append(methods, request.Method)
+
+		result := `{"number":"0x10","hash":"` + hash + `","transactions":[]}`
+		if request.Method == "eth_getBlockByHash" && request.Params[0] != hash {
+			result = "null"
+		}
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":` + result + `}`))
+		assert.NoError(t, err)
+	})
+
+	w := serve(srv, http.MethodGet, "/api/v1/block/lookup?number=0x10")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"hash":"`+hash+`"`)
+	assert.Equal(t, []string{"eth_getBlockByNumber"}, methods)
+
+	w = serve(srv, http.MethodGet, "/api/v1/block/lookup?hash="+hash)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"number":"0x10"`)
+	assert.Equal(t, `"`+hash+`"`, w.Header().Get("ETag"))
+	assert.Equal(t, []string{"eth_getBlockByNumber", "eth_getBlockByHash"}, methods)
+
+	w = serve(srv, http.MethodGet, "/api/v1/block/lookup?hash=0x"+strings.Repeat("0", 64))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Exactly one identifier must be given, and a hash must look like one
+	for _, query := range []string{"?number=0x10&hash=" + hash, "", "?hash=0x10"} {
+		w = serve(srv, http.MethodGet, "/api/v1/block/lookup"+query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	assert.Len(t, methods, 3)
+}
+
+func TestGetBlockByHashEndpoint(t *testing.T) {
+	const hash = "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"
+	var params [][]interface{}
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		result := `"0x10"`
+		if request.Method == "eth_getBlockByHash" {
+			params = append(params, request.Params)
+			result = `{"number":"0x10","hash":"` + hash + `","transactions":[]}`
+		}
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":` + result + `}`))
+		assert.NoError(t, err)
+	})
+
+	success := metrics.RPCRequestsTotal.WithLabelValues("eth_getBlockByHash", "success")
+	before := testutil.ToFloat64(success)
+
+	w := serve(srv, http.MethodGet, "/api/v1/block/hash/"+hash)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"number":"0x10"`)
+	assert.Equal(t, `"`+hash+`"`, w.Header().Get("ETag"))
+
+	w = serve(srv, http.MethodGet, "/api/v1/block/hash/"+hash+"?full=false")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, [][]interface{}{{hash, true}, {hash, false}}, params)
+	assert.Equal(t, before+2, testutil.ToFloat64(success))
+
+	w = serve(srv, http.MethodGet, "/api/v1/block/hash/0x10")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Len(t, params, 2)
+
+	// Number routes are unaffected by the hash route
+	w = serve(srv, http.MethodGet, "/api/v1/block/latest")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGetBlockSendersEndpoint(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		result := `{"number":"0x11","transactions":[]}`
+		if request.Params[0] == "0x10" {
+			result = `{"number":"0x10","transactions":[
+				{"hash":"0x01","from":"0xa7d9ddbe1f17865597fbd27ec712455208b6b76d"},
+				{"hash":"0x02","from":"0xc2132d05d31c914a87c6611c10748aeb04b58e8f"},
+				{"hash":"0x03","from":"0xA7D9DDBE1F17865597FBD27EC712455208B6B76D"}
+			]}`
+		}
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":` + result + `}`))
+		assert.NoError(t, err)
+	})
+
+	w := serve(srv, http.MethodGet, "/api/v1/block/0x10/senders")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"blockNumber": "0x10",
+		"transactionCount": 3,
+		"senders": [
+			{"address": "0xa7d9ddbe1f17865597fbd27ec712455208b6b76d", "transactionCount": 2},
+			{"address": "0xc2132d05d31c914a87c6611c10748aeb04b58e8f", "transactionCount": 1}
+		]
+	}`, w.Body.String())
+
+	w = serve(srv, http.MethodGet, "/api/v1/block/0x11/senders")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"blockNumber": "0x11", "transactionCount": 0, "senders": []}`, w.Body.String())
+}
+
+func TestGetBlockTransfersEndpoint(t *testing.T) {
+	const (
+		transferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+		fromTopic     = "0x000000000000000000000000a7d9ddbe1f17865597fbd27ec712455208b6b76d"
+		toTopic       = "0x000000000000000000000000c2132d05d31c914a87c6611c10748aeb04b58e8f"
+		amount        = "0x00000000000000000000000000000000000000000000000000000000000f4240"
+	)
+	transfer := `{"address":"0xC2132D05D31c914a87C6611C10748AEb04B58e8F","topics":["` + transferTopic + `","` + fromTopic + `","` + toTopic + `"],"data":"` + amount + `","transactionHash":"0x01","logIndex":"0x0"}`
+
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string
+			Params []struct {
+				FromBlock string
+				ToBlock   string
+				Topics    []string
+			}
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "eth_getLogs", request.Method)
+		filter := request.Params[0]
+		assert.Equal(t, filter.FromBlock, filter.ToBlock)
+		assert.Equal(t, []string{transferTopic}, filter.Topics)
+
+		logs := []string{transfer}
+		switch filter.FromBlock {
+		case "0x10":
+			// An ERC-721 transfer indexes the token ID, and a broken token logs no amount
+			logs = append(logs,
+				`{"address":"0x01","topics":["`+transferTopic+`","`+fromTopic+`","`+toTopic+`","`+amount+`"],"data":"0x","transactionHash":"0x02","logIndex":"0x1"}`,
+				`{"address":"0x02","topics":["`+transferTopic+`","`+fromTopic+`","`+toTopic+`"],"data":"0x","transactionHash":"0x03","logIndex":"0x2"}`)
+		case "0x11":
+			for len(logs) <= maxBlockTransfers {
+				logs = append(logs, transfer)
+			}
+		}
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":[` + strings.Join(logs, ",") + `]}`))
+		assert.NoError(t, err)
+	})
+
+	w := serve(srv, http.MethodGet, "/api/v1/block/0x10/transfers")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"blockNumber": "0x10",
+		"transfers": [{
+			"token": "0xc2132d05d31c914a87c6611c10748aeb04b58e8f",
+			"from": "0xa7d9ddbe1f17865597fbd27ec712455208b6b76d",
+			"to": "0xc2132d05d31c914a87c6611c10748aeb04b58e8f",
+			"value": "1000000",
+			"transactionHash": "0x01",
+			"logIndex": "0x0"
+		}],
+		"total": 1,
+		"truncated": false,
+		"skipped": 2
+	}`, w.Body.String())
+
+	// Busy blocks are capped
+	w = serve(srv, http.MethodGet, "/api/v1/block/0x11/transfers")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Transfers []models.TokenTransfer
+		Total     int
+		Truncated bool
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Transfers, maxBlockTransfers)
+	assert.Equal(t, maxBlockTransfers+1, response.Total)
+	assert.True(t, response.Truncated)
+
+	w = serve(srv, http.MethodGet, "/api/v1/block/0xzz/transfers")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetBlockRangeEndpoint(t *testing.T) {
+	var batches, batchSize atomic.Int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		batches.Add(1)
+		var requests []struct {
+			ID     int           `json:"id"`
+			Params []interface{} `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&requests))
+		batchSize.Store(int32(len(requests)))
+
+		// Answer in reverse order; the node's head is block 0x12
+		responses := make([]string, 0, len(requests))
+		for i := len(requests) - 1; i >= 0; i-- {
+			var number uint64
+			fmt.Sscanf(requests[i].Params[0].(string), "0x%x", &number)
+			result := "null"
+			if number <= 0x12 {
+				transactions := `["0xabc"]`
+				if requests[i].Params[1] == true {
+					transactions = `[{"hash":"0xabc","blockNumber":"` + requests[i].Params[0].(string) + `"}]`
+				}
+				result = fmt.Sprintf(`{"number":"0x%x","transactions":%s}`, number, transactions)
+			}
+			responses = append(responses, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, requests[i].ID, result))
+		}
+		_, err := w.Write([]byte("[" + strings.Join(responses, ",") + "]"))
+		assert.NoError(t, err)
+	})
+
+	successes := testutil.ToFloat64(metrics.RPCRequestsTotal.WithLabelValues(batchBlocksMethod, "success"))
+
+	// Blocks carry transaction hashes only by default
+	w := serve(srv, http.MethodGet, "/api/v1/blocks?from=16&to=0x12")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var blocks []models.Block
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &blocks))
+	if assert.Len(t, blocks, 3) {
+		for i, number := range []string{"0x10", "0x11", "0x12"} {
+			assert.Equal(t, number, blocks[i].Number)
+			assert.Equal(t, []string{"0xabc"}, blocks[i].TransactionHashes)
+		}
+	}
+	assert.Equal(t, int32(1), batches.Load())
+	assert.Equal(t, successes+1, testutil.ToFloat64(metrics.RPCRequestsTotal.WithLabelValues(batchBlocksMethod, "success")))
+
+	// Full transactions are only fetched on request
+	w = serve(srv, http.MethodGet, "/api/v1/blocks?from=0x11&to=0x12&full=true")
+	assert.Equal(t, http.StatusOK, w.Code)
+	blocks = nil
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &blocks))
+	if assert.Len(t, blocks, 2) {
+		assert.Empty(t, blocks[0].TransactionHashes)
+		if assert.Len(t, blocks[0].Transactions, 1) {
+			assert.Equal(t, "0xabc", blocks[0].Transactions[0].Hash)
+		}
+	}
+	assert.Equal(t, http.StatusBadRequest, serve(srv, http.MethodGet, "/api/v1/blocks?from=0x11&to=0x12&full=maybe").Code)
+
+	// Part of the range is beyond the head
+	w = serve(srv, http.MethodGet, "/api/v1/blocks?from=0x11&to=0x13")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	for _, query := range []string{"from=0x12&to=0x10", "from=0&to=100", "from=abc&to=1", "to=1"} {
+		w = serve(srv, http.MethodGet, "/api/v1/blocks?"+query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	assert.Equal(t, int32(3), batches.Load())
+
+	// The cap is inclusive of both ends
+	w = serve(srv, http.MethodGet, "/api/v1/blocks?from=0&to=99")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Ranges ending at the largest block number do not wrap around to block 0
+	for query, size := range map[string]int32{
+		"from=0xffffffffffffffff&to=0xffffffffffffffff": 1,
+		"from=18446744073709551614&to=18446744073709551615": 2,
+	} {
+		w = serve(srv, http.MethodGet, "/api/v1/blocks?"+query)
+		assert.Equal(t, http.StatusNotFound, w.Code, query)
+		assert.Equal(t, size, batchSize.Load(), query)
+	}
+}
+
+func TestGetBalanceEndpoint(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0xde0b6b3a7640000"}`))
+		assert.NoError(t, err)
+	})
+
+	w := serve(srv, http.MethodGet, "/api/v1/address/0xc2132d05d31c914a87c6611c10748aeb04b58e8f/balance?block=earliest")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"address":"0xc2132d05d31c914a87c6611c10748aeb04b58e8f",
+		"block":"earliest",
+		"balance":"0xde0b6b3a7640000",
+		"balanceDecimal":"1000000000000000000"
+	}`, w.Body.String())
+
+	w = serve(srv, http.MethodGet, "/api/v1/address/0x1234/balance")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetCodeEndpoint(t *testing.T) {
+	code := "0x6080604052"
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"` + code + `"}`))
+		assert.NoError(t, err)
+	})
+
+	w := serve(srv, http.MethodGet, "/api/v1/address/0xc2132d05d31c914a87c6611c10748aeb04b58e8f/code")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"address":"0xc2132d05d31c914a87c6611c10748aeb04b58e8f",
+		"block":"latest",
+		"code":"0x6080604052",
+		"isContract":true
+	}`, w.Body.String())
+
+	// Externally owned accounts have no code
+	code = "0x"
+	w = serve(srv, http.MethodGet, "/api/v1/address/0xa7d9ddbe1f17865597fbd27ec712455208b6b76d/code?block=0x10")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"address":"0xa7d9ddbe1f17865597fbd27ec712455208b6b76d",
+		"block":"0x10",
+		"code":"0x",
+		"isContract":false
+	}`, w.Body.String())
+
+	w = serve(srv, http.MethodGet, "/api/v1/address/0x1234/code")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetBalancesEndpoint(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var requests []models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&requests))
+
+		// All addresses go to the node in one batch
+		response := make([]string, len(requests))
+		for i, request := range requests {
+			assert.Equal(t, "eth_getBalance", request.Method)
+			response[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"0xde0b6b3a7640000"}`, request.ID)
+		}
+		_, err := w.Write([]byte("[" + strings.Join(response, ",") + "]"))
+		assert.NoError(t, err)
+	})
+	srv.maxBalanceAddresses = 2
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/balances", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		return w
+	}
+
+	// A malformed address is reported without failing the others
+	w := post(`{"addresses":["0xc2132d05d31c914a87c6611c10748aeb04b58e8f","0x1234"]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"block":"latest",
+		"balances":{
+			"0xc2132d05d31c914a87c6611c10748aeb04b58e8f":{"balance":"0xde0b6b3a7640000","balanceDecimal":"1000000000000000000"}
+		},
+		"errors":{
+			"0x1234":{"type":"validation_error","code":"INVALID_ADDRESS","message":"Address must be a 0x-prefixed 20-byte hex string"}
+		}
+	}`, w.Body.String())
+
+	// Repeated addresses count once against the cap
+	w = post(`{"addresses":["0xc2132d05d31c914a87c6611c10748aeb04b58e8f","0xc2132d05d31c914a87c6611c10748aeb04b58e8f"],"block":"0x10"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"block":"0x10"`)
+
+	w = post(`{"addresses":["0xc2132d05d31c914a87c6611c10748aeb04b58e8f","0xa7d9ddbe1f17865597fbd27ec712455208b6b76d","0x1234"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"TOO_MANY_ADDRESSES"`)
+
+	w = post(`{"addresses":[]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = post(`{"addresses":["0xc2132d05d31c914a87c6611c10748aeb04b58e8f"],"block":"100"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGasEndpoint(t *testing.T) {
+	gasNode := func(priorityFee string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var request struct {
+				Method string `json:"method"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+			response := `{"jsonrpc":"2.0","id":1,"result":"0x3b9aca00"}`
+			if request.Method == "eth_maxPriorityFeePerGas" {
+				response = priorityFee
+			}
+			_, err := w.Write([]byte(response))
+			assert.NoError(t, err)
+		}
+	}
+
+	srv := newTestServer(t, gasNode(`{"jsonrpc":"2.0","id":1,"result":"0x59682f00"}`))
+	w := serve(srv, http.MethodGet, "/api/v1/gas")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"gasPrice": "0x3b9aca00",
+		"gasPriceDecimal": "1000000000",
+		"maxPriorityFeePerGas": "0x59682f00",
+		"maxPriorityFeePerGasDecimal": "1500000000"
+	}`, w.Body.String())
+
+	// Older chains reject the priority fee method, which is left out
+	srv = newTestServer(t, gasNode(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"the method eth_maxPriorityFeePerGas does not exist/is not available"}}`))
+	w = serve(srv, http.MethodGet, "/api/v1/gas")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"gasPrice": "0x3b9aca00", "gasPriceDecimal": "1000000000"}`, w.Body.String())
+}
+
+func TestFeesEndpoint(t *testing.T) {
+	const unsupported = `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"the method does not exist/is not available"}}`
+	feeNode := func(baseFee string, priorityFeeSupported, feeHistorySupported bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var request struct {
+				Method string `json:"method"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+			var response string
+			switch request.Method {
+			case "eth_gasPrice":
+				response = `{"jsonrpc":"2.0","id":1,"result":"0x6fc23ac00"}`
+			case "eth_getBlockByNumber":
+				response = `{"jsonrpc":"2.0","id":1,"result":{"number":"0x10","hash":"0xabc","transactions":[],"baseFeePerGas":"` + baseFee + `"}}`
+			case "eth_maxPriorityFeePerGas":
+				response = `{"jsonrpc":"2.0","id":1,"result":"0x77359400"}`
+				if !priorityFeeSupported {
+					response = unsupported
+				}
+			case "eth_feeHistory":
+				// The empty middle block is ignored, leaving medians of 2, 3 and 5 gwei
+				response = `{"jsonrpc":"2.0","id":1,"result":{
+					"oldestBlock":"0xe",
+					"baseFeePerGas":["0x4a817c800","0x4a817c800","0x4a817c800","0x4a817c800"],
+					"gasUsedRatio":[0.5,0,0.9],
+					"reward":[["0x3b9aca00","0x77359400","0xb2d05e00"],["0x0","0x0","0x0"],["0x77359400","0xb2d05e00","0x12a05f200"]]}}`
+				if !feeHistorySupported {
+					response = unsupported
+				}
+			default:
+				t.Errorf("unexpected method %s", request.Method)
+			}
+			_, err := w.Write([]byte(response))
+			assert.NoError(t, err)
+		}
+	}
+	tier := func(maxFee, maxFeeDecimal, priorityFee, priorityFeeDecimal string) string {
+		return `{"maxFeePerGas":"` + maxFee + `","maxFeePerGasDecimal":"` + maxFeeDecimal +
+			`","maxPriorityFeePerGas":"` + priorityFee + `","maxPriorityFeePerGasDecimal":"` + priorityFeeDecimal + `"}`
+	}
+
+	// Tiers come from fee history percentiles, with room for the 20 gwei base fee to double
+	srv := newTestServer(t, feeNode("0x4a817c800", true, true))
+	w := serve(srv, http.MethodGet, "/api/v1/fees")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"blockNumber": "0x10",
+		"eip1559": true,
+		"source": "feeHistory",
+		"gasPrice": "0x6fc23ac00",
+		"gasPriceDecimal": "30000000000",
+		"baseFeePerGas": "0x4a817c800",
+		"baseFeePerGasDecimal": "20000000000",
+		"maxPriorityFeePerGas": "0x77359400",
+		"maxPriorityFeePerGasDecimal": "2000000000",
+		"slow": `+tier("0x9c7652400", "42000000000", "0x77359400", "2000000000")+`,
+		"standard": `+tier("0xa02ffee00", "43000000000", "0xb2d05e00", "3000000000")+`,
+		"fast": `+tier("0xa7a358200", "45000000000", "0x12a05f200", "5000000000")+`
+	}`, w.Body.String())
+
+	var fees map[string]json.RawMessage
+
+	// Without fee history every tier uses the suggested priority fee
+	srv = newTestServer(t, feeNode("0x4a817c800", true, false))
+	w = serve(srv, http.MethodGet, "/api/v1/fees")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &fees))
+	assert.JSONEq(t, `"maxPriorityFeePerGas"`, string(fees["source"]))
+	for _, name := range []string{"slow", "standard", "fast"} {
+		assert.JSONEq(t, tier("0x9c7652400", "42000000000", "0x77359400", "2000000000"), string(fees[name]), name)
+	}
+
+	// Without either, the priority fee is what the gas price pays above the base fee
+	srv = newTestServer(t, feeNode("0x4a817c800", false, false))
+	w = serve(srv, http.MethodGet, "/api/v1/fees")
+	assert.Equal(t, http.StatusOK, w.Code)
+	fees = nil
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &fees))
+	assert.JSONEq(t, `"gasPrice"`, string(fees["source"]))
+	assert.NotContains(t, fees, "maxPriorityFeePerGas")
+	assert.JSONEq(t, tier("0xba43b7400", "50000000000", "0x2540be400", "10000000000"), string(fees["standard"]))
+
+	// Pre-London blocks have no base fee, so every tier pays the legacy gas price
+	srv = newTestServer(t, feeNode("", false, false))
+	w = serve(srv, http.MethodGet, "/api/v1/fees")
+	assert.Equal(t, http.StatusOK, w.Code)
+	fees = nil
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &fees))
+	assert.JSONEq(t, `false`, string(fees["eip1559"]))
+	assert.NotContains(t, fees, "baseFeePerGas")
+	for _, name := range []string{"slow", "standard", "fast"} {
+		assert.JSONEq(t, tier("0x6fc23ac00", "30000000000", "0x6fc23ac00", "30000000000"), string(fees[name]), name)
+	}
+}
+
+func TestCallEndpoint(t *testing.T) {
+	const token = "0xc2132d05d31c914a87c6611c10748aeb04b58e8f"
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x01"}`))
+		assert.NoError(t, err)
+	})
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/api/v1/call?block=0x10", `{"to":"`+token+`","data":"0x06fdde03"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"to":"`+token+`","block":"0x10","result":"0x01"}`, w.Body.String())
+
+	for _, body := range []string{`{"data":"0x06fdde03"}`, `{"to":"0x1234"}`, `{"to":"` + token + `","gasPrice":"100"}`, `not json`} {
+		w = post("/api/v1/call", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	// Every invalid field is reported
+	w = post("/api/v1/call", `{"to":"0x1234","data":"0x0","gas":"100"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response struct {
+		Error struct {
+			Fields map[string]string `json:"fields"`
+		} `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, map[string]string{
+		"to":   "to must be a 0x-prefixed 20-byte hex address",
+		"data": "data must be 0x-prefixed hex bytes",
+		"gas":  "gas must be a 0x-prefixed hex quantity",
+	}, response.Error.Fields)
+}
+
+func TestCallEndpointReverted(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted: paused"}}`))
+		assert.NoError(t, err)
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/call", strings.NewReader(`{"to":"0xc2132d05d31c914a87c6611c10748aeb04b58e8f"}`))
+	req.Header.Set("X-Request-ID", "call-1")
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"error":{
+		"type": "execution_reverted",
+		"code": "EXECUTION_REVERTED",
+		"message": "execution reverted: paused",
+		"data": {"error_code": 3, "error_message": "execution reverted: paused"},
+		"requestId": "call-1"
+	}}`, w.Body.String())
+}
+
+func TestSendRawTransactionEndpoint(t *testing.T) {
+	const raw = "0x02f8700181c5843b9aca00850bdfd63e0082520894a7d9ddbe1f17865597fbd27ec712455208b6b76d880de0b6b3a764000080c0"
+	const hash = "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"
+	reply := `{"jsonrpc":"2.0","id":1,"result":"` + hash + `"}`
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(reply))
+		assert.NoError(t, err)
+	})
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/tx", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		return w
+	}
+
+	success := metrics.RPCRequestsTotal.WithLabelValues("eth_sendRawTransaction", "success")
+	before := testutil.ToFloat64(success)
+
+	w := post(`{"raw":"` + raw + `"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"hash":"`+hash+`"}`, w.Body.String())
+	assert.Equal(t, before+1, testutil.ToFloat64(success))
+
+	// Rejections are reported to the client with a stable code
+	reply = `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"nonce too low"}}`
+	w = post(`{"raw":"` + raw + `"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"NONCE_TOO_LOW"`)
+
+	for _, body := range []string{`{}`, `{"raw":"0x"}`, `{"raw":"0x123"}`, `{"raw":"02f870"}`, `not json`} {
+		w = post(body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.NotContains(t, w.Body.String(), "NONCE_TOO_LOW", body)
+	}
+
+	// Bodies over the limit are rejected before reaching the node
+	w = post(`{"raw":"0x` + strings.Repeat("00", middleware.DefaultMaxBodySize) + `"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"BODY_TOO_LARGE"`)
+}
+
+func TestRPCProxyEndpoint(t *testing.T) {
+	var methods []string
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string          `json:"method"`
+			ID     json.RawMessage `json:"id"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		methods = append(methods, request.Method)
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(request.ID) + `,"result":"0x89"}`))
+		assert.NoError(t, err)
+	})
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/rpc", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The node's response is returned verbatim
+	w := post(`{"jsonrpc":"2.0","method":"eth_chainId","params":[],"id":"abc"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":"abc","result":"0x89"}`, w.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	// Methods off the allow-list never reach the node
+	w = post(`{"jsonrpc":"2.0","method":"eth_sendTransaction","params":[{"from":"0x0"}],"id":1}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"type":"permission_error"`)
+	assert.Contains(t, w.Body.String(), `"message":"Method eth_sendTransaction is not allowed"`)
+
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","method":"eth_chainId"}`,
+		`{"jsonrpc":"2.0","method":"eth_chainId","id":{}}`,
+		`{"jsonrpc":"1.0","method":"eth_chainId","id":1}`,
+		`{"jsonrpc":"2.0","method":"eth_chainId","params":"0x1","id":1}`,
+		`[{"jsonrpc":"2.0","method":"eth_chainId","id":1}]`,
+	} {
+		w = post(body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	assert.Equal(t, []string{"eth_chainId"}, methods)
+
+	// Only POST is served
+	w = serve(srv, http.MethodGet, "/api/v1/rpc")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, http.MethodPost, w.Header().Get("Allow"))
+	assert.Equal(t, []string{"eth_chainId"}, methods)
+}
+
+func TestPprofEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := DefaultConfig()
+	config.AdminToken = "s3cret"
+
+	get := func(srv *EnhancedServer, path, authorization string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", authorization)
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Profiling is off by default
+	srv := NewEnhancedWithConfig(nil, config)
+	assert.Equal(t, http.StatusNotFound, get(srv, "/debug/pprof/heap", "Bearer s3cret").Code)
+
+	config.EnablePprof = true
+	srv = NewEnhancedWithConfig(nil, config)
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap", "/debug/pprof/goroutine?debug=1", "/debug/pprof/cmdline"} {
+		assert.Equal(t, http.StatusOK, get(srv, path, "Bearer s3cret").Code, path)
+		assert.Equal(t, http.StatusUnauthorized, get(srv, path, "").Code, path)
+	}
+	assert.Equal(t, http.StatusUnauthorized, get(srv, "/debug/pprof/profile?seconds=1", "Bearer wrong").Code)
+	assert.Equal(t, http.StatusNotFound, get(srv, "/debug/pprof/nonexistent", "Bearer s3cret").Code)
+
+	// Without an admin token the profiles are never served
+	config.AdminToken = ""
+	srv = NewEnhancedWithConfig(nil, config)
+	assert.Equal(t, http.StatusUnauthorized, get(srv, "/debug/pprof/heap", "Bearer ").Code)
+}
+
+func TestAdminStatsEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := DefaultConfig()
+	config.AdminToken = "s3cret"
+	srv := NewEnhancedWithConfig(nil, config)
+
+	metrics.BlockCacheHitsTotal.Inc()
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/stats", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var stats map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.ElementsMatch(t, []string{"requests", "rpc", "blockchainHeight", "blockCache"}, keys(stats))
+	assert.ElementsMatch(t, []string{"total", "slow"}, keys(stats["requests"]))
+	assert.ElementsMatch(t, []string{"total", "success", "error"}, keys(stats["rpc"]))
+	assert.ElementsMatch(t, []string{"hits", "misses", "hitRate"}, keys(stats["blockCache"]))
+	assert.Greater(t, stats["blockCache"].(map[string]interface{})["hits"], float64(0))
+
+	// The token is required
+	for _, header := range []string{"", "Bearer wrong", "s3cret"} {
+		req, _ = http.NewRequest(http.MethodGet, "/admin/stats", nil)
+		req.Header.Set("Authorization", header)
+		w = httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, header)
+	}
+
+	// Admin routes are not served without a token
+	w = serve(NewEnhanced(nil, "8080"), http.MethodGet, "/admin/stats")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestLogLevelEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := DefaultConfig()
+	config.AdminToken = "s3cret"
+	srv := NewEnhancedWithConfig(nil, config)
+	defer logger.SetLevel(logger.Level())
+
+	send := func(method, body, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/admin/loglevel", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.NoError(t, logger.SetLevel("info"))
+	w := send(http.MethodGet, "", "s3cret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"level":"info"}`, w.Body.String())
+
+	w = send(http.MethodPut, `{"level":"debug"}`, "s3cret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"level":"debug"}`, w.Body.String())
+	assert.Equal(t, "debug", logger.Level())
+
+	// Invalid levels are rejected and leave the level unchanged
+	for _, body := range []string{`{"level":"verbose"}`, `{}`, `not json`} {
+		w = send(http.MethodPut, body, "s3cret")
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	assert.Equal(t, "debug", logger.Level())
+
+	// The admin token is required
+	w = send(http.MethodPut, `{"level":"error"}`, "wrong")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "debug", logger.Level())
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var nodeCalls atomic.Int32
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nodeCalls.Add(1)
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+		assert.NoError(t, err)
+	}))
+	defer node.Close()
+
+	config := DefaultConfig()
+	config.AdminToken = "s3cret"
+	srv := NewEnhancedWithConfig(rpc.NewEnhancedClient(node.URL, time.Second), config)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	defer logger.Replace(zap.New(core))()
+
+	setMaintenance := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPut, "/admin/maintenance", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := setMaintenance(`{"enabled":true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"enabled":true}`, w.Body.String())
+	assert.Equal(t, 1, logs.FilterMessage("Maintenance mode enabled, API requests will be rejected").Len())
+
+	// API routes are rejected without reaching the node
+	w = serve(srv, http.MethodGet, "/api/v1/block/latest")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"type":"service_unavailable"`)
+	assert.Equal(t, int32(0), nodeCalls.Load())
+
+	// Probes and metrics stay live
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodGet, "/health").Code)
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodGet, "/metrics").Code)
+
+	// Repeating a switch is not a transition
+	setMaintenance(`{"enabled":true}`)
+	assert.Equal(t, 1, logs.FilterMessage("Maintenance mode enabled, API requests will be rejected").Len())
+
+	assert.Equal(t, http.StatusBadRequest, setMaintenance(`{}`).Code)
+	assert.True(t, srv.InMaintenance())
+
+	w = setMaintenance(`{"enabled":false}`)
+	assert.JSONEq(t, `{"enabled":false}`, w.Body.String())
+	assert.Equal(t, 1, logs.FilterMessage("Maintenance mode disabled, API requests will be served").Len())
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodGet, "/api/v1/block/latest").Code)
+	assert.Equal(t, int32(1), nodeCalls.Load())
+}
+
+// keys returns the keys of a decoded JSON object
+func keys(object interface{}) []string {
+	var names []string
+	for name := range object.(map[string]interface{}) {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestReadyAfterWarmup(t *testing.T) {
+	defer func(interval time.Duration) { warmupRetryInterval = interval }(warmupRetryInterval)
+	warmupRetryInterval = 10 * time.Millisecond
+
+	// The node is unreachable for the first attempt
+	var calls int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		result := `"0x134e82a"`
+		switch request.Method {
+		case "net_version":
+			result = `"137"`
+		case "net_peerCount":
+			result = `"0x19"`
+		case "web3_clientVersion":
+			result = `"bor/v1.2.0/linux-amd64/go1.21.4"`
+		}
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":` + result + `}`))
+		assert.NoError(t, err)
+	})
+
+	w := serve(srv, http.MethodGet, "/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+
+	assert.NoError(t, srv.Warmup(context.Background()))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	w = serve(srv, http.MethodGet, "/ready")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"status":"ready","chain":"Polygon Mainnet","networkId":"137","peerCount":25,"clientVersion":"bor/v1.2.0/linux-amd64/go1.21.4"}`, w.Body.String())
+
+	// A recent successful check is reused rather than hitting the node again
+	w = serve(srv, http.MethodGet, "/ready")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int32(7), atomic.LoadInt32(&calls))
+}
+
+func TestReadyDetectsChainIDChange(t *testing.T) {
+	var chainID atomic.Value
+	chainID.Store("0x89")
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		result := `"0x134e82a"`
+		switch request.Method {
+		case "net_version":
+			result = `"137"`
+		case "eth_chainId":
+			result = `"` + chainID.Load().(string) + `"`
+		}
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":` + result + `}`))
+		assert.NoError(t, err)
+	})
+
+	assert.NoError(t, srv.Warmup(context.Background()))
+	assert.Equal(t, "0x89", srv.chainID)
+
+	w := serve(srv, http.MethodGet, "/ready")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The provider starts answering for Ethereum mainnet
+	chainID.Store("0x1")
+	srv.readiness.set(nil)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	defer logger.Replace(zap.New(core))()
+
+	w = serve(srv, http.MethodGet, "/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{
+		"status": "unavailable",
+		"error": "Connected to a different chain than at startup",
+		"detail": "chain ID 0x1, expected 0x89"
+	}`, w.Body.String())
+	assert.Equal(t, 1, logs.FilterMessage("Connected chain changed since startup").FilterLevelExact(zapcore.ErrorLevel).Len())
+}
+
+func TestReadyReportsUnreachableRPC(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	srv.started.Store(true)
+
+	w := serve(srv, http.MethodGet, "/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"unavailable"`)
+	assert.Contains(t, w.Body.String(), "400")
+}
+
+func TestWarmupStopsWhenCancelled(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, srv.Warmup(ctx), context.Canceled)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(srv, http.MethodGet, "/ready").Code)
+}
+
+func TestBlockTimestampRFC3339(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x10","timestamp":"0x6456a1d4"}}`))
+		assert.NoError(t, err)
+	})
+
+	w := serve(srv, http.MethodGet, "/api/v1/block/0x10?timeFormat=rfc3339")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"timestamp":"2023-05-06T18:52:04Z"`)
+
+	// The raw hex timestamp is kept unless a format is requested
+	w = serve(srv, http.MethodGet, "/api/v1/block/0x10")
+	assert.Contains(t, w.Body.String(), `"timestamp":"0x6456a1d4"`)
+
+	w = serve(srv, http.MethodGet, "/api/v1/block/latest/full?timeFormat=rfc3339")
+	assert.Contains(t, w.Body.String(), `"timestamp":"2023-05-06T18:52:04Z"`)
+
+	w = serve(srv, http.MethodGet, "/api/v1/block/0x10?timeFormat=unix")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFormatBlockTimestampWithoutTimestamp(t *testing.T) {
+	// Pending blocks may omit the timestamp
+	pending := &models.Block{Hash: "0x1234"}
+	assert.Same(t, pending, formatBlockTimestamp(pending, timeFormatRFC3339))
+
+	// Formatting never mutates a block that may be cached
+	block := &models.Block{Timestamp: "0x6456a1d4"}
+	formatted := formatBlockTimestamp(block, timeFormatRFC3339)
+	assert.Equal(t, "2023-05-06T18:52:04Z", formatted.Timestamp)
+	assert.Equal(t, "0x6456a1d4", block.Timestamp)
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("RPC must not be called for a rejected method")
+	})
+
+	for _, path := range []string{"/health", "/api/v1/block/latest"} {
+		w := serve(srv, http.MethodPost, path)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code, path)
+		assert.Equal(t, "GET", w.Header().Get("Allow"), path)
+
+		var body struct {
+			Error struct {
+				Type      string                 `json:"type"`
+				Code      string                 `json:"code"`
+				Message   string                 `json:"message"`
+				Data      map[string]interface{} `json:"data"`
+				RequestID string                 `json:"requestId"`
+			} `json:"error"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), path)
+		assert.Equal(t, errors.ErrTypeValidation, body.Error.Type, path)
+		assert.Equal(t, errors.CodeMethodNotAllowed, body.Error.Code, path)
+		assert.Equal(t, "Method not allowed", body.Error.Message, path)
+		assert.Equal(t, "POST", body.Error.Data["method"], path)
+		assert.NotEmpty(t, body.Error.RequestID, path)
+		assert.Equal(t, w.Header().Get("X-Request-ID"), body.Error.RequestID, path)
+	}
+
+	// Unknown paths are still reported as not found
+	assert.Equal(t, http.StatusNotFound, serve(srv, http.MethodPost, "/unknown").Code)
+}
+
+func TestTransactionGasPriceEndpoint(t *testing.T) {
+	const hash = "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"
+	const blockHash = "0x4e3a3754410177e6937ef1f84bba68ea139e8d1a2258c5f85db9f1cd715a1bdd"
+	var tx, receipt string
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var request models.RPCRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		result := tx
+		if request.Method == "eth_getTransactionReceipt" {
+			result = receipt
+		}
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":` + result + `}`))
+		assert.NoError(t, err)
+	})
+	path := "/api/v1/tx/" + hash + "/gasprice"
+
+	// Mined EIP-1559 transactions paid the receipt's effective gas price
+	tx = `{"hash":"` + hash + `","blockHash":"` + blockHash + `","blockNumber":"0x10","gasPrice":"0x719f11100","maxFeePerGas":"0xba43b7400"}`
+	receipt = `{"transactionHash":"` + hash + `","blockHash":"` + blockHash + `","blockNumber":"0x10","effectiveGasPrice":"0x719f11100"}`
+	w := serve(srv, http.MethodGet, path)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"hash":"`+hash+`","pending":false,"blockNumber":"0x10","gasPrice":"0x719f11100","gasPriceWei":"30500000000","gasPriceGwei":"30.5","source":"effectiveGasPrice"}`, w.Body.String())
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+
+	// Receipts from nodes predating effectiveGasPrice fall back to the transaction
+	tx = `{"hash":"` + hash + `","blockHash":"` + blockHash + `","blockNumber":"0x10","gasPrice":"0x3b9aca00"}`
+	receipt = `{"transactionHash":"` + hash + `","blockHash":"` + blockHash + `","blockNumber":"0x10"}`
+	w = serve(srv, http.MethodGet, path)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"gasPriceGwei":"1"`)
+	assert.Contains(t, w.Body.String(), `"source":"gasPrice"`)
+
+	// Pending transactions get their fee cap as an estimate
+	tx = `{"hash":"` + hash + `","blockHash":null,"blockNumber":null,"gasPrice":"0xba43b7400","maxFeePerGas":"0xba43b7400"}`
+	w = serve(srv, http.MethodGet, path)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"hash":"`+hash+`","pending":true,"gasPrice":"0xba43b7400","gasPriceWei":"50000000000","gasPriceGwei":"50","source":"maxFeePerGas","note":"`+pendingGasPriceNote+`"}`, w.Body.String())
+
+	// A mined transaction whose receipt the node has not indexed yet
+	tx = `{"hash":"` + hash + `","blockHash":"` + blockHash + `","blockNumber":"0x10","gasPrice":"0x3b9aca00"}`
+	receipt = `null`
+	w = serve(srv, http.MethodGet, path)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"`+errors.CodeReceiptNotFound+`"`)
+
+	tx = `null`
+	assert.Equal(t, http.StatusNotFound, serve(srv, http.MethodGet, path).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(srv, http.MethodGet, "/api/v1/tx/0x1234/gasprice").Code)
+}
+
+func TestWeiToGwei(t *testing.T) {
+	tests := map[int64]string{
+		0:           "0",
+		1:           "0.000000001",
+		1000000000:  "1",
+		30500000000: "30.5",
+		1234567890:  "1.23456789",
+	}
+	for wei, expected := range tests {
+		assert.Equal(t, expected, weiToGwei(big.NewInt(wei)), wei)
+	}
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// defaultGasStatsBlocks is how many recent blocks gas statistics cover by default
+const defaultGasStatsBlocks = 20
+
+// maxGasStatsBlocks caps the window so a single request cannot fan out into
+// an arbitrarily large batch against the upstream node
+const maxGasStatsBlocks = 128
+
+// DefaultStatsMaxMissingRatio is the largest fraction of blocks that may fail
+// to fetch before gas statistics are no longer computed from the rest
+const DefaultStatsMaxMissingRatio = 0.1
+
+// gasPercentiles summarises gasUsed over a window of blocks
+type gasPercentiles struct {
+	P50 uint64 `json:"p50"`
+	P90 uint64 `json:"p90"`
+	P99 uint64 `json:"p99"`
+}
+
+// gasStats is the response body of the gas statistics endpoint
+type gasStats struct {
+	Blocks int `json:"blocks"`
+	// Missing counts the blocks of the window that could not be fetched
+	Missing   int            `json:"missing"`
+	FromBlock string         `json:"fromBlock"`
+	ToBlock   string         `json:"toBlock"`
+	GasUsed   gasPercentiles `json:"gasUsed"`
+}
+
+// gasStatsCache remembers computed statistics per chain and window size. An
+// entry stays valid until the chain head moves, since the window is then the same.
+type gasStatsCache struct {
+	mu      sync.Mutex
+	entries map[string]*gasStats
+}
+
+// get returns the cached statistics for key if they end at toBlock
+func (g *gasStatsCache) get(key, toBlock string) (*gasStats, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	stats, ok := g.entries[key]
+	if !ok || stats.ToBlock != toBlock {
+		return nil, false
+	}
+	return stats, true
+}
+
+// set records statistics for key, replacing any older window
+func (g *gasStatsCache) set(key string, stats *gasStats) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.entries == nil {
+		g.entries = make(map[string]*gasStats)
+	}
+	g.entries[key] = stats
+}
+
+// getGasStats handles requests for gasUsed percentiles over the most recent blocks
+func (s *EnhancedServer) getGasStats(c *gin.Context) {
+	count := defaultGasStatsBlocks
+	if raw := c.Query("blocks"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxGasStatsBlocks {
+			c.Error(errors.NewValidationError(
+				fmt.Sprintf("blocks must be an integer between 1 and %d", maxGasStatsBlocks), nil))
+			return
+		}
+		count = n
+	}
+
+	client, chain := s.clientFor(c)
+	ctx := metrics.WithSource(c.Request.Context(), metrics.SourceStats)
+
+	latestHex, err := client.GetLatestBlockNumberCtx(ctx)
+	if err != nil {
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get latest block number"))
+		return
+	}
+	latest, err := hexutil.DecodeUint64(latestHex)
+	if err != nil {
+		c.Error(errors.NewBlockchainError("Node returned an invalid block number", err).
+			WithData(map[string]interface{}{"block_number": latestHex}))
+		return
+	}
+
+	key := fmt.Sprintf("%s:%d", chain, count)
+	if stats, ok := s.gasStats.get(key, latestHex); ok {
+		c.JSON(http.StatusOK, stats)
+		return
+	}
+
+	// Young chains may not have enough blocks to fill the window
+	from := uint64(0)
+	if latest >= uint64(count) {
+		from = latest - uint64(count) + 1
+	}
+	// Counting rather than comparing n with latest keeps the loop from
+	// wrapping around if the node reports the largest block number
+	numbers := make([]string, 0, latest-from+1)
+	for i := uint64(0); i <= latest-from; i++ {
+		numbers = append(numbers, hexutil.EncodeUint64(from+i))
+	}
+
+	// Blocks that fail to fetch are left nil, and statistics are computed from
+	// the rest unless too many are missing
+	blocks, err := client.BatchGetBlocksByNumber(ctx, numbers, false)
+	missing := len(numbers) - len(blocks)
+	for _, block := range blocks {
+		if block == nil {
+			missing++
+		}
+	}
+	if missing == len(numbers) {
+		logger.Error("Failed to fetch blocks for gas statistics",
+			zap.String("from", numbers[0]),
+			zap.String("to", latestHex),
+			zap.Error(err))
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to fetch blocks for gas statistics"))
+		return
+	}
+	if missing > 0 {
+		logger.Warn("Some blocks for gas statistics could not be fetched",
+			zap.String("from", numbers[0]),
+			zap.String("to", latestHex),
+			zap.Int("missing", missing),
+			zap.Error(err))
+		if float64(missing) > s.statsMaxMissingRatio*float64(len(numbers)) {
+			c.Error(errors.NewBlockchainError("Too many blocks for gas statistics could not be fetched", err).
+				WithData(map[string]interface{}{
+					"blocks":            len(numbers),
+					"missing":           missing,
+					"max_missing_ratio": s.statsMaxMissingRatio,
+				}))
+			return
+		}
+	}
+
+	gasUsed := make([]uint64, 0, len(blocks))
+	for _, block := range blocks {
+		if block == nil {
+			continue
+		}
+		// Empty blocks report 0x0 and count towards the distribution
+		used, err := hexutil.DecodeUint64(block.GasUsed)
+		if err != nil {
+			logger.Warn("Skipping block with invalid gasUsed",
+				zap.String("block_number", block.Number),
+				zap.String("gas_used", block.GasUsed))
+			continue
+		}
+		gasUsed = append(gasUsed, used)
+	}
+
+	stats := &gasStats{
+		Blocks:    len(gasUsed),
+		Missing:   missing,
+		FromBlock: numbers[0],
+		ToBlock:   latestHex,
+		GasUsed:   gasUsedPercentiles(gasUsed),
+	}
+	// Partial results are not cached, so the next request retries the missing blocks
+	if missing == 0 {
+		s.gasStats.set(key, stats)
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// gasUsedPercentiles computes p50, p90 and p99 of values, which it sorts in place
+func gasUsedPercentiles(values []uint64) gasPercentiles {
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return gasPercentiles{
+		P50: percentile(values, 50),
+		P90: percentile(values, 90),
+		P99: percentile(values, 99),
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted values, or 0
+// when there are none
+func percentile(sorted []uint64, p float64) uint64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasUsedPercentiles(t *testing.T) {
+	// 1..100 in reverse order, so the nearest rank equals the percentile
+	values := make([]uint64, 100)
+	for i := range values {
+		values[i] = uint64(100 - i)
+	}
+	assert.Equal(t, gasPercentiles{P50: 50, P90: 90, P99: 99}, gasUsedPercentiles(values))
+
+	// Empty blocks pull the median down rather than being ignored
+	assert.Equal(t, gasPercentiles{P50: 0, P90: 30, P99: 30},
+		gasUsedPercentiles([]uint64{0, 0, 30, 0, 10}))
+
+	assert.Equal(t, gasPercentiles{P50: 7, P90: 7, P99: 7}, gasUsedPercentiles([]uint64{7}))
+	assert.Equal(t, gasPercentiles{}, gasUsedPercentiles(nil))
+}
+
+func TestGasStatsEndpoint(t *testing.T) {
+	var batches atomic.Int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		if body[0] != '[' {
+			_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x13"}`))
+			assert.NoError(t, err)
+			return
+		}
+
+		batches.Add(1)
+		var requests []struct {
+			ID     int           `json:"id"`
+			Params []interface{} `json:"params"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &requests))
+
+		// Blocks 0x10..0x13 used 0, 100, 200 and 300 gas
+		responses := make([]string, len(requests))
+		for i, request := range requests {
+			var number int
+			fmt.Sscanf(request.Params[0].(string), "0x%x", &number)
+			assert.Equal(t, false, request.Params[1])
+			responses[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"number":"%s","gasUsed":"0x%x","transactions":["0xabc"]}}`,
+				request.ID, request.Params[0], (number-0x10)*100)
+		}
+		_, err := w.Write([]byte("[" + strings.Join(responses, ",") + "]"))
+		assert.NoError(t, err)
+	})
+
+	w := serve(srv, http.MethodGet, "/api/v1/stats/gas?blocks=4")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"blocks": 4,
+		"missing": 0,
+		"fromBlock": "0x10",
+		"toBlock": "0x13",
+		"gasUsed": {"p50": 100, "p90": 300, "p99": 300}
+	}`, w.Body.String())
+
+	// The head has not moved, so the cached result is served
+	w = serve(srv, http.MethodGet, "/api/v1/stats/gas?blocks=4")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int32(1), batches.Load())
+
+	for _, blocks := range []string{"0", "abc", "129"} {
+		w = serve(srv, http.MethodGet, "/api/v1/stats/gas?blocks="+blocks)
+		assert.Equal(t, http.StatusBadRequest, w.Code, blocks)
+	}
+}
+
+func TestGasStatsEndpointPartialFailure(t *testing.T) {
+	var failing atomic.Int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		if body[0] != '[' {
+			_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x19"}`))
+			assert.NoError(t, err)
+			return
+		}
+
+		var requests []struct {
+			ID     int           `json:"id"`
+			Params []interface{} `json:"params"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &requests))
+
+		// Blocks 0x10..0x19 used 100 gas each, except the first few fail
+		responses := make([]string, len(requests))
+		for i, request := range requests {
+			if int32(i) < failing.Load() {
+				responses[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-32000,"message":"header not found"}}`, request.ID)
+				continue
+			}
+			responses[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"number":"%s","gasUsed":"0x64","transactions":[]}}`,
+				request.ID, request.Params[0])
+		}
+		_, err := w.Write([]byte("[" + strings.Join(responses, ",") + "]"))
+		assert.NoError(t, err)
+	})
+
+	// One missing block of ten is within the default 10% tolerance
+	failing.Store(1)
+	w := serve(srv, http.MethodGet, "/api/v1/stats/gas?blocks=10")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"blocks": 9,
+		"missing": 1,
+		"fromBlock": "0x10",
+		"toBlock": "0x19",
+		"gasUsed": {"p50": 100, "p90": 100, "p99": 100}
+	}`, w.Body.String())
+
+	// Partial results are not cached, so a repeated request sees the recovery
+	failing.Store(0)
+	w = serve(srv, http.MethodGet, "/api/v1/stats/gas?blocks=10")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"missing":0`)
+
+	// Two missing blocks are tolerated among twenty but not among five
+	failing.Store(2)
+	w = serve(srv, http.MethodGet, "/api/v1/stats/gas?blocks=20")
+	assert.Equal(t, http.StatusOK, w.Code, "within 10% of 20 blocks")
+	w = serve(srv, http.MethodGet, "/api/v1/stats/gas?blocks=5")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+
+	// Every block failing is an error regardless of the tolerance
+	failing.Store(10)
+	w = serve(srv, http.MethodGet, "/api/v1/stats/gas?blocks=3")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+}
+
+func TestGasStatsEndpointAtLargestBlockNumber(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		if body[0] != '[' {
+			_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0xffffffffffffffff"}`))
+			assert.NoError(t, err)
+			return
+		}
+
+		var requests []struct {
+			ID     int           `json:"id"`
+			Params []interface{} `json:"params"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &requests))
+		responses := make([]string, len(requests))
+		for i, request := range requests {
+			responses[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"number":"%s","gasUsed":"0x64","transactions":[]}}`,
+				request.ID, request.Params[0])
+		}
+		_, err := w.Write([]byte("[" + strings.Join(responses, ",") + "]"))
+		assert.NoError(t, err)
+	})
+
+	// The window ends at the head instead of wrapping around to block 0
+	w := serve(srv, http.MethodGet, "/api/v1/stats/gas?blocks=2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"blocks":2`)
+	assert.Contains(t, w.Body.String(), `"toBlock":"0xffffffffffffffff"`)
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"time"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// timeFormatRFC3339 is the ?timeFormat value that renders block timestamps as RFC3339
+const timeFormatRFC3339 = "rfc3339"
+
+// parseTimeFormat validates the optional ?timeFormat query parameter. An empty
+// result keeps the node's hex timestamp.
+func parseTimeFormat(c *gin.Context) (string, error) {
+	switch format := c.Query("timeFormat"); format {
+	case "", "hex":
+		return "", nil
+	case timeFormatRFC3339:
+		return format, nil
+	default:
+		return "", errors.NewValidationError("Invalid timeFormat parameter, expected rfc3339 or hex", nil).
+			WithData(map[string]interface{}{"timeFormat": format})
+	}
+}
+
+// formatBlockTimestamp returns the block with its timestamp rendered in format.
+// The block is copied since it may be shared with the client's cache. Blocks
+// without a decodable timestamp, such as some pending blocks, are returned as is.
+func formatBlockTimestamp(block *models.Block, format string) *models.Block {
+	if format != timeFormatRFC3339 || block.Timestamp == "" {
+		return block
+	}
+
+	timestamp, err := block.TimestampTime()
+	if err != nil {
+		logger.Warn("Unparseable block timestamp",
+			zap.String("block_number", block.Number),
+			zap.String("timestamp", block.Timestamp),
+			zap.Error(err))
+		return block
+	}
+
+	formatted := *block
+	formatted.Timestamp = timestamp.Format(time.RFC3339)
+	return &formatted
+}
@@ -0,0 +1,295 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// getTransactionByHash handles requests for a specific transaction by hash
+func (s *EnhancedServer) getTransactionByHash(c *gin.Context) {
+	hash := c.Param("hash")
+
+	logger.Debug("Transaction details requested", zap.String("tx_hash", hash))
+
+	if err := validateHash(hash); err != nil {
+		logger.Warn("Invalid transaction hash format", zap.String("input", hash))
+		c.Error(err)
+		return
+	}
+
+	client, _ := s.clientFor(c)
+
+	// Start metrics timer
+	start := time.Now()
+
+	tx, err := client.GetTransactionByHash(c.Request.Context(), hash)
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		metrics.RPCRequestsTotal.WithLabelValues("eth_getTransactionByHash", "error").Inc()
+
+		if errors.IsType(err, errors.ErrorTypeNotFound) {
+			logger.Warn("Transaction not found", zap.String("tx_hash", hash))
+			c.Error(err)
+		} else {
+			logger.Error("Failed to get transaction", zap.String("tx_hash", hash), zap.Error(err))
+			c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get transaction data").
+				WithData(map[string]interface{}{"tx_hash": hash}))
+		}
+		return
+	}
+
+	// Record successful RPC metrics
+	metrics.RPCRequestsTotal.WithLabelValues("eth_getTransactionByHash", "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues("eth_getTransactionByHash").Observe(duration)
+
+	// Mined transactions only change if a reorg moves them to another block
+	if notModified(c, transactionETag(tx.Hash, tx.BlockHash)) {
+		return
+	}
+
+	response, err := s.newTransactionResponse(tx)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// getTransactionReceipt handles requests for the receipt of a transaction
+func (s *EnhancedServer) getTransactionReceipt(c *gin.Context) {
+	hash := c.Param("hash")
+
+	logger.Debug("Transaction receipt requested", zap.String("tx_hash", hash))
+
+	if err := validateHash(hash); err != nil {
+		logger.Warn("Invalid transaction hash format", zap.String("input", hash))
+		c.Error(err)
+		return
+	}
+
+	client, _ := s.clientFor(c)
+
+	// Start metrics timer
+	start := time.Now()
+
+	receipt, err := client.GetTransactionReceipt(c.Request.Context(), hash)
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		metrics.RPCRequestsTotal.WithLabelValues("eth_getTransactionReceipt", "error").Inc()
+
+		if errors.IsType(err, errors.ErrorTypeNotFound) {
+			// A missing receipt usually means the transaction is still pending
+			logger.Debug("Transaction receipt not available", zap.String("tx_hash", hash))
+			c.Error(err)
+		} else {
+			logger.Error("Failed to get transaction receipt", zap.String("tx_hash", hash), zap.Error(err))
+			c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get transaction receipt").
+				WithData(map[string]interface{}{"tx_hash": hash}))
+		}
+		return
+	}
+
+	// Record successful RPC metrics
+	metrics.RPCRequestsTotal.WithLabelValues("eth_getTransactionReceipt", "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues("eth_getTransactionReceipt").Observe(duration)
+
+	if notModified(c, transactionETag(receipt.TransactionHash, receipt.BlockHash)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
+
+// Gas price sources report where a transaction's gas price was taken from
+const (
+	gasPriceSourceReceipt     = "effectiveGasPrice"
+	gasPriceSourceTransaction = "gasPrice"
+	gasPriceSourceMaxFee      = "maxFeePerGas"
+)
+
+// pendingGasPriceNote explains the estimate returned for pending transactions
+const pendingGasPriceNote = "Transaction is pending; the price is its maxFeePerGas, the most it may pay per gas, and the price it pays is only known once it is mined"
+
+// getTransactionGasPrice handles requests for the gas price a transaction paid
+// per unit of gas. Since the London fork the price paid depends on the base
+// fee of the block the transaction lands in, so it is taken from the receipt's
+// effectiveGasPrice, falling back to the transaction's gasPrice for nodes that
+// predate the field. Pending transactions get their maxFeePerGas as an upper
+// bound estimate.
+func (s *EnhancedServer) getTransactionGasPrice(c *gin.Context) {
+	hash := c.Param("hash")
+
+	logger.Debug("Transaction gas price requested", zap.String("tx_hash", hash))
+
+	if err := validateHash(hash); err != nil {
+		logger.Warn("Invalid transaction hash format", zap.String("input", hash))
+		c.Error(err)
+		return
+	}
+
+	client, _ := s.clientFor(c)
+	ctx := c.Request.Context()
+
+	// Start metrics timer
+	start := time.Now()
+
+	tx, err := client.GetTransactionByHash(ctx, hash)
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		metrics.RPCRequestsTotal.WithLabelValues("eth_getTransactionByHash", "error").Inc()
+
+		if errors.IsType(err, errors.ErrorTypeNotFound) {
+			logger.Warn("Transaction not found", zap.String("tx_hash", hash))
+			c.Error(err)
+		} else {
+			logger.Error("Failed to get transaction", zap.String("tx_hash", hash), zap.Error(err))
+			c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get transaction data").
+				WithData(map[string]interface{}{"tx_hash": hash}))
+		}
+		return
+	}
+	metrics.RPCRequestsTotal.WithLabelValues("eth_getTransactionByHash", "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues("eth_getTransactionByHash").Observe(duration)
+
+	response := gin.H{"hash": hash}
+
+	// Pending transactions have no block and no receipt yet
+	if tx.BlockHash == "" {
+		price, source := tx.MaxFeePerGas, gasPriceSourceMaxFee
+		if price == "" {
+			// Legacy transactions pay their gas price wherever they land
+			price, source = tx.GasPrice, gasPriceSourceTransaction
+		}
+		if err := addGasPrice(response, price, source); err != nil {
+			c.Error(err)
+			return
+		}
+		response["pending"] = true
+		if source == gasPriceSourceMaxFee {
+			response["note"] = pendingGasPriceNote
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// The price paid only changes if a reorg moves the transaction
+	if notModified(c, transactionETag(tx.Hash, tx.BlockHash)) {
+		return
+	}
+
+	start = time.Now()
+	receipt, err := client.GetTransactionReceipt(ctx, hash)
+	duration = time.Since(start).Seconds()
+	if err != nil {
+		metrics.RPCRequestsTotal.WithLabelValues("eth_getTransactionReceipt", "error").Inc()
+
+		// The node may not have indexed the receipt of a just-mined
+		// transaction, or the transaction was reorged out in between
+		if errors.IsType(err, errors.ErrorTypeNotFound) {
+			logger.Warn("Transaction receipt not found", zap.String("tx_hash", hash))
+			c.Error(err)
+		} else {
+			logger.Error("Failed to get transaction receipt", zap.String("tx_hash", hash), zap.Error(err))
+			c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get transaction receipt").
+				WithData(map[string]interface{}{"tx_hash": hash}))
+		}
+		return
+	}
+	metrics.RPCRequestsTotal.WithLabelValues("eth_getTransactionReceipt", "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues("eth_getTransactionReceipt").Observe(duration)
+
+	price, source := receipt.EffectiveGasPrice, gasPriceSourceReceipt
+	if price == "" {
+		price, source = tx.GasPrice, gasPriceSourceTransaction
+	}
+	if err := addGasPrice(response, price, source); err != nil {
+		c.Error(err)
+		return
+	}
+	response["pending"] = false
+	response["blockNumber"] = receipt.BlockNumber
+	c.JSON(http.StatusOK, response)
+}
+
+// addGasPrice adds a gas price in hex, wei and gwei to response, with the
+// field it was taken from
+func addGasPrice(response gin.H, price, source string) error {
+	wei, err := decodeFee(source, price)
+	if err != nil {
+		return err
+	}
+	response["gasPrice"] = price
+	response["gasPriceWei"] = wei.String()
+	response["gasPriceGwei"] = weiToGwei(wei)
+	response["source"] = source
+	return nil
+}
+
+// sendRawTransactionRequest is the body of a request to broadcast a transaction
+type sendRawTransactionRequest struct {
+	// Raw is the signed transaction's encoding as 0x-prefixed hex
+	Raw string `json:"raw"`
+}
+
+// sendRawTransaction handles requests to broadcast a signed transaction
+func (s *EnhancedServer) sendRawTransaction(c *gin.Context) {
+	var request sendRawTransactionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.NewValidationError("Request body must be a JSON object with a raw transaction", err))
+		return
+	}
+	if request.Raw == "" || request.Raw == "0x" {
+		c.Error(errors.NewValidationError("raw is required", nil))
+		return
+	}
+	if err := validateDataParam("raw", request.Raw); err != nil {
+		c.Error(err)
+		return
+	}
+
+	client, _ := s.clientFor(c)
+
+	// Start metrics timer
+	start := time.Now()
+
+	hash, err := client.SendRawTransaction(c.Request.Context(), request.Raw)
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		// A rejection is the node's answer to a well-formed transaction
+		if errors.IsType(err, errors.ErrTypeValidation) {
+			metrics.RPCRequestsTotal.WithLabelValues("eth_sendRawTransaction", "success").Inc()
+			metrics.RPCRequestDuration.WithLabelValues("eth_sendRawTransaction").Observe(duration)
+			logger.Warn("Transaction rejected by node", zap.Error(err))
+			c.Error(err)
+			return
+		}
+
+		metrics.RPCRequestsTotal.WithLabelValues("eth_sendRawTransaction", "error").Inc()
+		logger.Error("Failed to send transaction", zap.Error(err))
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to send transaction"))
+		return
+	}
+
+	// Record successful RPC metrics
+	metrics.RPCRequestsTotal.WithLabelValues("eth_sendRawTransaction", "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues("eth_sendRawTransaction").Observe(duration)
+
+	logger.Info("Transaction sent", zap.String("tx_hash", hash))
+	c.JSON(http.StatusOK, gin.H{"hash": hash})
+}
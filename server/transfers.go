@@ -0,0 +1,74 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/logger"
+	"blockchain-client/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxBlockTransfers caps how many transfers a block transfers response lists,
+// since a busy block can emit thousands
+const maxBlockTransfers = 1000
+
+// getBlockTransfers handles requests for the ERC-20 token transfers of a block.
+// Responses list at most maxBlockTransfers transfers and report the total.
+func (s *EnhancedServer) getBlockTransfers(c *gin.Context) {
+	blockNumberParam := c.Param("number")
+
+	formattedBlockNumber, err := validateAndFormatBlockNumber(blockNumberParam)
+	if err != nil {
+		c.Error(errors.Wrap(err, errors.ErrorTypeValidation, "Invalid block number format").
+			WithCode(errors.CodeInvalidBlockNumber))
+		return
+	}
+
+	client, _ := s.clientFor(c)
+
+	// Start metrics timer
+	start := time.Now()
+
+	transfers, skipped, err := client.GetTokenTransfers(c.Request.Context(), formattedBlockNumber)
+
+	// Record RPC metrics
+	duration := time.Since(start).Seconds()
+	if err != nil {
+		// Invalid block numbers are rejected before any RPC call is made
+		if errors.IsType(err, errors.ErrTypeValidation) {
+			c.Error(err)
+			return
+		}
+
+		metrics.RPCRequestsTotal.WithLabelValues("eth_getLogs", "error").Inc()
+		if errors.IsType(err, errors.ErrTypeTooManyResults) {
+			c.Error(err)
+			return
+		}
+		logger.Error("Failed to get block transfers",
+			zap.String("block_number", formattedBlockNumber),
+			zap.Error(err))
+		c.Error(errors.Wrap(err, errors.ErrorTypeBlockchain, "Failed to get block transfers").
+			WithData(map[string]interface{}{"block_number": formattedBlockNumber}))
+		return
+	}
+	metrics.RPCRequestsTotal.WithLabelValues("eth_getLogs", "success").Inc()
+	metrics.RPCRequestDuration.WithLabelValues("eth_getLogs").Observe(duration)
+
+	total := len(transfers)
+	if total > maxBlockTransfers {
+		transfers = transfers[:maxBlockTransfers]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"blockNumber": formattedBlockNumber,
+		"transfers":   transfers,
+		"total":       total,
+		"truncated":   total > maxBlockTransfers,
+		"skipped":     skipped,
+	})
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"blockchain-client/models"
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+	"blockchain-client/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// transactionResponse is a transaction with its wei amounts rendered in decimal
+type transactionResponse struct {
+	*models.Transaction
+	ValueDecimal    string            `json:"valueDecimal,omitempty"`
+	GasPriceDecimal string            `json:"gasPriceDecimal,omitempty"`
+	DecodeErrors    map[string]string `json:"decodeErrors,omitempty"`
+}
+
+// newTransactionResponse renders the wei amounts of tx in decimal
+func (s *EnhancedServer) newTransactionResponse(tx *models.Transaction) (*transactionResponse, error) {
+	response := &transactionResponse{Transaction: tx}
+	decodeErrors := make(map[string]string)
+
+	var err error
+	if response.ValueDecimal, err = s.decodeWei("value", tx.Value, decodeErrors); err != nil {
+		return nil, err
+	}
+	if response.GasPriceDecimal, err = s.decodeWei("gasPrice", tx.GasPrice, decodeErrors); err != nil {
+		return nil, err
+	}
+
+	if len(decodeErrors) > 0 {
+		response.DecodeErrors = decodeErrors
+	}
+	return response, nil
+}
+
+// decodeWei renders a hex wei amount returned by the node in decimal. Empty
+// values are left empty. A malformed value fails the request when strict
+// decoding is enabled; otherwise its decimal is left empty and the problem is
+// recorded in decodeErrors under field so the rest of the response survives.
+func (s *EnhancedServer) decodeWei(field, value string, decodeErrors map[string]string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	wei, err := hexutil.DecodeBigField(field, value)
+	if err == nil {
+		return wei.String(), nil
+	}
+
+	// The error quotes a truncated copy of the value, which may be arbitrarily long
+	logger.Warn("Node returned a malformed hex value", zap.String("field", field), zap.Error(err))
+	if s.strictDecoding {
+		return "", errors.NewBlockchainError(fmt.Sprintf("Node returned a malformed %s", field), err).
+			WithData(map[string]interface{}{"field": field})
+	}
+	decodeErrors[field] = err.Error()
+	return "", nil
+}
+
+// weiPerGwei is the number of wei in one gwei
+var weiPerGwei = big.NewInt(1_000_000_000)
+
+// weiToGwei renders a non-negative wei amount in gwei as an exact decimal, without
+// trailing zeros in its fraction, e.g. 30500000000 wei as "30.5"
+func weiToGwei(wei *big.Int) string {
+	whole, remainder := new(big.Int).QuoRem(wei, weiPerGwei, new(big.Int))
+	if remainder.Sign() == 0 {
+		return whole.String()
+	}
+	return whole.String() + "." + strings.TrimRight(fmt.Sprintf("%09d", remainder), "0")
+}
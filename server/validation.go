@@ -0,0 +1,77 @@
+package server
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"blockchain-client/pkg/errors"
+	"blockchain-client/pkg/hexutil"
+)
+
+var (
+	// hashPattern matches a 0x-prefixed 32-byte hex string such as a transaction hash
+	hashPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+	// addressPattern matches a 0x-prefixed 20-byte hex address
+	addressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	// dataPattern matches 0x-prefixed hex bytes such as call data
+	dataPattern = regexp.MustCompile(`^0x([0-9a-fA-F]{2})*$`)
+)
+
+// validateHash checks that a hash is a 0x-prefixed 32-byte hex string
+func validateHash(hash string) error {
+	if !hashPattern.MatchString(hash) {
+		return errors.NewValidationError("Hash must be a 0x-prefixed 32-byte hex string", nil).
+			WithCode(errors.CodeInvalidHash).
+			WithData(map[string]interface{}{"hash": hash})
+	}
+	return nil
+}
+
+// validateAddressParam checks that a named parameter is a 0x-prefixed 20-byte hex address
+func validateAddressParam(name, address string) error {
+	if !addressPattern.MatchString(address) {
+		return errors.NewValidationError(name+" must be a 0x-prefixed 20-byte hex address", nil).
+			WithCode(errors.CodeInvalidAddress).
+			WithData(map[string]interface{}{name: address})
+	}
+	return nil
+}
+
+// validateDataParam checks that a named parameter is 0x-prefixed hex bytes
+func validateDataParam(name, data string) error {
+	if !dataPattern.MatchString(data) {
+		return errors.NewValidationError(name+" must be 0x-prefixed hex bytes", nil).
+			WithData(map[string]interface{}{name: data})
+	}
+	return nil
+}
+
+// validateQuantityParam checks that a named parameter is a 0x-prefixed hex quantity
+func validateQuantityParam(name, quantity string) error {
+	if _, err := hexutil.DecodeBig(quantity); err != nil {
+		return errors.NewValidationError(name+" must be a 0x-prefixed hex quantity", err).
+			WithData(map[string]interface{}{name: quantity})
+	}
+	return nil
+}
+
+// parseBlockNumberParam parses a named block number parameter given either as
+// a 0x-prefixed hex quantity or in decimal
+func parseBlockNumberParam(name, value string) (uint64, error) {
+	var (
+		number uint64
+		err    error
+	)
+	if strings.HasPrefix(value, "0x") {
+		number, err = hexutil.DecodeUint64(value)
+	} else {
+		number, err = strconv.ParseUint(value, 10, 64)
+	}
+	if err != nil {
+		return 0, errors.NewValidationError(name+" must be a decimal or 0x-prefixed hex block number", err).
+			WithCode(errors.CodeInvalidBlockNumber).
+			WithData(map[string]interface{}{name: value})
+	}
+	return number, nil
+}